@@ -5,10 +5,13 @@ import (
 
 	"github.com/markelca/prioritty/internal/cli"
 	"github.com/markelca/prioritty/internal/logger"
+	"github.com/markelca/prioritty/internal/notify"
 )
 
 func main() {
 	defer logger.ShutdownLogger()
+	// Deliver the events of the changes made before exiting
+	defer notify.Wait(notify.ExitTimeout)
 	if err := cli.Execute(); err != nil {
 		log.Fatalf("Command failed: %v", err)
 	}
@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/markelca/prioritty/internal/config"
+	"github.com/markelca/prioritty/internal/service"
+	"github.com/markelca/prioritty/internal/tui"
+	"github.com/markelca/prioritty/pkg/items"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	agendaDays int
+	agendaJSON bool
+)
+
+func init() {
+	agendaCmd.Flags().IntVar(&agendaDays, "days", 0, "Number of upcoming days to show (default agenda_days, 7)")
+	agendaCmd.Flags().BoolVar(&agendaJSON, "json", false, "Print the agenda as JSON")
+	rootCmd.AddCommand(agendaCmd)
+}
+
+var agendaCmd = &cobra.Command{
+	Use:   "agenda",
+	Args:  cobra.NoArgs,
+	Short: "Shows the tasks to work on today and in the next days",
+	Long: `Shows the open tasks of today: overdue, due today, scheduled for today or
+before, and in progress, each listed once, followed by the tasks due or
+scheduled in the next days. Press g in the TUI for the same screen:
+
+  pt agenda
+  pt agenda --days 14 --json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		days := viper.GetInt(config.CONF_AGENDA_DAYS)
+		if cmd.Flags().Changed("days") {
+			days = agendaDays
+		}
+		if days < 0 {
+			log.Printf("Error: invalid --days %d, expected 0 or more", days)
+			return
+		}
+
+		m := tui.InitialModel(false)
+		m.LoadItems() // numbers the tasks as in the list
+		agenda, err := m.Service.GetAgenda(days)
+		if err != nil {
+			log.Printf("Error computing the agenda: %v", err)
+			return
+		}
+
+		if agendaJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(agendaRecordFrom(m, agenda)); err != nil {
+				log.Printf("Error: %v", err)
+			}
+			return
+		}
+		fmt.Print(m.AgendaView(agenda))
+	},
+}
+
+// agendaRecord is the JSON output of the agenda command.
+type agendaRecord struct {
+	Date       string             `json:"date"` // today, YYYY-MM-DD
+	Overdue    []agendaTaskRecord `json:"overdue"`
+	DueToday   []agendaTaskRecord `json:"due_today"`
+	Scheduled  []agendaTaskRecord `json:"scheduled"`
+	InProgress []agendaTaskRecord `json:"in_progress"`
+	Upcoming   []agendaDayRecord  `json:"upcoming"`
+}
+
+type agendaDayRecord struct {
+	Date  string             `json:"date"`
+	Tasks []agendaTaskRecord `json:"tasks"`
+}
+
+type agendaTaskRecord struct {
+	Index     int    `json:"index"` // position in the list, as in pt list
+	Title     string `json:"title"`
+	UID       string `json:"uid,omitempty"`
+	Status    string `json:"status"`
+	Priority  string `json:"priority,omitempty"`
+	Tag       string `json:"tag,omitempty"`
+	Due       string `json:"due,omitempty"`
+	Scheduled string `json:"scheduled,omitempty"`
+	Estimate  string `json:"estimate,omitempty"`
+}
+
+func agendaTaskRecords(m tui.Model, tasks []items.Task) []agendaTaskRecord {
+	records := []agendaTaskRecord{}
+	for i := range tasks {
+		t := &tasks[i]
+		r := agendaTaskRecord{
+			Index:     m.IndexOf(t) + 1,
+			Title:     t.Title,
+			UID:       t.UID,
+			Status:    string(t.Status),
+			Priority:  string(t.Priority),
+			Due:       items.FormatDate(t.DueDate),
+			Scheduled: items.FormatDate(t.ScheduledDate),
+			Estimate:  t.Estimate.String(),
+		}
+		if t.Tag != nil {
+			r.Tag = t.Tag.Name
+		}
+		records = append(records, r)
+	}
+	return records
+}
+
+func agendaRecordFrom(m tui.Model, a service.Agenda) agendaRecord {
+	r := agendaRecord{
+		Date:       items.FormatDate(a.Date),
+		Overdue:    agendaTaskRecords(m, a.Overdue),
+		DueToday:   agendaTaskRecords(m, a.DueToday),
+		Scheduled:  agendaTaskRecords(m, a.ScheduledToday),
+		InProgress: agendaTaskRecords(m, a.InProgress),
+		Upcoming:   []agendaDayRecord{},
+	}
+	for _, day := range a.Upcoming {
+		r.Upcoming = append(r.Upcoming, agendaDayRecord{
+			Date:  items.FormatDate(day.Date),
+			Tasks: agendaTaskRecords(m, day.Tasks),
+		})
+	}
+	return r
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/markelca/prioritty/internal/config"
+	"github.com/markelca/prioritty/internal/tui"
+	"github.com/markelca/prioritty/pkg/formats/backup"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	rootCmd.AddCommand(backupCmd)
+}
+
+var backupCmd = &cobra.Command{
+	Use:   "backup {file}",
+	Args:  cobra.ExactArgs(1),
+	Short: "Backs up all items and tags to a file",
+	Long: `Writes all tasks, notes and tags to a new file that can be restored into any
+repository type with the restore command, e.g. to move from SQLite to an Obsidian vault:
+
+  pt backup prioritty-backup.json
+  PRIORITTY_REPOSITORY_TYPE=obsidian pt restore prioritty-backup.json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		path := args[0]
+
+		m := tui.InitialModel(false)
+		data, err := m.Service.Snapshot()
+		if err != nil {
+			log.Printf("Error reading items: %v", err)
+			return
+		}
+
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		defer file.Close()
+
+		archive := backup.Archive{
+			CreatedAt: time.Now(),
+			Source:    viper.GetString(config.CONF_REPOSITORY_TYPE),
+			Data:      data,
+		}
+		if err := backup.Encode(file, archive); err != nil {
+			log.Printf("Error writing the backup: %v", err)
+			return
+		}
+		fmt.Printf("Backed up %d tasks, %d notes and %d tags to %s\n", len(data.Tasks), len(data.Notes), len(data.Tags), path)
+	},
+}
@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/markelca/prioritty/internal/config"
+	"github.com/markelca/prioritty/internal/tui"
+	"github.com/markelca/prioritty/pkg/items/repository"
+	"github.com/markelca/prioritty/pkg/items/repository/remote"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	rootCmd.AddCommand(daemonCmd)
+}
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Args:  cobra.NoArgs,
+	Short: "Keeps the repository open for faster commands",
+	Long: `Runs in the foreground, keeping the repository open and its items in memory,
+and answers the other pt commands over a Unix socket (daemon_socket_path).
+Commands use the daemon when it's running and serves the configured repository,
+and the repository directly otherwise. Changes made by other programs (e.g. the
+Obsidian app) are picked up.
+
+  pt daemon &`,
+	Run: func(cmd *cobra.Command, args []string) {
+		socketPath := repository.ExpandTilde(viper.GetString(config.CONF_DAEMON_SOCKET_PATH))
+		if socketPath == "" {
+			log.Printf("Error: daemon_socket_path is not set")
+			return
+		}
+		repoType := viper.GetString(config.CONF_REPOSITORY_TYPE)
+		dbPath, err := repository.GetDatabasePath(repoType, viper.GetBool("demo"))
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		repo, err := tui.OpenRepository(repoType, dbPath)
+		if err != nil {
+			log.Printf("Error opening %s: %v", dbPath, err)
+			return
+		}
+
+		listener, err := listenUnix(socketPath)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return
+		}
+		defer os.Remove(socketPath)
+
+		srv := remote.NewServer(repo, remote.Info{RepositoryType: repoType, DatabasePath: dbPath})
+		watcher, err := watchRepository(dbPath, srv.Invalidate)
+		if err != nil {
+			log.Printf("Error watching %s, changes made by other programs won't be seen: %v", dbPath, err)
+		} else {
+			defer watcher.Close()
+		}
+
+		// Stop on Ctrl+C or kill, removing the socket
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			<-signals
+			listener.Close()
+		}()
+
+		fmt.Printf("Serving %s on %s\n", dbPath, socketPath)
+		if err := srv.Serve(listener); err != nil {
+			log.Printf("Error serving: %v", err)
+		}
+	},
+}
+
+// listenUnix listens on a Unix socket only accessible by the user. A socket left
+// by a daemon that didn't stop cleanly is replaced, a running daemon's isn't.
+func listenUnix(socketPath string) (net.Listener, error) {
+	if conn, err := net.Dial("unix", socketPath); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("a daemon is already running on %s", socketPath)
+	}
+	if err := os.Remove(socketPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(socketPath), 0755); err != nil {
+		return nil, err
+	}
+
+	return listenPrivate(socketPath)
+}
+
+// watchRepository calls onChange whenever a file of the repository changes: the
+// file itself and its neighbours (e.g. SQLite's journal), or every file of a vault.
+func watchRepository(dbPath string, onChange func()) (*fsnotify.Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(dbPath)
+	if err != nil {
+		watcher.Close()
+		return nil, err
+	}
+	if info.IsDir() {
+		err = addDirs(watcher, dbPath)
+	} else {
+		err = watcher.Add(filepath.Dir(dbPath))
+	}
+	if err != nil {
+		watcher.Close()
+		return nil, err
+	}
+
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if !info.IsDir() && !strings.HasPrefix(filepath.Base(event.Name), filepath.Base(dbPath)) {
+					continue
+				}
+				// Watch the folders created in a vault
+				if info.IsDir() && event.Has(fsnotify.Create) {
+					if fi, err := os.Stat(event.Name); err == nil && fi.IsDir() {
+						addDirs(watcher, event.Name)
+					}
+				}
+				onChange()
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Printf("Error watching %s: %v", dbPath, err)
+				onChange()
+			}
+		}
+	}()
+	return watcher, nil
+}
+
+// addDirs watches a folder and its subfolders, except hidden ones such as .git.
+func addDirs(watcher *fsnotify.Watcher, root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		return watcher.Add(path)
+	})
+}
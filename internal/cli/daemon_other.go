@@ -0,0 +1,21 @@
+//go:build !unix
+
+package cli
+
+import (
+	"net"
+	"os"
+)
+
+// listenPrivate listens on a Unix socket, restricting it to the user once created.
+func listenPrivate(socketPath string) (net.Listener, error) {
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(socketPath, 0600); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
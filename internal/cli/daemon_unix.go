@@ -0,0 +1,16 @@
+//go:build unix
+
+package cli
+
+import (
+	"net"
+	"syscall"
+)
+
+// listenPrivate listens on a Unix socket only accessible by the user. The socket is
+// created with the umask, so it's restricted before anyone can connect.
+func listenPrivate(socketPath string) (net.Listener, error) {
+	oldMask := syscall.Umask(0077)
+	defer syscall.Umask(oldMask)
+	return net.Listen("unix", socketPath)
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/markelca/prioritty/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+var exportFormat string
+
+func init() {
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", "", "Output format ("+formatNames(fileFormat.exportable)+"), guessed from the file extension by default")
+	rootCmd.AddCommand(exportCmd)
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export {file}",
+	Args:  cobra.ExactArgs(1),
+	Short: "Exports all tasks and notes to a file",
+	Long: `Exports all tasks and notes to a new file in another format, e.g.:
+
+  pt export --format todotxt todo.txt
+  pt export --format taskwarrior tasks.json && task import tasks.json
+  pt export tasks.ics
+
+The file must not exist. Notes of todo.txt exports are written next to it (todo.notes.md),
+Taskwarrior and iCalendar exports only include tasks.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		path := args[0]
+		if _, err := os.Stat(path); err == nil {
+			log.Printf("Error: %s already exists", path)
+			return
+		}
+
+		format, err := getFileFormat(exportFormat, path, fileFormat.exportable)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		m := tui.InitialModel(false)
+		result, err := format.exportTo(m.Service, path)
+		if err != nil {
+			log.Printf("Error exporting items: %v", err)
+			return
+		}
+		fmt.Printf("Exported %d tasks and %d notes to %s\n", result.Tasks, result.Notes, path)
+	},
+}
@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/markelca/prioritty/internal/service"
+	"github.com/markelca/prioritty/pkg/formats/ical"
+	"github.com/markelca/prioritty/pkg/formats/taskbook"
+	"github.com/markelca/prioritty/pkg/formats/taskwarrior"
+	"github.com/markelca/prioritty/pkg/items/repository"
+	"github.com/markelca/prioritty/pkg/items/repository/jsonfile"
+	"github.com/markelca/prioritty/pkg/items/repository/memory"
+	"github.com/markelca/prioritty/pkg/items/repository/todotxt"
+)
+
+// fileFormat is a file format items can be exported to or imported from. Formats
+// that are storage backends open the file as a repository, the others decode
+// and encode the whole file at once. Import-only formats have no encode.
+type fileFormat struct {
+	open   func(path string) repository.Repository
+	decode func(r io.Reader) (memory.Data, error)
+	encode func(w io.Writer, data memory.Data) error
+}
+
+const (
+	formatTaskwarrior = "taskwarrior"
+	formatTaskbook    = "taskbook"
+	formatICS         = "ics"
+)
+
+// fileFormats lists the supported formats by name.
+var fileFormats = map[string]fileFormat{
+	repository.RepoTypeJSON: {open: func(path string) repository.Repository {
+		return jsonfile.NewJSONRepository(path)
+	}},
+	repository.RepoTypeTodoTxt: {open: func(path string) repository.Repository {
+		return todotxt.NewTodoTxtRepository(path)
+	}},
+	formatTaskwarrior: {decode: taskwarrior.Decode, encode: taskwarrior.Encode},
+	formatTaskbook:    {decode: taskbook.Decode},
+	formatICS:         {decode: ical.Decode, encode: ical.Encode},
+}
+
+// formatExtensions guesses the format when --format isn't given.
+var formatExtensions = map[string]string{
+	".json": repository.RepoTypeJSON,
+	".txt":  repository.RepoTypeTodoTxt,
+	".ics":  formatICS,
+}
+
+func (f fileFormat) exportable() bool {
+	return f.open != nil || f.encode != nil
+}
+
+func (f fileFormat) importable() bool {
+	return f.open != nil || f.decode != nil
+}
+
+// formatNames lists the names of the formats supporting an operation, e.g. fileFormat.exportable.
+func formatNames(supports func(fileFormat) bool) string {
+	var names []string
+	for name, f := range fileFormats {
+		if supports(f) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// getFileFormat returns the given format, or the one matching the extension of path
+// if format is empty. The format must support the operation.
+func getFileFormat(format, path string, supports func(fileFormat) bool) (fileFormat, error) {
+	if format == "" {
+		format = formatExtensions[strings.ToLower(filepath.Ext(path))]
+		if format == "" {
+			return fileFormat{}, fmt.Errorf("can't guess the format of %s, use --format (%s)", path, formatNames(supports))
+		}
+	}
+	f, ok := fileFormats[format]
+	if !ok || !supports(f) {
+		return fileFormat{}, fmt.Errorf("unsupported format %q (%s)", format, formatNames(supports))
+	}
+	return f, nil
+}
+
+// exportTo writes all items of s to a new file at path.
+func (f fileFormat) exportTo(s service.Service, path string) (repository.CopyResult, error) {
+	if f.open != nil {
+		return s.Export(f.open(path))
+	}
+
+	data, err := s.Snapshot()
+	if err != nil {
+		return repository.CopyResult{}, err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return repository.CopyResult{}, err
+	}
+	if err := f.encode(file, data); err != nil {
+		file.Close()
+		return repository.CopyResult{}, err
+	}
+	if err := file.Close(); err != nil {
+		return repository.CopyResult{}, err
+	}
+
+	// Only count what the format can hold
+	decoded, err := f.decodeFile(path)
+	if err != nil {
+		return repository.CopyResult{}, err
+	}
+	return repository.CopyResult{Tasks: len(decoded.Tasks), Notes: len(decoded.Notes)}, nil
+}
+
+// importFrom adds the items of the file at path to s.
+func (f fileFormat) importFrom(s service.Service, path string) (service.ImportResult, error) {
+	if f.open != nil {
+		return s.Import(f.open(path))
+	}
+
+	data, err := f.decodeFile(path)
+	if err != nil {
+		return service.ImportResult{}, err
+	}
+	return s.Import(memory.NewRepositoryFrom(data))
+}
+
+func (f fileFormat) decodeFile(path string) (memory.Data, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return memory.Data{}, err
+	}
+	defer file.Close()
+	return f.decode(file)
+}
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/markelca/prioritty/internal/tui"
+	"github.com/markelca/prioritty/pkg/items"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(revertCmd)
+}
+
+var historyCmd = &cobra.Command{
+	Use:   "history {id}",
+	Args:  cobra.ExactArgs(1),
+	Short: "Shows the past versions of a task or note",
+	Long: `Lists the git commits that changed a task or note, newest first. Only available
+for Obsidian vaults in a git repository, see obsidian_git_history.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		m := tui.InitialModel(false)
+		item, ok := itemAtArg(m, args[0])
+		if !ok {
+			return
+		}
+
+		revisions, err := m.Service.History(item)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		if len(revisions) == 0 {
+			fmt.Printf("No history for %q yet\n", item.GetTitle())
+			return
+		}
+		for _, rev := range revisions {
+			fmt.Printf("%s  %s  %s\n", rev.Id[:7], rev.Time.Local().Format("2006-01-02 15:04"), rev.Message)
+		}
+	},
+}
+
+var revertCmd = &cobra.Command{
+	Use:   "revert {id} {revision}",
+	Args:  cobra.ExactArgs(2),
+	Short: "Restores a task or note to a past version",
+	Long: `Restores a task or note to its content at a revision listed by the history command:
+
+  pt history 3
+  pt revert 3 1a2b3c4`,
+	Run: func(cmd *cobra.Command, args []string) {
+		m := tui.InitialModel(false)
+		item, ok := itemAtArg(m, args[0])
+		if !ok {
+			return
+		}
+
+		if err := m.Service.Revert(item, args[1]); err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		fmt.Printf("Reverted %q to %s\n", item.GetTitle(), args[1])
+	},
+}
+
+// itemAtArg returns the item at the 1-based index given as argument, logging an error if there's none.
+func itemAtArg(m tui.Model, arg string) (items.ItemInterface, bool) {
+	index, err := strconv.Atoi(arg)
+	if err != nil {
+		log.Printf("Error: Invalid ID '%s'. Please provide a valid number.", arg)
+		return nil, false
+	}
+	item := m.GetItemAt(index - 1)
+	if item == nil {
+		log.Printf("Item at index %d does not exist", index)
+		return nil, false
+	}
+	return item, true
+}
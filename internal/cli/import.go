@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/markelca/prioritty/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+var importFormat string
+
+func init() {
+	importCmd.Flags().StringVarP(&importFormat, "format", "f", "", "Input format ("+formatNames(fileFormat.importable)+"), guessed from the file extension by default")
+	importCmd.Flags().StringVar(&importFormat, "from", "", "Same as --format")
+	rootCmd.AddCommand(importCmd)
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import {file}",
+	Args:  cobra.ExactArgs(1),
+	Short: "Imports tasks and notes from a file",
+	Long: `Adds the tasks and notes of a file to the current repository. Items with the
+UID of a stored item update it, so importing a file again doesn't duplicate them:
+
+  pt import --format todotxt ~/todo/todo.txt
+  task export > tasks.json && pt import --from taskwarrior tasks.json
+  pt import --from taskbook ~/.taskbook/storage/storage.json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		path := args[0]
+		if _, err := os.Stat(path); err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		format, err := getFileFormat(importFormat, path, fileFormat.importable)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		m := tui.InitialModel(false)
+		result, err := format.importFrom(m.Service, path)
+		if err != nil {
+			log.Printf("Error importing items: %v", err)
+			return
+		}
+		fmt.Printf("Imported from %s: %d tasks and %d notes created, %d tasks and %d notes updated, %d unchanged\n",
+			path, result.Created.Tasks, result.Created.Notes, result.Updated.Tasks, result.Updated.Notes, result.Unchanged)
+	},
+}
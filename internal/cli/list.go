@@ -17,6 +17,7 @@ var listCmd = &cobra.Command{
 	Short:   "Shows all the tasks",
 	Run: func(cmd *cobra.Command, args []string) {
 		m := tui.InitialModel(false)
+		m.LoadItems()
 		fmt.Print(m.View())
 	},
 }
@@ -0,0 +1,177 @@
+package cli
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/markelca/prioritty/internal/editor"
+	"github.com/markelca/prioritty/internal/tui"
+	"github.com/markelca/prioritty/internal/tui/styles"
+	"github.com/markelca/prioritty/pkg/items"
+	"github.com/spf13/cobra"
+)
+
+var (
+	logAt     string
+	logRemove int
+	logEdit   bool
+)
+
+// logTimeLayout is the format of the times of time entries, when shown and edited.
+const logTimeLayout = "2006-01-02 15:04"
+
+// pomodoroMark follows the time entries of pomodoros, when shown and edited.
+const pomodoroMark = "pomodoro"
+
+func init() {
+	logCmd.Flags().StringVar(&logAt, "at", "", `Start of the entry added, "YYYY-MM-DD HH:MM" (default so that it ends now)`)
+	logCmd.Flags().IntVar(&logRemove, "remove", 0, "Remove the entry with this number")
+	logCmd.Flags().BoolVar(&logEdit, "edit", false, "Edit the entries in the editor")
+	rootCmd.AddCommand(logCmd)
+}
+
+var logCmd = &cobra.Command{
+	Use:   "log {id} [duration]",
+	Args:  cobra.RangeArgs(1, 2),
+	Short: "Lists or edits the time spent on a task",
+	Long: `Lists the time entries of a task, as tracked by its timer (see pt start --timer),
+or changes them: adds one of the duration given (e.g. 1h30m), ending now or
+starting at --at, removes one, or edits them all in the editor:
+
+  pt log 3
+  pt log 3 45m
+  pt log 3 2h --at "2025-06-02 09:00"
+  pt log 3 --remove 2
+  pt log 3 --edit`,
+	Run: func(cmd *cobra.Command, args []string) {
+		m := tui.InitialModel(false)
+		item, ok := itemAtArg(m, args[0])
+		if !ok {
+			return
+		}
+		task, ok := item.(*items.Task)
+		if !ok {
+			log.Printf("Error: only tasks have time entries")
+			return
+		}
+
+		timeLog := append([]items.TimeEntry(nil), task.TimeLog...)
+		switch {
+		case len(args) == 2:
+			d, err := time.ParseDuration(args[1])
+			if err != nil || d <= 0 {
+				log.Printf("Error: invalid duration %q, expected e.g. 1h30m or 45m", args[1])
+				return
+			}
+			entry := items.TimeEntry{Start: time.Now().Add(-d), End: time.Now()}
+			if logAt != "" {
+				if entry.Start, err = items.ParseTimestamp(logAt, time.Local); err != nil {
+					log.Printf("Error: invalid --at time %q, expected YYYY-MM-DD HH:MM", logAt)
+					return
+				}
+				entry.End = entry.Start.Add(d)
+			}
+			timeLog = append(timeLog, entry)
+		case logRemove != 0:
+			if logRemove < 1 || logRemove > len(timeLog) {
+				log.Printf("Error: there's no entry %d, the task has %d", logRemove, len(timeLog))
+				return
+			}
+			timeLog = append(timeLog[:logRemove-1], timeLog[logRemove:]...)
+		case logEdit:
+			content, err := editor.EditText(formatTimeEntries(task), "time_log_*.txt")
+			if err != nil {
+				log.Printf("Error: %v", err)
+				return
+			}
+			if timeLog, err = parseTimeEntries(content); err != nil {
+				log.Printf("Error: %v", err)
+				return
+			}
+		default:
+			printTimeLog(task)
+			return
+		}
+
+		if err := m.Service.SetTimeLog(task, timeLog); err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		printTimeLog(task)
+	},
+}
+
+// printTimeLog prints the numbered time entries of a task and their total.
+func printTimeLog(t *items.Task) {
+	if len(t.TimeLog) == 0 {
+		fmt.Printf("No time logged for %q yet\n", t.Title)
+		return
+	}
+	now := time.Now()
+	for i, e := range t.TimeLog {
+		end := "running"
+		if !e.Running() {
+			end = e.End.Local().Format("15:04")
+			if items.FormatDate(e.End.Local()) != items.FormatDate(e.Start.Local()) {
+				end = e.End.Local().Format(logTimeLayout)
+			}
+		}
+		line := fmt.Sprintf("%s %s → %-7s %s",
+			styles.Secondary.Render(fmt.Sprintf("%2d.", i+1)),
+			e.Start.Local().Format(logTimeLayout), end,
+			styles.Done.Render(items.FormatWorkTime(e.Duration(now))),
+		)
+		if e.Pomodoro {
+			line += " " + styles.Secondary.Render(pomodoroMark)
+		}
+		fmt.Println(line)
+	}
+	fmt.Printf("%s %s\n", styles.Secondary.Render("Total"), styles.Done.Render(items.FormatWorkTime(t.TimeSpent(now))))
+}
+
+// formatTimeEntries writes the time entries of a task to be edited, one per line.
+func formatTimeEntries(t *items.Task) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Time entries of %q, one per line: start - end (\"YYYY-MM-DD HH:MM\").\n", t.Title)
+	b.WriteString("# The end of a running timer is empty. Delete a line to remove its entry.\n")
+	for _, e := range t.TimeLog {
+		end := ""
+		if !e.Running() {
+			end = " " + e.End.Local().Format(logTimeLayout)
+		}
+		if e.Pomodoro {
+			end += " " + pomodoroMark
+		}
+		fmt.Fprintf(&b, "%s -%s\n", e.Start.Local().Format(logTimeLayout), end)
+	}
+	return b.String()
+}
+
+// parseTimeEntries reads the time entries written by formatTimeEntries, once edited.
+func parseTimeEntries(content string) ([]items.TimeEntry, error) {
+	var timeLog []items.TimeEntry
+	for n, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// The space added separates the empty end of running timers too
+		start, end, _ := strings.Cut(line+" ", " - ")
+		start, end = strings.TrimSpace(start), strings.TrimSpace(end)
+		var e items.TimeEntry
+		var err error
+		if rest, ok := strings.CutSuffix(end, pomodoroMark); ok {
+			end, e.Pomodoro = strings.TrimSpace(rest), true
+		}
+		if e.Start, err = items.ParseTimestamp(start, time.Local); err != nil || e.Start.IsZero() {
+			return nil, fmt.Errorf("line %d: invalid start %q, expected YYYY-MM-DD HH:MM", n+1, start)
+		}
+		if e.End, err = items.ParseTimestamp(end, time.Local); err != nil {
+			return nil, fmt.Errorf("line %d: invalid end %q, expected YYYY-MM-DD HH:MM", n+1, end)
+		}
+		timeLog = append(timeLog, e)
+	}
+	return timeLog, nil
+}
@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"log"
+	"os"
+
+	"github.com/markelca/prioritty/internal/mcp"
+	"github.com/markelca/prioritty/internal/notify"
+	"github.com/markelca/prioritty/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+var mcpReadOnly bool
+
+func init() {
+	mcpCmd.Flags().BoolVar(&mcpReadOnly, "read-only", false, "Only offer the tools that read items")
+	rootCmd.AddCommand(mcpCmd)
+}
+
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Args:  cobra.NoArgs,
+	Short: "Serves the tasks and notes to AI assistants over MCP",
+	Long: `Speaks the Model Context Protocol over stdin and stdout, for AI assistants to
+list, search, create, change the status and tag of items. The board is exposed
+as the prioritty://board resource. Items can't be deleted or edited otherwise.
+
+Add it to the MCP servers of your assistant as the command "pt mcp", or
+"pt mcp --read-only" to only let it read the items.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		notify.LongRunning()
+		m := tui.InitialModel(false)
+		if err := mcp.New(m.Service, mcpReadOnly).Serve(os.Stdin, os.Stdout); err != nil {
+			log.Printf("Error: %v", err)
+		}
+	},
+}
@@ -1,14 +1,15 @@
 package cli
 
 import (
-	tea "github.com/charmbracelet/bubbletea"
-	"github.com/markelca/prioritty/internal/tui"
+	"fmt"
+
 	"github.com/markelca/prioritty/pkg/items"
 	"github.com/spf13/cobra"
 )
 
 func init() {
 	rootCmd.AddCommand(noteCmd)
+	addTemplateFlag(noteCmd)
 }
 
 var noteCmd = &cobra.Command{
@@ -17,14 +18,12 @@ var noteCmd = &cobra.Command{
 	Args:    cobra.MaximumNArgs(1),
 	Short:   "Adds a new note",
 	Run: func(cmd *cobra.Command, args []string) {
-		if len(args) == 0 {
-			// Create with editor
-			m := tui.CreateModel(items.ItemTypeNote)
-			tea.NewProgram(m).Run()
-		} else {
-			// Create with title only
-			m := tui.InitialModel(false)
-			m.Service.AddNote(args[0])
+		title := ""
+		if len(args) > 0 {
+			title = args[0]
+		}
+		if err := addItem(cmd, items.ItemTypeNote, title); err != nil {
+			fmt.Printf("Failed to add the note: %v\n", err)
 		}
 	},
 }
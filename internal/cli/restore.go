@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/markelca/prioritty/internal/tui"
+	"github.com/markelca/prioritty/pkg/formats/backup"
+	"github.com/spf13/cobra"
+)
+
+var restoreReplace bool
+
+func init() {
+	restoreCmd.Flags().Bool("merge", false, "Add the items missing from the repository and keep the rest (default)")
+	restoreCmd.Flags().BoolVar(&restoreReplace, "replace", false, "Make the repository match the backup, removing the items missing from it")
+	restoreCmd.MarkFlagsMutuallyExclusive("merge", "replace")
+	rootCmd.AddCommand(restoreCmd)
+}
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore {file}",
+	Args:  cobra.ExactArgs(1),
+	Short: "Restores items and tags from a backup",
+	Long: `Loads a file written by the backup command into the configured repository.
+
+With --merge (the default), items missing from the repository are added. Items
+already there, by UID or by title and creation day, are kept as they are, and
+those that differ from the backup are reported as conflicts.
+
+With --replace, items that differ are replaced by their version in the backup,
+and the items missing from it are removed once the backup is loaded, so a
+failure leaves them in place. Inline tasks of Obsidian notes are left alone.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		file, err := os.Open(args[0])
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		defer file.Close()
+
+		archive, err := backup.Decode(file)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		m := tui.InitialModel(false)
+		result, err := m.Service.Restore(archive.Data, restoreReplace)
+		if result.Updated > 0 || result.Removed > 0 {
+			fmt.Printf("Replaced %d items and removed %d\n", result.Updated, result.Removed)
+		}
+		if err != nil {
+			log.Printf("Error restoring items: %v", err)
+			return
+		}
+		for _, conflict := range result.Conflicts {
+			fmt.Printf("Conflict: %s, kept the current version\n", conflict)
+		}
+		fmt.Printf("Restored %d tasks and %d notes from the backup of %s (%d unchanged, %d conflicts)\n",
+			result.Tasks, result.Notes, archive.CreatedAt.Local().Format("2006-01-02 15:04"), result.Unchanged, len(result.Conflicts))
+	},
+}
@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/markelca/prioritty/internal/config"
+	"github.com/markelca/prioritty/internal/notify"
+	"github.com/markelca/prioritty/internal/server"
+	"github.com/markelca/prioritty/internal/tui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	serveAddr  string
+	serveToken string
+)
+
+func init() {
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "", "Address to listen on (default serve_addr)")
+	serveCmd.Flags().StringVar(&serveToken, "token", "", "Token the clients must send as \"Authorization: Bearer <token>\" (default serve_token)")
+	rootCmd.AddCommand(serveCmd)
+}
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Args:  cobra.NoArgs,
+	Short: "Serves the tasks and notes over an HTTP/JSON API",
+	Long: `Serves a REST API to list, create, edit and delete the tasks and notes of the
+repository, for dashboards and editor integrations:
+
+  pt serve --addr 127.0.0.1:7420
+  curl http://127.0.0.1:7420/api/items?status=todo,in-progress
+
+Each item has an ETag: send it back in If-Match to only change the item if it
+wasn't modified in the meantime. Set serve_token to require a token.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		addr := firstNonEmpty(serveAddr, viper.GetString(config.CONF_SERVE_ADDR))
+		token := firstNonEmpty(serveToken, viper.GetString(config.CONF_SERVE_TOKEN))
+
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		if host, _, err := net.SplitHostPort(addr); token == "" && err == nil && !isLoopback(host) {
+			log.Printf("Warning: serving on %s without a token, anyone who can reach it can change your items", addr)
+			fmt.Fprintf(os.Stderr, "Warning: no token set, anyone who can reach %s can change your items\n", addr)
+		}
+
+		notify.LongRunning()
+		m := tui.InitialModel(false)
+		srv := &http.Server{
+			Handler:           server.New(m.Service, token).Handler(),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		// Stop on Ctrl+C, letting the requests in progress finish
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			srv.Shutdown(shutdownCtx)
+		}()
+
+		fmt.Printf("Serving on http://%s\n", listener.Addr())
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Error serving: %v", err)
+		}
+	},
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -3,7 +3,10 @@ package cli
 import (
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/markelca/prioritty/internal/service"
 	"github.com/markelca/prioritty/internal/tui"
 	"github.com/markelca/prioritty/internal/tui/styles"
 	"github.com/markelca/prioritty/pkg/items"
@@ -29,16 +32,13 @@ var showCmd = &cobra.Command{
 			return
 		}
 
+		// Loaded for the links, numbered as in the list
 		m := tui.InitialModel(false)
+		m.LoadItems()
 		item := m.GetItemAt(index - 1) // Convert to 0-based index
 
 		if item == nil {
-			allItems, err := m.Service.GetAll()
-			if err != nil {
-				fmt.Printf("Error: Could not retrieve items: %v\n", err)
-				return
-			}
-			fmt.Printf("Error: Index %d is out of range. Available items: 1-%d\n", index, len(allItems))
+			fmt.Printf("Error: Index %d is out of range. Available items: 1-%d\n", index, len(m.Items()))
 			return
 		}
 
@@ -53,6 +53,11 @@ var showCmd = &cobra.Command{
 			if task, ok := item.(*items.Task); ok {
 				input.ItemType = items.ItemTypeTask
 				input.Status = string(task.Status)
+				input.Priority = string(task.Priority)
+				input.Due = items.FormatDate(task.DueDate)
+				input.Scheduled = items.FormatDate(task.ScheduledDate)
+				input.Recurrence = task.Recurrence
+				input.Estimate = task.Estimate.String()
 			} else {
 				input.ItemType = items.ItemTypeNote
 			}
@@ -74,9 +79,83 @@ var showCmd = &cobra.Command{
 			title += " " + styles.Secondary.Render("@"+tag.Name)
 		}
 		fmt.Println(title)
-		if item.GetBody() != "" {
-			fmt.Printf("\n" + item.GetBody())
+		printTimes(item)
+		if body := item.GetBody(); body != "" {
+			fmt.Print("\n" + body)
+			if !strings.HasSuffix(body, "\n") {
+				fmt.Println()
+			}
+		}
+
+		links := service.FindLinks(item, m.Items())
+		printLinks(m, "Links", links.Outgoing)
+		printLinks(m, "Backlinks", links.Backlinks)
+		if len(links.Unresolved) > 0 {
+			fmt.Printf("\n%s\n", styles.Secondary.Render("Unresolved links: "+strings.Join(links.Unresolved, ", ")))
 		}
 	},
 }
 
+// printTimes prints when the item was created, updated and completed, in the local time zone,
+// and the status history, estimate and time spent of tasks.
+func printTimes(item items.ItemInterface) {
+	times := []string{"Created " + items.DisplayTime(item.GetCreatedAt())}
+	if updatedAt := item.GetUpdatedAt(); !updatedAt.IsZero() && !updatedAt.Equal(item.GetCreatedAt()) {
+		times = append(times, "updated "+items.DisplayTime(updatedAt))
+	}
+	task, isTask := item.(*items.Task)
+	if isTask && !task.CompletedAt.IsZero() {
+		times = append(times, "completed "+items.DisplayTime(task.CompletedAt))
+		if d, ok := task.CycleTime(); ok {
+			times = append(times, "cycle time "+items.FormatDuration(d))
+		} else if d, ok := task.LeadTime(); ok {
+			times = append(times, "lead time "+items.FormatDuration(d))
+		}
+	}
+	fmt.Println(styles.Secondary.Render(strings.Join(times, " · ")))
+
+	if isTask && len(task.StatusHistory) > 1 {
+		for _, c := range task.StatusHistory {
+			fmt.Println(styles.Secondary.Render(fmt.Sprintf("  %s  %s", items.DisplayTime(c.At), c.Status)))
+		}
+	}
+
+	if !isTask || (len(task.TimeLog) == 0 && task.Estimate.IsZero()) {
+		return
+	}
+	var work []string
+	if !task.Estimate.IsZero() {
+		work = append(work, "estimated "+task.Estimate.String())
+	}
+	if len(task.TimeLog) > 0 {
+		entries := "entries"
+		if len(task.TimeLog) == 1 {
+			entries = "entry"
+		}
+		work = append(work, fmt.Sprintf("time spent %s in %d %s", items.FormatWorkTime(task.TimeSpent(time.Now())), len(task.TimeLog), entries))
+		if n := task.Pomodoros(); n > 0 {
+			work = append(work, fmt.Sprintf("%d %s", n, plural(n, "pomodoro")))
+		}
+		if entry, ok := task.RunningTimer(); ok {
+			work = append(work, "timer running since "+entry.Start.Local().Format("15:04"))
+		}
+	}
+	line := strings.Join(work, " · ")
+	fmt.Println(styles.Secondary.Render(strings.ToUpper(line[:1]) + line[1:]))
+}
+
+// printLinks prints a section of linked items with the index used by the other commands.
+func printLinks(m tui.Model, header string, linked []items.ItemInterface) {
+	if len(linked) == 0 {
+		return
+	}
+	fmt.Printf("\n%s\n", styles.Default.Underline(true).Render(header))
+	for _, i := range linked {
+		fmt.Printf("  %s%s%s\n",
+			styles.Secondary.Render(fmt.Sprintf("%d. ", m.IndexOf(i)+1)),
+			tui.GetItemIcon(i),
+			i.GetTitle(),
+		)
+	}
+}
+
@@ -0,0 +1,304 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/markelca/prioritty/internal/service"
+	"github.com/markelca/prioritty/internal/tui"
+	"github.com/markelca/prioritty/internal/tui/styles"
+	"github.com/markelca/prioritty/pkg/items"
+	"github.com/spf13/cobra"
+)
+
+var (
+	statsSince string
+	statsUntil string
+	statsJSON  bool
+)
+
+// statsDays is the length of the default period, ending today.
+const statsDays = 30
+
+// statsBarWidth is the width of the longest bar of the week and tag charts.
+const statsBarWidth = 30
+
+func init() {
+	statsCmd.Flags().StringVar(&statsSince, "since", "", fmt.Sprintf("First day of the period, YYYY-MM-DD (default %d days ago)", statsDays-1))
+	statsCmd.Flags().StringVar(&statsUntil, "until", "", "Last day of the period, YYYY-MM-DD (default today)")
+	statsCmd.Flags().BoolVar(&statsJSON, "json", false, "Print the statistics as JSON")
+	rootCmd.AddCommand(statsCmd)
+}
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Args:  cobra.NoArgs,
+	Short: "Shows statistics of the completed tasks",
+	Long: `Shows the tasks completed per day, week and tag in a period, the last 30 days by
+default, with the average lead time (from created to done) and cycle time (from
+started to done), the streak of days with completed tasks, the open tasks left
+at the end of each day, the pomodoros completed in the focus mode of the TUI
+and the estimates of the completed tasks versus the time logged on them:
+
+  pt stats --since 2025-01-01 --until 2025-03-31
+  pt stats --json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		until := time.Now()
+		if statsUntil != "" {
+			var err error
+			if until, err = items.ParseDate(statsUntil); err != nil {
+				log.Printf("Error: invalid --until date %q, expected YYYY-MM-DD", statsUntil)
+				return
+			}
+		}
+		since := until.AddDate(0, 0, -(statsDays - 1))
+		if statsSince != "" {
+			var err error
+			if since, err = items.ParseDate(statsSince); err != nil {
+				log.Printf("Error: invalid --since date %q, expected YYYY-MM-DD", statsSince)
+				return
+			}
+		}
+		if since.After(until) {
+			log.Printf("Error: --since is after --until")
+			return
+		}
+
+		m := tui.InitialModel(false)
+		stats, err := m.Service.GetStats(since, until)
+		if err != nil {
+			log.Printf("Error computing statistics: %v", err)
+			return
+		}
+
+		if statsJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(statsRecordFrom(stats)); err != nil {
+				log.Printf("Error: %v", err)
+			}
+			return
+		}
+		fmt.Print(renderStats(stats))
+	},
+}
+
+func renderStats(s service.Stats) string {
+	var b strings.Builder
+	value := func(n int) string { return styles.Done.Render(fmt.Sprint(n)) }
+	label := styles.Secondary.Render
+
+	fmt.Fprintf(&b, "\n  %s\n", label(fmt.Sprintf("%s → %s", items.FormatDate(s.Since), items.FormatDate(s.Until))))
+	fmt.Fprintf(&b, "  %s %s %s %s %s %s\n",
+		value(s.Completed), label("completed ·"),
+		styles.Default.Render(fmt.Sprint(s.Created)), label("created ·"),
+		styles.InProgress.Render(fmt.Sprint(s.Open)), label("open"),
+	)
+
+	var times []string
+	if s.Completed > 0 {
+		times = append(times, "average lead time "+items.FormatDuration(s.AverageLeadTime))
+	}
+	if s.AverageCycleTime > 0 {
+		times = append(times, "cycle time "+items.FormatDuration(s.AverageCycleTime))
+	}
+	if len(times) > 0 {
+		fmt.Fprintf(&b, "  %s\n", label(strings.Join(times, " · ")))
+	}
+	fmt.Fprintf(&b, "  %s %s %s\n", value(s.CurrentStreak), label(plural(s.CurrentStreak, "day")+" streak · longest"), value(s.LongestStreak))
+
+	completed := make([]int, len(s.Days))
+	open := make([]int, len(s.Days))
+	for i, d := range s.Days {
+		completed[i], open[i] = d.Completed, d.Open
+	}
+	fmt.Fprintf(&b, "\n  %s\n  %s\n", label("Completed per day"), styles.Done.Render(sparkline(completed)))
+	fmt.Fprintf(&b, "  %s\n  %s\n", label("Open tasks"), styles.InProgress.Render(sparkline(open)))
+	if s.Pomodoros > 0 {
+		pomodoros := make([]int, len(s.Days))
+		for i, d := range s.Days {
+			pomodoros[i] = d.Pomodoros
+		}
+		fmt.Fprintf(&b, "  %s %s\n  %s\n", label("Pomodoros per day ·"), value(s.Pomodoros), styles.Cancelled.Render(sparkline(pomodoros)))
+	}
+
+	most := 0
+	for _, w := range s.Weeks {
+		most = max(most, w.Completed)
+	}
+	fmt.Fprintf(&b, "\n  %s\n", label("Completed per week"))
+	for _, w := range s.Weeks {
+		fmt.Fprintf(&b, "  %s %s %s\n", label(items.FormatDate(w.Start)), styles.Done.Render(bar(w.Completed, most)), value(w.Completed))
+	}
+
+	if e := s.Estimates; e.Tasks > 0 || e.PointTasks > 0 {
+		fmt.Fprintf(&b, "\n  %s\n", label("Estimated vs actual work"))
+		if e.Tasks > 0 {
+			fmt.Fprintf(&b, "  %s %s %s %s %s\n",
+				value(e.Tasks), label(plural(e.Tasks, "task")+" estimated"), styles.Default.Render(items.FormatWorkTime(e.Estimated)),
+				label("· took"), styles.Done.Render(fmt.Sprintf("%s (%.f%%)", items.FormatWorkTime(e.Actual), percent(e.Actual, e.Estimated))),
+			)
+		}
+		if e.PointTasks > 0 {
+			perPoint := time.Duration(float64(e.PointsActual) / e.Points)
+			fmt.Fprintf(&b, "  %s %s %s %s %s\n",
+				value(e.PointTasks), label(plural(e.PointTasks, "task")+" of"), styles.Default.Render(items.Estimate{Points: e.Points}.String()),
+				label("· took"), styles.Done.Render(fmt.Sprintf("%s, %s per point", items.FormatWorkTime(e.PointsActual), items.FormatWorkTime(perPoint))),
+			)
+		}
+	}
+
+	if len(s.Tags) > 0 {
+		width := 0
+		for _, t := range s.Tags {
+			width = max(width, len(tagLabel(t.Tag)))
+		}
+		fmt.Fprintf(&b, "\n  %s\n", label("Completed per tag"))
+		for _, t := range s.Tags {
+			line := fmt.Sprintf("  %-*s %s %s", width, tagLabel(t.Tag), styles.Done.Render(bar(t.Completed, s.Tags[0].Completed)), value(t.Completed))
+			if t.AverageLeadTime > 0 {
+				line += " " + label("· lead time "+items.FormatDuration(t.AverageLeadTime))
+			}
+			b.WriteString(line + "\n")
+		}
+	}
+	return b.String()
+}
+
+// sparkLevels are the bars of a sparkline, from the lowest to the highest value.
+var sparkLevels = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws values as a line of bars scaled to the highest one.
+func sparkline(values []int) string {
+	most := 0
+	for _, v := range values {
+		most = max(most, v)
+	}
+	line := make([]rune, len(values))
+	for i, v := range values {
+		if most == 0 {
+			line[i] = sparkLevels[0]
+			continue
+		}
+		line[i] = sparkLevels[v*(len(sparkLevels)-1)/most]
+	}
+	return string(line)
+}
+
+// bar draws a horizontal bar of value scaled to most, at least one block for non-zero values.
+func bar(value, most int) string {
+	if value == 0 || most == 0 {
+		return ""
+	}
+	return strings.Repeat("█", max(1, value*statsBarWidth/most))
+}
+
+func tagLabel(tag string) string {
+	if tag == "" {
+		return "(no tag)"
+	}
+	return "@" + tag
+}
+
+// percent returns part as a percentage of whole.
+func percent(part, whole time.Duration) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return float64(part) / float64(whole) * 100
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
+
+// statsRecord is the JSON output of the stats command. Dates are YYYY-MM-DD in the
+// local time zone and durations are in seconds.
+type statsRecord struct {
+	Since                   string         `json:"since"`
+	Until                   string         `json:"until"`
+	Completed               int            `json:"completed"`
+	Created                 int            `json:"created"`
+	Open                    int            `json:"open"`
+	Pomodoros               int            `json:"pomodoros"`
+	AverageLeadTimeSeconds  int64          `json:"average_lead_time_seconds"`
+	AverageCycleTimeSeconds int64          `json:"average_cycle_time_seconds"`
+	CurrentStreak           int            `json:"current_streak"`
+	LongestStreak           int            `json:"longest_streak"`
+	Days                    []dayRecord    `json:"days"`
+	Weeks                   []weekRecord   `json:"weeks"`
+	Tags                    []tagRecord    `json:"tags"`
+	Estimates               estimateRecord `json:"estimates"`
+}
+
+// estimateRecord compares the estimates of the tasks completed with the time logged.
+type estimateRecord struct {
+	Tasks               int     `json:"tasks"`
+	EstimatedSeconds    int64   `json:"estimated_seconds"`
+	ActualSeconds       int64   `json:"actual_seconds"`
+	PointTasks          int     `json:"point_tasks"`
+	Points              float64 `json:"points"`
+	PointsActualSeconds int64   `json:"points_actual_seconds"`
+}
+
+type dayRecord struct {
+	Date      string `json:"date"`
+	Completed int    `json:"completed"`
+	Created   int    `json:"created"`
+	Open      int    `json:"open"`
+	Pomodoros int    `json:"pomodoros"`
+}
+
+type weekRecord struct {
+	Start     string `json:"start"`
+	Completed int    `json:"completed"`
+}
+
+type tagRecord struct {
+	Tag                    string `json:"tag"`
+	Completed              int    `json:"completed"`
+	AverageLeadTimeSeconds int64  `json:"average_lead_time_seconds"`
+}
+
+func statsRecordFrom(s service.Stats) statsRecord {
+	r := statsRecord{
+		Since:                   items.FormatDate(s.Since),
+		Until:                   items.FormatDate(s.Until),
+		Completed:               s.Completed,
+		Created:                 s.Created,
+		Open:                    s.Open,
+		Pomodoros:               s.Pomodoros,
+		AverageLeadTimeSeconds:  int64(s.AverageLeadTime.Seconds()),
+		AverageCycleTimeSeconds: int64(s.AverageCycleTime.Seconds()),
+		CurrentStreak:           s.CurrentStreak,
+		LongestStreak:           s.LongestStreak,
+		Days:                    []dayRecord{},
+		Weeks:                   []weekRecord{},
+		Tags:                    []tagRecord{},
+		Estimates: estimateRecord{
+			Tasks:               s.Estimates.Tasks,
+			EstimatedSeconds:    int64(s.Estimates.Estimated.Seconds()),
+			ActualSeconds:       int64(s.Estimates.Actual.Seconds()),
+			PointTasks:          s.Estimates.PointTasks,
+			Points:              s.Estimates.Points,
+			PointsActualSeconds: int64(s.Estimates.PointsActual.Seconds()),
+		},
+	}
+	for _, d := range s.Days {
+		r.Days = append(r.Days, dayRecord{Date: items.FormatDate(d.Date), Completed: d.Completed, Created: d.Created, Open: d.Open, Pomodoros: d.Pomodoros})
+	}
+	for _, w := range s.Weeks {
+		r.Weeks = append(r.Weeks, weekRecord{Start: items.FormatDate(w.Start), Completed: w.Completed})
+	}
+	for _, t := range s.Tags {
+		r.Tags = append(r.Tags, tagRecord{Tag: t.Tag, Completed: t.Completed, AverageLeadTimeSeconds: int64(t.AverageLeadTime.Seconds())})
+	}
+	return r
+}
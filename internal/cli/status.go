@@ -4,19 +4,32 @@ import (
 	"fmt"
 	"strconv"
 
+	"github.com/markelca/prioritty/internal/config"
+	"github.com/markelca/prioritty/internal/service"
 	"github.com/markelca/prioritty/internal/tui"
 	"github.com/markelca/prioritty/pkg/items"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
+var startTimer bool
+
 func init() {
 	rootCmd.AddCommand(doneCmd)
 	rootCmd.AddCommand(todoCmd)
 	rootCmd.AddCommand(cancelCmd)
 	rootCmd.AddCommand(startCmd)
+	startCmd.Flags().BoolVar(&startTimer, "timer", false, "Start a timer tracking the time spent on the tasks (default timer_on_start)")
 }
 
 func updateTaskStatus(args []string, status items.Status) error {
+	return updateTasks(args, func(s service.Service, t *items.Task) error {
+		return s.UpdateStatus(t, status)
+	})
+}
+
+// updateTasks applies update to the tasks at the indexes given.
+func updateTasks(args []string, update func(service.Service, *items.Task) error) error {
 	if len(args) == 0 {
 		return fmt.Errorf("please provide at least one task ID")
 	}
@@ -39,7 +52,7 @@ func updateTaskStatus(args []string, status items.Status) error {
 				continue
 			}
 
-			err = m.Service.UpdateStatus(v, status)
+			err = update(m.Service, v)
 			if err != nil {
 				fmt.Printf("Failed to update task %d: %v\n", i, err)
 				continue
@@ -85,8 +98,22 @@ var startCmd = &cobra.Command{
 	Use:     "start [task_ids...]",
 	Aliases: []string{"progress", "pg"},
 	Short:   "Mark tasks as in progress",
-	Args:    cobra.MinimumNArgs(1),
+	Long: `Marks tasks as in progress, optionally starting a timer tracking the time spent
+on them (see the timer and log commands). The timer stops when they leave in
+progress, or when another timer starts:
+
+  pt start 3 --timer`,
+	Args: cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		timer := viper.GetBool(config.CONF_TIMER_ON_START)
+		if cmd.Flags().Changed("timer") {
+			timer = startTimer
+		}
+		if timer {
+			return updateTasks(args, func(s service.Service, t *items.Task) error {
+				return s.StartTimer(t)
+			})
+		}
 		return updateTaskStatus(args, items.InProgress)
 	},
 }
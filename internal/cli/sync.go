@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"github.com/markelca/prioritty/internal/config"
+	"github.com/markelca/prioritty/internal/hooks"
+	"github.com/markelca/prioritty/internal/tui"
+	"github.com/markelca/prioritty/pkg/items/repository"
+	"github.com/markelca/prioritty/pkg/items/repository/reposync"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	syncRemoteType string
+	syncRemotePath string
+	syncPolicy     string
+	syncDryRun     bool
+)
+
+func init() {
+	syncCmd.Flags().StringVar(&syncRemoteType, "remote-type", "", "Type of the repository to sync with (default sync_repository_type)")
+	syncCmd.Flags().StringVar(&syncRemotePath, "remote-path", "", "Path of the repository to sync with (default sync_database_path)")
+	syncCmd.Flags().StringVarP(&syncPolicy, "policy", "p", "", "How to resolve items changed on both sides: newest, local, remote or skip (default sync_policy)")
+	syncCmd.Flags().BoolVarP(&syncDryRun, "dry-run", "n", false, "Show the changes without making them")
+	rootCmd.AddCommand(syncCmd)
+}
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Args:  cobra.NoArgs,
+	Short: "Syncs the repository with another one",
+	Long: `Reconciles the configured repository (local) with another one (remote), e.g. a
+SQLite database with an Obsidian vault:
+
+  pt sync --remote-type obsidian --remote-path ~/vault
+
+Items created, edited or deleted on one side since the last sync are created,
+updated or deleted on the other, along with the completion time, status history
+and time log of tasks when the other side can keep them. Items edited on both sides are resolved with
+the policy: the newest version wins by default. Edits win over deletions.
+
+The state of the last sync is kept in a file per pair of repositories, in
+sync_state_dir, or in sync_state_path if set. A state file only syncs the pair
+it was created for, others are refused.
+
+Syncing doesn't run the hooks: the changes made on the other side are saved as
+they are, and a warning is printed when hooks are configured.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		policy, err := reposync.ParsePolicy(firstNonEmpty(syncPolicy, viper.GetString(config.CONF_SYNC_POLICY)))
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		localType := viper.GetString(config.CONF_REPOSITORY_TYPE)
+		localPath, err := repository.GetDatabasePath(localType, viper.GetBool("demo"))
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		remoteType := firstNonEmpty(syncRemoteType, viper.GetString(config.CONF_SYNC_REPOSITORY_TYPE))
+		remotePath := repository.ExpandTilde(firstNonEmpty(syncRemotePath, viper.GetString(config.CONF_SYNC_DATABASE_PATH)))
+		if remoteType == "" || remotePath == "" {
+			log.Printf("Error: no repository to sync with, set sync_repository_type and sync_database_path or use --remote-type and --remote-path")
+			return
+		}
+		if remoteType == localType && remotePath == localPath {
+			log.Printf("Error: can't sync %s with itself", localPath)
+			return
+		}
+
+		local, err := tui.OpenRepository(localType, localPath)
+		if err != nil {
+			log.Printf("Error opening %s: %v", localPath, err)
+			return
+		}
+		remote, err := tui.OpenRepository(remoteType, remotePath)
+		if err != nil {
+			log.Printf("Error opening %s: %v", remotePath, err)
+			return
+		}
+
+		if scripts, err := hooks.Load(repository.ExpandTilde(viper.GetString(config.CONF_HOOKS_PATH))); err == nil && scripts != nil {
+			fmt.Println("Warning: syncing doesn't run the hooks, the changes are saved as they are")
+		}
+
+		pair := reposync.Pair{
+			Local:  reposync.Location{Type: localType, Path: absPath(localPath)},
+			Remote: reposync.Location{Type: remoteType, Path: absPath(remotePath)},
+		}
+		statePath := repository.ExpandTilde(viper.GetString(config.CONF_SYNC_STATE_PATH))
+		if statePath == "" {
+			statePath = reposync.DefaultStatePath(repository.ExpandTilde(viper.GetString(config.CONF_SYNC_STATE_DIR)), pair)
+		}
+		result, err := reposync.SyncWithState(statePath, pair, local, remote, reposync.Options{Policy: policy, DryRun: syncDryRun})
+		for _, c := range result.Changes {
+			fmt.Printf("%s: %s %s %q\n", c.Side, c.Action, c.Type, c.Title)
+		}
+		for _, c := range result.Conflicts {
+			fmt.Printf("Conflict: %s %q %s\n", c.Type, c.Title, c.Resolution)
+		}
+		if err != nil {
+			log.Printf("Error syncing: %v", err)
+			return
+		}
+
+		summary := fmt.Sprintf("%d changes, %d conflicts", len(result.Changes), len(result.Conflicts))
+		if syncDryRun {
+			fmt.Printf("Dry run: %s\n", summary)
+		} else {
+			fmt.Printf("Synced with %s: %s\n", remotePath, summary)
+		}
+	},
+}
+
+// absPath returns the absolute path of a repository, so it's keyed the same way
+// wherever pt runs from.
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -1,14 +1,15 @@
 package cli
 
 import (
-	tea "github.com/charmbracelet/bubbletea"
-	"github.com/markelca/prioritty/internal/tui"
+	"fmt"
+
 	"github.com/markelca/prioritty/pkg/items"
 	"github.com/spf13/cobra"
 )
 
 func init() {
 	rootCmd.AddCommand(taskCmd)
+	addTemplateFlag(taskCmd)
 }
 
 var taskCmd = &cobra.Command{
@@ -17,14 +18,12 @@ var taskCmd = &cobra.Command{
 	Args:    cobra.MaximumNArgs(1),
 	Short:   "Adds a new task",
 	Run: func(cmd *cobra.Command, args []string) {
-		if len(args) == 0 {
-			// Create with editor
-			m := tui.CreateModel(items.ItemTypeTask)
-			tea.NewProgram(m).Run()
-		} else {
-			// Create with title only
-			m := tui.InitialModel(false)
-			m.Service.AddTask(args[0])
+		title := ""
+		if len(args) > 0 {
+			title = args[0]
+		}
+		if err := addItem(cmd, items.ItemTypeTask, title); err != nil {
+			fmt.Printf("Failed to add the task: %v\n", err)
 		}
 	},
 }
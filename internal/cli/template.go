@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/markelca/prioritty/internal/editor"
+	"github.com/markelca/prioritty/internal/templates"
+	"github.com/markelca/prioritty/internal/tui"
+	"github.com/markelca/prioritty/pkg/items"
+	"github.com/spf13/cobra"
+)
+
+// addTemplateFlag adds the --template flag to a command adding items.
+func addTemplateFlag(cmd *cobra.Command) {
+	cmd.Flags().String("template", "", "Template of the new item, from templates_path (empty for none)")
+	cmd.RegisterFlagCompletionFunc("template", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		names, _ := tui.TemplateNames()
+		return names, cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
+// addItem adds an item of the type: with the editor when title is empty, otherwise
+// directly. It starts from the template of the --template flag, or the default one
+// of the type, asking the questions of its prompts first.
+func addItem(cmd *cobra.Command, itemType items.ItemType, title string) error {
+	name := tui.DefaultTemplate(itemType)
+	if cmd.Flags().Changed("template") {
+		name, _ = cmd.Flags().GetString("template")
+	}
+
+	if name == "" {
+		if title == "" {
+			_, err := tea.NewProgram(tui.CreateModel(itemType)).Run()
+			return err
+		}
+		m := tui.InitialModel(false)
+		if itemType == items.ItemTypeNote {
+			return m.Service.AddNote(title)
+		}
+		return m.Service.AddTask(title)
+	}
+
+	t, err := tui.LoadTemplate(name)
+	if err != nil {
+		return err
+	}
+	content, err := renderTemplate(t, title)
+	if err != nil {
+		return err
+	}
+
+	if title == "" {
+		_, err := tea.NewProgram(tui.CreateModelFromTemplate(itemType, content)).Run()
+		return err
+	}
+	// The title given replaces the template's, unless it's part of it
+	if t.UsesTitle() {
+		title = ""
+	}
+	msg := editor.CreateFromTemplate(itemType, content, title)
+	if msg.Err != nil {
+		return msg.Err
+	}
+	m := tui.InitialModel(false)
+	if msg.ItemType == items.ItemTypeNote {
+		return m.Service.CreateNoteFromEditorMsg(msg)
+	}
+	return m.Service.CreateTaskFromEditorMsg(msg)
+}
+
+// renderTemplate asks the questions of the prompts of a template on the terminal, and
+// returns the template rendered with the answers.
+func renderTemplate(t templates.Template, title string) (string, error) {
+	answers := map[string]string{}
+	reader := bufio.NewReader(os.Stdin)
+	for _, prompt := range t.Prompts() {
+		fmt.Fprintf(os.Stderr, "%s: ", prompt)
+		answer, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", err
+		}
+		answers[prompt] = strings.TrimSpace(answer)
+	}
+	return t.Render(templates.Values{Now: time.Now(), Title: title, Answers: answers}), nil
+}
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/markelca/prioritty/internal/tui"
+	"github.com/markelca/prioritty/internal/tui/styles"
+	"github.com/markelca/prioritty/pkg/items"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(timerCmd)
+	timerCmd.AddCommand(timerStatusCmd)
+	timerCmd.AddCommand(timerStopCmd)
+}
+
+var timerCmd = &cobra.Command{
+	Use:   "timer",
+	Args:  cobra.NoArgs,
+	Short: "Shows or stops the timer tracking the time spent on a task",
+	Long: `Shows or stops the timer tracking the time spent on a task. Timers start with
+pt start --timer (or the p key of the TUI, with timer_on_start), and stop when
+the task leaves in progress:
+
+  pt timer status
+  pt timer stop`,
+	Run: func(cmd *cobra.Command, args []string) {
+		timerStatusCmd.Run(cmd, args)
+	},
+}
+
+var timerStatusCmd = &cobra.Command{
+	Use:   "status",
+	Args:  cobra.NoArgs,
+	Short: "Shows the task whose timer is running",
+	Run: func(cmd *cobra.Command, args []string) {
+		m := tui.InitialModel(false)
+		running, err := m.Service.RunningTimers()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		if len(running) == 0 {
+			fmt.Println("No timer running")
+			return
+		}
+		m.LoadItems() // numbers the tasks as in the list
+		now := time.Now()
+		for _, t := range running {
+			printTaskLine(m, &t)
+			entry, _ := t.RunningTimer()
+			fmt.Println(styles.Secondary.Render(fmt.Sprintf("  running for %s, since %s · %s in total",
+				items.FormatWorkTime(entry.Duration(now)), entry.Start.Local().Format("15:04"),
+				items.FormatWorkTime(t.TimeSpent(now)))))
+		}
+	},
+}
+
+var timerStopCmd = &cobra.Command{
+	Use:   "stop",
+	Args:  cobra.NoArgs,
+	Short: "Stops the running timer, the task staying in progress",
+	Run: func(cmd *cobra.Command, args []string) {
+		m := tui.InitialModel(false)
+		running, err := m.Service.RunningTimers()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		if len(running) == 0 {
+			fmt.Println("No timer running")
+			return
+		}
+		for _, t := range running {
+			if err := m.Service.StopTimer(&t); err != nil {
+				log.Printf("Error stopping the timer of %q: %v", t.Title, err)
+				continue
+			}
+			fmt.Printf("Stopped the timer of %q, %s spent in total\n", t.Title, items.FormatWorkTime(t.TimeSpent(time.Now())))
+		}
+	},
+}
+
+// printTaskLine prints a task with the index used by the other commands.
+func printTaskLine(m tui.Model, t *items.Task) {
+	line := styles.Secondary.Render(fmt.Sprintf("%d. ", m.IndexOf(t)+1)) + tui.GetItemIcon(t) + t.Title
+	if t.Tag != nil {
+		line += " " + styles.Secondary.Render("@"+t.Tag.Name)
+	}
+	fmt.Println(line)
+}
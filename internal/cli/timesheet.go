@@ -0,0 +1,193 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/markelca/prioritty/internal/service"
+	"github.com/markelca/prioritty/internal/tui"
+	"github.com/markelca/prioritty/internal/tui/styles"
+	"github.com/markelca/prioritty/pkg/items"
+	"github.com/spf13/cobra"
+)
+
+var (
+	timesheetWeek string
+	timesheetJSON bool
+)
+
+// timesheetLabelWidth is the width of the column of tags and task titles.
+const timesheetLabelWidth = 28
+
+// timesheetCellWidth is the width of the columns of the days and the total.
+const timesheetCellWidth = 8
+
+func init() {
+	timesheetCmd.Flags().StringVar(&timesheetWeek, "week", "", "A day of the week, YYYY-MM-DD (default today)")
+	timesheetCmd.Flags().BoolVar(&timesheetJSON, "json", false, "Print the timesheet as JSON")
+	rootCmd.AddCommand(timesheetCmd)
+}
+
+var timesheetCmd = &cobra.Command{
+	Use:   "timesheet",
+	Args:  cobra.NoArgs,
+	Short: "Shows the time spent on tasks in a week, per tag",
+	Long: `Shows the time logged on tasks (see the timer and log commands) per day of a
+week, from Monday to Sunday, grouped by tag. Running timers count up to now:
+
+  pt timesheet
+  pt timesheet --week 2025-06-02 --json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		day := time.Now()
+		if timesheetWeek != "" {
+			var err error
+			if day, err = items.ParseDate(timesheetWeek); err != nil {
+				log.Printf("Error: invalid --week date %q, expected YYYY-MM-DD", timesheetWeek)
+				return
+			}
+		}
+
+		m := tui.InitialModel(false)
+		sheet, err := m.Service.GetTimesheet(day)
+		if err != nil {
+			log.Printf("Error computing the timesheet: %v", err)
+			return
+		}
+
+		if timesheetJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(timesheetRecordFrom(sheet)); err != nil {
+				log.Printf("Error: %v", err)
+			}
+			return
+		}
+		fmt.Print(renderTimesheet(sheet))
+	},
+}
+
+func renderTimesheet(s service.Timesheet) string {
+	var b strings.Builder
+	label := styles.Secondary.Render
+	end := s.Start.AddDate(0, 0, 6)
+	fmt.Fprintf(&b, "\n  %s\n\n", label(fmt.Sprintf("Week of %s → %s", items.FormatDate(s.Start), items.FormatDate(end))))
+	if len(s.Tags) == 0 {
+		b.WriteString("  No time logged this week\n")
+		return b.String()
+	}
+
+	header := []string{}
+	for i := range s.Days {
+		header = append(header, s.Start.AddDate(0, 0, i).Format("Mon 02"))
+	}
+	header = append(header, "Total")
+	b.WriteString("  " + timesheetRow("", header, label) + "\n")
+
+	for _, tag := range s.Tags {
+		title := styles.Default.Underline(true).Render
+		b.WriteString("  " + timesheetRow(tagLabel(tag.Tag), timesheetCells(tag.Days, tag.Total), title) + "\n")
+		for _, t := range tag.Tasks {
+			b.WriteString("  " + timesheetRow("  "+t.Task.Title, timesheetCells(t.Days, t.Total), styles.Default.Render) + "\n")
+		}
+	}
+	b.WriteString("  " + timesheetRow("Total", timesheetCells(s.Days, s.Total), styles.Done.Render) + "\n")
+	return b.String()
+}
+
+// timesheetRow lays out a row of the timesheet: a label, rendered with style, and cells.
+func timesheetRow(name string, cells []string, style func(...string) string) string {
+	name = truncate(name, timesheetLabelWidth)
+	row := style(name) + strings.Repeat(" ", timesheetLabelWidth-lipgloss.Width(name))
+	for _, cell := range cells {
+		row += fmt.Sprintf("%*s", timesheetCellWidth, cell)
+	}
+	return row
+}
+
+// timesheetCells formats the time of each day and the total, empty days as "-".
+func timesheetCells(days [7]time.Duration, total time.Duration) []string {
+	var cells []string
+	for _, d := range append(days[:], total) {
+		if d < time.Minute {
+			cells = append(cells, "-")
+			continue
+		}
+		cells = append(cells, fmt.Sprintf("%d:%02d", int(d/time.Hour), int(d%time.Hour/time.Minute)))
+	}
+	return cells
+}
+
+// truncate shortens s to width columns, with an ellipsis.
+func truncate(s string, width int) string {
+	if lipgloss.Width(s) <= width-1 {
+		return s
+	}
+	runes := []rune(s)
+	for lipgloss.Width(string(runes)) > width-2 {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
+// timesheetRecord is the JSON output of the timesheet command. Durations are in
+// seconds, per day from Monday.
+type timesheetRecord struct {
+	Week         string               `json:"week"` // Monday, YYYY-MM-DD
+	DaysSeconds  []int64              `json:"days_seconds"`
+	TotalSeconds int64                `json:"total_seconds"`
+	Tags         []timesheetTagRecord `json:"tags"`
+}
+
+type timesheetTagRecord struct {
+	Tag          string                `json:"tag"`
+	DaysSeconds  []int64               `json:"days_seconds"`
+	TotalSeconds int64                 `json:"total_seconds"`
+	Tasks        []timesheetTaskRecord `json:"tasks"`
+}
+
+type timesheetTaskRecord struct {
+	Title        string  `json:"title"`
+	UID          string  `json:"uid,omitempty"`
+	DaysSeconds  []int64 `json:"days_seconds"`
+	TotalSeconds int64   `json:"total_seconds"`
+}
+
+func seconds(days [7]time.Duration) []int64 {
+	var s []int64
+	for _, d := range days {
+		s = append(s, int64(d.Seconds()))
+	}
+	return s
+}
+
+func timesheetRecordFrom(s service.Timesheet) timesheetRecord {
+	r := timesheetRecord{
+		Week:         items.FormatDate(s.Start),
+		DaysSeconds:  seconds(s.Days),
+		TotalSeconds: int64(s.Total.Seconds()),
+		Tags:         []timesheetTagRecord{},
+	}
+	for _, tag := range s.Tags {
+		tr := timesheetTagRecord{
+			Tag:          tag.Tag,
+			DaysSeconds:  seconds(tag.Days),
+			TotalSeconds: int64(tag.Total.Seconds()),
+			Tasks:        []timesheetTaskRecord{},
+		}
+		for _, t := range tag.Tasks {
+			tr.Tasks = append(tr.Tasks, timesheetTaskRecord{
+				Title:        t.Task.Title,
+				UID:          t.Task.UID,
+				DaysSeconds:  seconds(t.Days),
+				TotalSeconds: int64(t.Total.Seconds()),
+			})
+		}
+		r.Tags = append(r.Tags, tr)
+	}
+	return r
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/markelca/prioritty/internal/notify"
 	"github.com/markelca/prioritty/internal/tui"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -19,6 +20,7 @@ var tuiCmd = &cobra.Command{
 	Short: "Launch the interactive TUI",
 	Long:  `Launch the interactive Terminal User Interface for managing tasks.`,
 	Run: func(cmd *cobra.Command, args []string) {
+		notify.LongRunning()
 		model := tui.InitialModel(true)
 		p := tea.NewProgram(
 			model,
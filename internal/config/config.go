@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/spf13/viper"
 	"gopkg.in/yaml.v3"
@@ -14,13 +15,64 @@ const CONF_LOG_FILE_PATH string = "log_file_path"
 const CONF_DEFAULT_COMMAND string = "default_command"
 const CONF_EDITOR string = "editor"
 const CONF_REPOSITORY_TYPE string = "repository_type"
+const CONF_OBSIDIAN_INLINE_TASKS string = "obsidian_inline_tasks"
+const CONF_OBSIDIAN_GIT_HISTORY string = "obsidian_git_history"
+const CONF_TIMEZONE string = "timezone"
+const CONF_SYNC_REPOSITORY_TYPE string = "sync_repository_type"
+const CONF_SYNC_DATABASE_PATH string = "sync_database_path"
+const CONF_SYNC_POLICY string = "sync_policy"
+const CONF_SYNC_STATE_PATH string = "sync_state_path"
+const CONF_SYNC_STATE_DIR string = "sync_state_dir"
+const CONF_SERVE_ADDR string = "serve_addr"
+const CONF_SERVE_TOKEN string = "serve_token"
+const CONF_DAEMON_SOCKET_PATH string = "daemon_socket_path"
+const CONF_NOTIFY string = "notify"
+const CONF_HOOKS_PATH string = "hooks_path"
+const CONF_TEMPLATES_PATH string = "templates_path"
+const CONF_TASK_TEMPLATE string = "task_template"
+const CONF_NOTE_TEMPLATE string = "note_template"
+const CONF_TIMER_ON_START string = "timer_on_start"
+const CONF_POMODORO_WORK string = "pomodoro_work"
+const CONF_POMODORO_BREAK string = "pomodoro_break"
+const CONF_POMODORO_LONG_BREAK string = "pomodoro_long_break"
+const CONF_AGENDA_DAYS string = "agenda_days"
 
 type Config struct {
-	DatabasePath   string `mapstructure:"database_path" yaml:"database_path"`
-	LogFilePath    string `mapstructure:"log_file_path" yaml:"log_file_path"`
-	DefaultCommand string `mapstructure:"default_command" yaml:"default_command"`
-	Editor         string `mapstructure:"editor" yaml:"editor"`
-	RepositoryType string `mapstructure:"repository_type" yaml:"repository_type"`
+	DatabasePath        string       `mapstructure:"database_path" yaml:"database_path"`
+	LogFilePath         string       `mapstructure:"log_file_path" yaml:"log_file_path"`
+	DefaultCommand      string       `mapstructure:"default_command" yaml:"default_command"`
+	Editor              string       `mapstructure:"editor" yaml:"editor"`
+	RepositoryType      string       `mapstructure:"repository_type" yaml:"repository_type"`
+	ObsidianInlineTasks bool         `mapstructure:"obsidian_inline_tasks" yaml:"obsidian_inline_tasks"`
+	ObsidianGitHistory  bool         `mapstructure:"obsidian_git_history" yaml:"obsidian_git_history"`
+	Timezone            string       `mapstructure:"timezone" yaml:"timezone,omitempty"`
+	SyncRepositoryType  string       `mapstructure:"sync_repository_type" yaml:"sync_repository_type,omitempty"`
+	SyncDatabasePath    string       `mapstructure:"sync_database_path" yaml:"sync_database_path,omitempty"`
+	SyncPolicy          string       `mapstructure:"sync_policy" yaml:"sync_policy"`
+	SyncStatePath       string       `mapstructure:"sync_state_path" yaml:"sync_state_path,omitempty"` // one per pair in sync_state_dir when empty
+	SyncStateDir        string       `mapstructure:"sync_state_dir" yaml:"sync_state_dir"`
+	ServeAddr           string       `mapstructure:"serve_addr" yaml:"serve_addr"`
+	ServeToken          string       `mapstructure:"serve_token" yaml:"serve_token,omitempty"`
+	DaemonSocketPath    string       `mapstructure:"daemon_socket_path" yaml:"daemon_socket_path"`
+	Notify              []NotifySink `mapstructure:"notify" yaml:"notify,omitempty"`
+	HooksPath           string       `mapstructure:"hooks_path" yaml:"hooks_path"`
+	TemplatesPath       string       `mapstructure:"templates_path" yaml:"templates_path"`
+	TaskTemplate        string       `mapstructure:"task_template" yaml:"task_template,omitempty"`
+	NoteTemplate        string       `mapstructure:"note_template" yaml:"note_template,omitempty"`
+	TimerOnStart        bool         `mapstructure:"timer_on_start" yaml:"timer_on_start"`
+	PomodoroWork        string       `mapstructure:"pomodoro_work" yaml:"pomodoro_work"`             // e.g. 25m
+	PomodoroBreak       string       `mapstructure:"pomodoro_break" yaml:"pomodoro_break"`           // e.g. 5m
+	PomodoroLongBreak   string       `mapstructure:"pomodoro_long_break" yaml:"pomodoro_long_break"` // every 4th break
+	AgendaDays          int          `mapstructure:"agenda_days" yaml:"agenda_days"`                 // upcoming days of the agenda
+}
+
+// NotifySink is where the events of the changes to items are sent: POSTed as JSON
+// to a URL, or written to the stdin of a command.
+type NotifySink struct {
+	URL     string   `mapstructure:"url" yaml:"url,omitempty"`
+	Command string   `mapstructure:"command" yaml:"command,omitempty"`
+	Args    []string `mapstructure:"args" yaml:"args,omitempty"`
+	Events  []string `mapstructure:"events" yaml:"events,omitempty"` // all events when empty
 }
 
 var config *Config
@@ -64,6 +116,10 @@ func InitConfig(cfgFile string) error {
 		}
 	}
 
+	if err := setTimezone(); err != nil {
+		return err
+	}
+
 	if err := viper.Unmarshal(config); err != nil {
 		return fmt.Errorf("unable to decode config: %w", err)
 	}
@@ -71,17 +127,52 @@ func InitConfig(cfgFile string) error {
 	return nil
 }
 
+// setTimezone makes the configured time zone the local one. Times are stored in UTC
+// and shown in this time zone, the system's by default.
+func setTimezone() error {
+	tz := viper.GetString(CONF_TIMEZONE)
+	if tz == "" {
+		return nil
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return fmt.Errorf("invalid timezone %q: %w", tz, err)
+	}
+	time.Local = loc
+	return nil
+}
+
 func createConfigFile(configDir string) error {
 	if err := os.MkdirAll(configDir, 0755); err != nil {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
 	cfg := Config{
-		DatabasePath:   viper.GetString(CONF_DATABASE_PATH),
-		LogFilePath:    viper.GetString(CONF_LOG_FILE_PATH),
-		DefaultCommand: viper.GetString(CONF_DEFAULT_COMMAND),
-		Editor:         viper.GetString(CONF_EDITOR),
-		RepositoryType: viper.GetString(CONF_REPOSITORY_TYPE),
+		DatabasePath:        viper.GetString(CONF_DATABASE_PATH),
+		LogFilePath:         viper.GetString(CONF_LOG_FILE_PATH),
+		DefaultCommand:      viper.GetString(CONF_DEFAULT_COMMAND),
+		Editor:              viper.GetString(CONF_EDITOR),
+		RepositoryType:      viper.GetString(CONF_REPOSITORY_TYPE),
+		ObsidianInlineTasks: viper.GetBool(CONF_OBSIDIAN_INLINE_TASKS),
+		ObsidianGitHistory:  viper.GetBool(CONF_OBSIDIAN_GIT_HISTORY),
+		Timezone:            viper.GetString(CONF_TIMEZONE),
+		SyncRepositoryType:  viper.GetString(CONF_SYNC_REPOSITORY_TYPE),
+		SyncDatabasePath:    viper.GetString(CONF_SYNC_DATABASE_PATH),
+		SyncPolicy:          viper.GetString(CONF_SYNC_POLICY),
+		SyncStatePath:       viper.GetString(CONF_SYNC_STATE_PATH),
+		SyncStateDir:        viper.GetString(CONF_SYNC_STATE_DIR),
+		ServeAddr:           viper.GetString(CONF_SERVE_ADDR),
+		ServeToken:          viper.GetString(CONF_SERVE_TOKEN),
+		DaemonSocketPath:    viper.GetString(CONF_DAEMON_SOCKET_PATH),
+		HooksPath:           viper.GetString(CONF_HOOKS_PATH),
+		TemplatesPath:       viper.GetString(CONF_TEMPLATES_PATH),
+		TaskTemplate:        viper.GetString(CONF_TASK_TEMPLATE),
+		NoteTemplate:        viper.GetString(CONF_NOTE_TEMPLATE),
+		TimerOnStart:        viper.GetBool(CONF_TIMER_ON_START),
+		PomodoroWork:        viper.GetString(CONF_POMODORO_WORK),
+		PomodoroBreak:       viper.GetString(CONF_POMODORO_BREAK),
+		PomodoroLongBreak:   viper.GetString(CONF_POMODORO_LONG_BREAK),
+		AgendaDays:          viper.GetInt(CONF_AGENDA_DAYS),
 	}
 
 	configFile := filepath.Join(configDir, "prioritty.yaml")
@@ -106,4 +197,17 @@ func setDefaults(configDir string) {
 	viper.SetDefault(CONF_DEFAULT_COMMAND, "tui")
 	viper.SetDefault(CONF_EDITOR, "nano")
 	viper.SetDefault(CONF_REPOSITORY_TYPE, "sqlite")
+	viper.SetDefault(CONF_OBSIDIAN_INLINE_TASKS, false)
+	viper.SetDefault(CONF_OBSIDIAN_GIT_HISTORY, false)
+	viper.SetDefault(CONF_SYNC_POLICY, "newest")
+	viper.SetDefault(CONF_SYNC_STATE_DIR, filepath.Join(configDir, "sync"))
+	viper.SetDefault(CONF_SERVE_ADDR, "127.0.0.1:7420")
+	viper.SetDefault(CONF_DAEMON_SOCKET_PATH, filepath.Join(configDir, "prioritty.sock"))
+	viper.SetDefault(CONF_HOOKS_PATH, filepath.Join(configDir, "hooks"))
+	viper.SetDefault(CONF_TEMPLATES_PATH, filepath.Join(configDir, "templates"))
+	viper.SetDefault(CONF_TIMER_ON_START, false)
+	viper.SetDefault(CONF_POMODORO_WORK, "25m")
+	viper.SetDefault(CONF_POMODORO_BREAK, "5m")
+	viper.SetDefault(CONF_POMODORO_LONG_BREAK, "15m")
+	viper.SetDefault(CONF_AGENDA_DAYS, 7)
 }
@@ -7,6 +7,7 @@ import (
 	"os/exec"
 	"runtime"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/markelca/prioritty/internal/config"
@@ -17,76 +18,118 @@ import (
 
 // EditorInput contains the data to populate the editor temp file.
 type EditorInput struct {
-	Id       string
-	ItemType items.ItemType
-	Title    string
-	Body     string
-	Status   string
-	Tag      string
+	Id         string
+	ItemType   items.ItemType
+	Title      string
+	Body       string
+	Status     string
+	Tag        string
+	Priority   string
+	Due        string
+	Scheduled  string
+	Recurrence string
+	Estimate   string
 }
 
 // EditorFinishedMsg contains the parsed result from the editor.
 type EditorFinishedMsg struct {
-	Id       string
-	ItemType items.ItemType
-	Title    string
-	Body     string
-	Status   string
-	Tag      string
-	Err      error
+	Id         string
+	ItemType   items.ItemType
+	Title      string
+	Body       string
+	Status     string
+	Tag        string
+	Priority   items.Priority
+	Due        time.Time
+	Scheduled  time.Time
+	Recurrence string
+	Estimate   items.Estimate
+	Err        error
 }
 
 // AddItem opens the editor with an empty template for creating a new item.
 // Uses SerializeForEditor to show all available fields.
 func AddItem(itemType items.ItemType) (tea.Cmd, error) {
-	tempFile, err := os.CreateTemp(os.TempDir(), "item_*.md")
+	return openEditor(markdown.ItemInput{ItemType: itemType}, "")
+}
+
+// AddItemFromTemplate opens the editor for creating a new item from the content of a
+// rendered template, showing all available fields. The type of the template, if set,
+// replaces itemType.
+func AddItemFromTemplate(itemType items.ItemType, content string) (tea.Cmd, error) {
+	input, err := ParseTemplate(itemType, content)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create temp file: %w", err)
+		return nil, err
 	}
+	return openEditor(input, "")
+}
 
-	content, err := markdown.SerializeForEditor(markdown.ItemInput{
-		ItemType: itemType,
-	})
+// ParseTemplate returns the fields of a rendered template. The type of the template,
+// if set, replaces itemType.
+func ParseTemplate(itemType items.ItemType, content string) (markdown.ItemInput, error) {
+	var fm parsedFrontmatter
+	body, err := markdown.Parse(content, &fm)
 	if err != nil {
-		tempFile.Close()
-		return nil, fmt.Errorf("failed to serialize content: %w", err)
+		return markdown.ItemInput{}, fmt.Errorf("invalid template frontmatter: %w", err)
 	}
-
-	if _, err := tempFile.WriteString(content); err != nil {
-		tempFile.Close()
-		return nil, fmt.Errorf("failed to write to temp file: %w", err)
+	if t := items.ParseItemType(fm.Type); t != "" {
+		itemType = t
 	}
-	tempFile.Close()
+	return markdown.ItemInput{
+		ItemType:   itemType,
+		Title:      strings.TrimSpace(fm.Title),
+		Body:       strings.TrimSpace(body),
+		Status:     fm.Status,
+		Tag:        fm.Tag,
+		Priority:   fm.Priority,
+		Due:        fm.Due,
+		Scheduled:  fm.Scheduled,
+		Recurrence: fm.Recurrence,
+		Estimate:   fm.Estimate,
+	}, nil
+}
 
-	editor, err := getEditor()
+// CreateFromTemplate returns the item of a rendered template without opening the
+// editor, titled title instead if not empty.
+func CreateFromTemplate(itemType items.ItemType, content, title string) EditorFinishedMsg {
+	input, err := ParseTemplate(itemType, content)
 	if err != nil {
-		return nil, err
+		return EditorFinishedMsg{Err: err}
 	}
-
-	return tea.ExecProcess(exec.Command(editor, tempFile.Name()), func(err error) tea.Msg {
-		defer os.Remove(tempFile.Name())
-		modifiedContent, err := os.ReadFile(tempFile.Name())
-		if err != nil {
-			return EditorFinishedMsg{Err: fmt.Errorf("failed to read modified file: %w", err)}
-		}
-
-		return parseEditorContent(string(modifiedContent), itemType)
-	}), nil
+	if title != "" {
+		input.Title = title
+	}
+	serialized, err := markdown.SerializeForEditor(input)
+	if err != nil {
+		return EditorFinishedMsg{Err: err}
+	}
+	return ParseContent(serialized, input.ItemType)
 }
 
 func EditItem(input EditorInput) (tea.Cmd, error) {
+	return openEditor(markdown.ItemInput{
+		ItemType:   input.ItemType,
+		Title:      input.Title,
+		Body:       input.Body,
+		Status:     input.Status,
+		Tag:        input.Tag,
+		Priority:   input.Priority,
+		Due:        input.Due,
+		Scheduled:  input.Scheduled,
+		Recurrence: input.Recurrence,
+		Estimate:   input.Estimate,
+	}, input.Id)
+}
+
+// openEditor opens the editor with the fields of an item, returning the changes as
+// an EditorFinishedMsg with the given ID.
+func openEditor(input markdown.ItemInput, id string) (tea.Cmd, error) {
 	tempFile, err := os.CreateTemp(os.TempDir(), "item_*.md")
 	if err != nil {
 		return nil, fmt.Errorf("failed to create temp file: %w", err)
 	}
 
-	content, err := markdown.SerializeForEditor(markdown.ItemInput{
-		ItemType: input.ItemType,
-		Title:    input.Title,
-		Body:     input.Body,
-		Status:   input.Status,
-		Tag:      input.Tag,
-	})
+	content, err := markdown.SerializeForEditor(input)
 	if err != nil {
 		tempFile.Close()
 		return nil, fmt.Errorf("failed to serialize content: %w", err)
@@ -110,8 +153,8 @@ func EditItem(input EditorInput) (tea.Cmd, error) {
 			return EditorFinishedMsg{Err: fmt.Errorf("failed to read modified file: %w", err)}
 		}
 
-		msg := parseEditorContent(string(modifiedContent), input.ItemType)
-		msg.Id = input.Id
+		msg := ParseContent(string(modifiedContent), input.ItemType)
+		msg.Id = id
 
 		return msg
 	}), nil
@@ -119,14 +162,20 @@ func EditItem(input EditorInput) (tea.Cmd, error) {
 
 // parsedFrontmatter is used for parsing (uses regular strings)
 type parsedFrontmatter struct {
-	Title  string `yaml:"title"`
-	Type   string `yaml:"type"`
-	Status string `yaml:"status"`
-	Tag    string `yaml:"tag"`
+	Title      string `yaml:"title"`
+	Type       string `yaml:"type"`
+	Status     string `yaml:"status"`
+	Tag        string `yaml:"tag"`
+	Priority   string `yaml:"priority"`
+	Due        string `yaml:"due"`
+	Scheduled  string `yaml:"scheduled"`
+	Recurrence string `yaml:"recurrence"`
+	Estimate   string `yaml:"estimate"`
 }
 
-// parseEditorContent parses the editor content including frontmatter.
-func parseEditorContent(content string, itemType items.ItemType) EditorFinishedMsg {
+// ParseContent parses the content of an item, including frontmatter, as written in
+// the editor. itemType is the type of items without one.
+func ParseContent(content string, itemType items.ItemType) EditorFinishedMsg {
 	// Check if content is completely empty or only whitespace
 	trimmedContent := strings.TrimSpace(content)
 	if trimmedContent == "" {
@@ -153,12 +202,30 @@ func parseEditorContent(content string, itemType items.ItemType) EditorFinishedM
 		parsedType = itemType
 	}
 
+	due, err := items.ParseDate(fm.Due)
+	if err != nil {
+		return EditorFinishedMsg{Err: fmt.Errorf("invalid due date, expected YYYY-MM-DD: %w", err)}
+	}
+	scheduled, err := items.ParseDate(fm.Scheduled)
+	if err != nil {
+		return EditorFinishedMsg{Err: fmt.Errorf("invalid scheduled date, expected YYYY-MM-DD: %w", err)}
+	}
+	estimate, err := items.ParseEstimate(fm.Estimate)
+	if err != nil {
+		return EditorFinishedMsg{Err: err}
+	}
+
 	return EditorFinishedMsg{
-		ItemType: parsedType,
-		Title:    title,
-		Body:     strings.TrimSpace(body),
-		Status:   fm.Status,
-		Tag:      fm.Tag,
+		ItemType:   parsedType,
+		Title:      title,
+		Body:       strings.TrimSpace(body),
+		Status:     fm.Status,
+		Tag:        fm.Tag,
+		Priority:   items.ParsePriority(fm.Priority),
+		Due:        due,
+		Scheduled:  scheduled,
+		Recurrence: strings.TrimSpace(fm.Recurrence),
+		Estimate:   estimate,
 	}
 }
 
@@ -197,3 +264,35 @@ func getEditor() (string, error) {
 	}
 	return "", fmt.Errorf("Error - No available editor could be found")
 }
+
+// EditText opens the editor with content, waiting for it to close, and returns the
+// content saved. It's for text other than items, e.g. time entries; pattern names
+// the temporary file as in os.CreateTemp.
+func EditText(content, pattern string) (string, error) {
+	tempFile, err := os.CreateTemp(os.TempDir(), pattern)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tempFile.Name())
+	if _, err := tempFile.WriteString(content); err != nil {
+		tempFile.Close()
+		return "", fmt.Errorf("failed to write to temp file: %w", err)
+	}
+	tempFile.Close()
+
+	editor, err := getEditor()
+	if err != nil {
+		return "", err
+	}
+	cmd := exec.Command(editor, tempFile.Name())
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("running the editor: %w", err)
+	}
+
+	modified, err := os.ReadFile(tempFile.Name())
+	if err != nil {
+		return "", fmt.Errorf("failed to read modified file: %w", err)
+	}
+	return string(modified), nil
+}
@@ -0,0 +1,236 @@
+// Package hooks runs the executables of the hooks folder before items are changed,
+// like git hooks: on-add before an item is created, on-modify before it's changed and
+// on-delete before it's removed. Every executable whose name starts with the hook's
+// (e.g. on-add-require-tag) runs, in alphabetical order.
+//
+// Hooks get the item as a line of JSON on stdin (on-modify gets two lines, the item
+// before and after the change), with the fields of the HTTP API. on-add and on-modify
+// hooks may print the item back, changed, on stdout: the fields printed replace the
+// item's. Exiting with a non-zero status refuses the change, with the message
+// printed to stderr (or stdout) as the reason.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/markelca/prioritty/internal/editor"
+	"github.com/markelca/prioritty/internal/itemjson"
+	"github.com/markelca/prioritty/internal/service"
+	"github.com/markelca/prioritty/pkg/items"
+)
+
+const (
+	OnAdd    = "on-add"
+	OnModify = "on-modify"
+	OnDelete = "on-delete"
+)
+
+// timeout bounds each run of a hook, one taking longer refuses the change.
+const timeout = 10 * time.Second
+
+// Scripts are the hooks found in a folder.
+type Scripts struct {
+	paths map[string][]string // by hook
+}
+
+var _ service.Hooks = (*Scripts)(nil)
+
+// Load returns the hooks in dir, or nil if there are none (e.g. dir doesn't exist).
+func Load(dir string) (*Scripts, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Scripts{paths: map[string][]string{}}
+	found := false
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || !executable(info) {
+			continue
+		}
+		for _, hook := range []string{OnAdd, OnModify, OnDelete} {
+			if strings.HasPrefix(entry.Name(), hook) {
+				s.paths[hook] = append(s.paths[hook], filepath.Join(dir, entry.Name()))
+				found = true
+			}
+		}
+	}
+	if !found {
+		return nil, nil
+	}
+	for _, paths := range s.paths {
+		sort.Strings(paths)
+	}
+	return s, nil
+}
+
+// executable reports whether a file can be run as a hook. Windows has no execute
+// permission, there every file is.
+func executable(info fs.FileInfo) bool {
+	return runtime.GOOS == "windows" || info.Mode().Perm()&0111 != 0
+}
+
+func (s *Scripts) OnAdd(i items.ItemInterface) (items.ItemInterface, error) {
+	for _, path := range s.paths[OnAdd] {
+		out, err := run(path, OnAdd, i)
+		if err != nil {
+			return nil, err
+		}
+		if i, err = apply(i, out); err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+	}
+	return i, nil
+}
+
+func (s *Scripts) OnModify(old, modified items.ItemInterface) (items.ItemInterface, error) {
+	for _, path := range s.paths[OnModify] {
+		out, err := run(path, OnModify, old, modified)
+		if err != nil {
+			return nil, err
+		}
+		if modified, err = apply(modified, out); err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+	}
+	return modified, nil
+}
+
+func (s *Scripts) OnDelete(i items.ItemInterface) error {
+	for _, path := range s.paths[OnDelete] {
+		if _, err := run(path, OnDelete, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// run runs a hook with the items on stdin, one per line, and returns its output. A
+// hook failing refuses the change.
+func run(path, hook string, list ...items.ItemInterface) ([]byte, error) {
+	var stdin bytes.Buffer
+	enc := json.NewEncoder(&stdin)
+	for _, i := range list {
+		if err := enc.Encode(itemjson.RecordFrom(i)); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Stdin = &stdin
+	cmd.Env = append(os.Environ(), "PRIORITTY_HOOK="+hook)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+
+	err := cmd.Run()
+	name := filepath.Base(path)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("%w %s: timed out after %s", service.ErrVetoed, name, timeout)
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		reason := strings.TrimSpace(stderr.String())
+		if reason == "" {
+			reason = strings.TrimSpace(stdout.String())
+		}
+		if reason == "" {
+			reason = exitErr.Error()
+		}
+		return nil, fmt.Errorf("%w %s: %s", service.ErrVetoed, name, reason)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("running hook %s: %w", name, err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// apply returns the item with the fields a hook printed, the item itself if it
+// printed nothing.
+func apply(i items.ItemInterface, out []byte) (items.ItemInterface, error) {
+	if len(bytes.TrimSpace(out)) == 0 {
+		return i, nil
+	}
+	rec := itemjson.RecordFrom(i)
+	if err := json.Unmarshal(out, &rec); err != nil {
+		return nil, fmt.Errorf("invalid item printed: %w", err)
+	}
+	msg, err := itemjson.Input{
+		Type:       rec.Type,
+		Title:      rec.Title,
+		Body:       rec.Body,
+		Tag:        rec.Tag,
+		Status:     rec.Status,
+		Priority:   rec.Priority,
+		Due:        rec.Due,
+		Scheduled:  rec.Scheduled,
+		Recurrence: rec.Recurrence,
+		Estimate:   rec.Estimate,
+	}.EditorMsg()
+	if err != nil {
+		return nil, fmt.Errorf("invalid item printed: %w", err)
+	}
+	return withFields(i, msg)
+}
+
+// withFields returns a copy of the item with the fields of the message.
+func withFields(i items.ItemInterface, msg editor.EditorFinishedMsg) (items.ItemInterface, error) {
+	var tag *items.Tag
+	if msg.Tag != "" {
+		tag = &items.Tag{Name: msg.Tag}
+		if current := i.GetTag(); current != nil && current.Name == msg.Tag {
+			tag = current
+		}
+	}
+
+	switch v := i.(type) {
+	case *items.Task:
+		if msg.ItemType != items.ItemTypeTask {
+			return nil, errors.New("hooks can't change the type of items")
+		}
+		t := *v
+		t.Title, t.Body, t.Tag = msg.Title, msg.Body, tag
+		if msg.Status != "" {
+			t.Status = items.Status(msg.Status)
+		}
+		t.Priority = msg.Priority
+		t.Recurrence = msg.Recurrence
+		t.Estimate = msg.Estimate
+		// Keep the dates printed back unchanged as they were
+		if items.FormatDate(t.DueDate) != items.FormatDate(msg.Due) {
+			t.DueDate = msg.Due
+		}
+		if items.FormatDate(t.ScheduledDate) != items.FormatDate(msg.Scheduled) {
+			t.ScheduledDate = msg.Scheduled
+		}
+		return &t, nil
+	case *items.Note:
+		if msg.ItemType != items.ItemTypeNote {
+			return nil, errors.New("hooks can't change the type of items")
+		}
+		n := *v
+		n.Title, n.Body, n.Tag = msg.Title, msg.Body, tag
+		return &n, nil
+	}
+	return nil, fmt.Errorf("unsupported item %v", i)
+}
@@ -0,0 +1,190 @@
+// Package itemjson is the JSON form of items shared by the HTTP API, the MCP
+// tools, the hooks and the notifications.
+package itemjson
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/markelca/prioritty/internal/editor"
+	"github.com/markelca/prioritty/pkg/items"
+)
+
+// Record is the JSON of an item, in the API responses, the hooks, the MCP tools and
+// the notifications. Dates are YYYY-MM-DD and times RFC 3339, in UTC.
+type Record struct {
+	Id          string `json:"id"`
+	UID         string `json:"uid,omitempty"`
+	Type        string `json:"type"`
+	Title       string `json:"title"`
+	Body        string `json:"body"`
+	Tag         string `json:"tag,omitempty"`
+	Status      string `json:"status,omitempty"`
+	Priority    string `json:"priority,omitempty"`
+	Due         string `json:"due,omitempty"`
+	Scheduled   string `json:"scheduled,omitempty"`
+	Recurrence  string `json:"recurrence,omitempty"`
+	Estimate    string `json:"estimate,omitempty"`
+	CreatedAt   string `json:"created_at,omitempty"`
+	UpdatedAt   string `json:"updated_at,omitempty"`
+	CompletedAt string `json:"completed_at,omitempty"`
+	ETag        string `json:"etag"`
+}
+
+// Input is the JSON creating or replacing an item, e.g. in the API requests. Omitted
+// fields are cleared, except the status, which is kept (todo for new tasks).
+type Input struct {
+	Type       string `json:"type"` // task (default) or note, it can't be changed
+	Title      string `json:"title"`
+	Body       string `json:"body"`
+	Tag        string `json:"tag"`
+	Status     string `json:"status"`
+	Priority   string `json:"priority"`
+	Due        string `json:"due"`
+	Scheduled  string `json:"scheduled"`
+	Recurrence string `json:"recurrence"`
+	Estimate   string `json:"estimate"` // story points (e.g. 3pt) or a duration (e.g. 1h30m)
+}
+
+// RecordFrom returns the record of an item.
+func RecordFrom(i items.ItemInterface) Record {
+	r := Record{
+		Id:        i.GetId(),
+		UID:       i.GetUID(),
+		Type:      string(items.ItemTypeNote),
+		Title:     i.GetTitle(),
+		Body:      i.GetBody(),
+		CreatedAt: items.FormatTimestamp(i.GetCreatedAt()),
+		UpdatedAt: items.FormatTimestamp(i.GetUpdatedAt()),
+	}
+	if tag := i.GetTag(); tag != nil {
+		r.Tag = tag.Name
+	}
+	if t, ok := i.(*items.Task); ok {
+		r.Type = string(items.ItemTypeTask)
+		r.Status = string(t.Status)
+		r.Priority = string(t.Priority)
+		r.Due = items.FormatDate(t.DueDate)
+		r.Scheduled = items.FormatDate(t.ScheduledDate)
+		r.Recurrence = t.Recurrence
+		r.Estimate = t.Estimate.String()
+		r.CompletedAt = items.FormatTimestamp(t.CompletedAt)
+	}
+	r.ETag = etag(r)
+	return r
+}
+
+// etag identifies a version of an item: it changes whenever any of its fields does.
+func etag(r Record) string {
+	r.ETag = ""
+	data, _ := json.Marshal(r)
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:12]) + `"`
+}
+
+// EditorMsg validates the input and converts it to the changes the editor makes.
+func (in Input) EditorMsg() (editor.EditorFinishedMsg, error) {
+	msg := editor.EditorFinishedMsg{
+		ItemType:   items.ItemTypeTask,
+		Title:      strings.TrimSpace(in.Title),
+		Body:       in.Body,
+		Tag:        strings.TrimSpace(in.Tag),
+		Recurrence: in.Recurrence,
+	}
+	if in.Type != "" {
+		if msg.ItemType = items.ParseItemType(in.Type); msg.ItemType == "" {
+			return msg, fmt.Errorf("invalid type %q, expected task or note", in.Type)
+		}
+	}
+	if msg.Title == "" {
+		return msg, errors.New("title is required")
+	}
+	if msg.ItemType == items.ItemTypeNote {
+		if in.Status != "" || in.Priority != "" || in.Due != "" || in.Scheduled != "" || in.Recurrence != "" || in.Estimate != "" {
+			return msg, errors.New("notes have no status, priority, dates, recurrence or estimate")
+		}
+		return msg, nil
+	}
+
+	if in.Status != "" {
+		status, err := ParseStatus(in.Status)
+		if err != nil {
+			return msg, err
+		}
+		msg.Status = string(status)
+	}
+	if in.Priority != "" {
+		if msg.Priority = items.ParsePriority(in.Priority); msg.Priority == items.PriorityNone {
+			return msg, fmt.Errorf("invalid priority %q", in.Priority)
+		}
+	}
+	var err error
+	if msg.Due, err = items.ParseDate(in.Due); err != nil {
+		return msg, fmt.Errorf("invalid due date %q, expected YYYY-MM-DD", in.Due)
+	}
+	if msg.Scheduled, err = items.ParseDate(in.Scheduled); err != nil {
+		return msg, fmt.Errorf("invalid scheduled date %q, expected YYYY-MM-DD", in.Scheduled)
+	}
+	if msg.Estimate, err = items.ParseEstimate(in.Estimate); err != nil {
+		return msg, err
+	}
+	return msg, nil
+}
+
+// ParseStatus parses a status strictly, items.ParseStatus defaults to todo.
+func ParseStatus(s string) (items.Status, error) {
+	status := items.ParseStatus(s)
+	if status == items.Todo && !strings.EqualFold(strings.TrimSpace(s), string(items.Todo)) {
+		return "", fmt.Errorf("invalid status %q", s)
+	}
+	return status, nil
+}
+
+// Filter selects items by their fields. Empty fields match everything, the others
+// match any of their comma-separated values, case-insensitively.
+type Filter struct {
+	Type     string
+	Status   string
+	Tag      string
+	Priority string
+	Text     string // contained in the title or the body
+}
+
+// Records returns the records of the items matching the filter.
+func (f Filter) Records(all []items.ItemInterface) []Record {
+	records := []Record{}
+	for _, i := range all {
+		if rec := RecordFrom(i); f.Match(rec) {
+			records = append(records, rec)
+		}
+	}
+	return records
+}
+
+// Match reports whether an item matches the filter.
+func (f Filter) Match(rec Record) bool {
+	if !matches(f.Type, rec.Type) || !matches(f.Status, rec.Status) ||
+		!matches(f.Tag, rec.Tag) || !matches(f.Priority, rec.Priority) {
+		return false
+	}
+	text := strings.ToLower(f.Text)
+	return text == "" || strings.Contains(strings.ToLower(rec.Title+"\n"+rec.Body), text)
+}
+
+// matches reports whether a field matches a filter: an empty filter matches
+// everything, otherwise one of its comma-separated values must match.
+func matches(filter, value string) bool {
+	if filter == "" {
+		return true
+	}
+	for _, f := range strings.Split(filter, ",") {
+		if strings.EqualFold(strings.TrimSpace(f), value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,226 @@
+// Package mcp serves the items of a repository to AI assistants with the Model
+// Context Protocol: JSON-RPC 2.0 messages, one per line, over stdio.
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/markelca/prioritty/internal/service"
+)
+
+// protocolVersions are the supported MCP versions, the latest first.
+var protocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// boardURI is the URI of the board resource.
+const boardURI = "prioritty://board"
+
+// JSON-RPC error codes
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternalError  = -32603
+	codeNotFound       = -32002 // resource not found
+)
+
+// Server answers the MCP requests of a client with a service. When read-only, the
+// tools changing items aren't offered.
+type Server struct {
+	service  service.Service
+	readOnly bool
+	tools    []tool
+}
+
+// New returns an MCP server for the service.
+func New(s service.Service, readOnly bool) *Server {
+	srv := &Server{service: s, readOnly: readOnly}
+	for _, t := range srv.allTools() {
+		if !readOnly || !t.write {
+			srv.tools = append(srv.tools, t)
+		}
+	}
+	return srv
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Id      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Id      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return e.Message
+}
+
+// Serve answers the requests read from in, one per line, until it's closed.
+func (s *Server) Serve(in io.Reader, out io.Writer) error {
+	reader := bufio.NewReader(in)
+	enc := json.NewEncoder(out)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			if resp := s.handle(line); resp != nil {
+				if err := enc.Encode(resp); err != nil {
+					return err
+				}
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// handle answers a message, returning nil for notifications.
+func (s *Server) handle(line []byte) *response {
+	var req request
+	if err := json.Unmarshal(line, &req); err != nil {
+		return &response{JSONRPC: "2.0", Id: json.RawMessage("null"), Error: &rpcError{Code: codeParseError, Message: err.Error()}}
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return &response{JSONRPC: "2.0", Id: idOrNull(req.Id), Error: &rpcError{Code: codeInvalidRequest, Message: "invalid request"}}
+	}
+
+	result, err := s.call(req.Method, req.Params)
+	if len(req.Id) == 0 {
+		// Notifications, e.g. notifications/initialized, get no answer
+		return nil
+	}
+
+	resp := &response{JSONRPC: "2.0", Id: req.Id, Result: result}
+	if err != nil {
+		var rpcErr *rpcError
+		if !errors.As(err, &rpcErr) {
+			log.Printf("Error handling %s: %v", req.Method, err)
+			rpcErr = &rpcError{Code: codeInternalError, Message: err.Error()}
+		}
+		resp.Result, resp.Error = nil, rpcErr
+	}
+	return resp
+}
+
+func idOrNull(id json.RawMessage) json.RawMessage {
+	if len(id) == 0 {
+		return json.RawMessage("null")
+	}
+	return id
+}
+
+func (s *Server) call(method string, params json.RawMessage) (any, error) {
+	switch method {
+	case "initialize":
+		return s.initialize(params)
+	case "ping":
+		return struct{}{}, nil
+	case "tools/list":
+		return s.listTools(), nil
+	case "tools/call":
+		return s.callTool(params)
+	case "resources/list":
+		return s.listResources(), nil
+	case "resources/templates/list":
+		return map[string]any{"resourceTemplates": []any{}}, nil
+	case "resources/read":
+		return s.readResource(params)
+	default:
+		if strings.HasPrefix(method, "notifications/") {
+			return nil, nil
+		}
+		return nil, &rpcError{Code: codeMethodNotFound, Message: "method not found: " + method}
+	}
+}
+
+func (s *Server) initialize(params json.RawMessage) (any, error) {
+	var p struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	if err := unmarshalParams(params, &p); err != nil {
+		return nil, err
+	}
+	// Answer with the client's version if supported, otherwise with the latest one
+	version := protocolVersions[0]
+	if slices.Contains(protocolVersions, p.ProtocolVersion) {
+		version = p.ProtocolVersion
+	}
+
+	instructions := "Tasks and notes of a prioritty board. Items are identified by their type (task or note) and ID. Read " + boardURI + " for an overview."
+	if s.readOnly {
+		instructions += " The board is read-only."
+	}
+	return map[string]any{
+		"protocolVersion": version,
+		"capabilities": map[string]any{
+			"tools":     map[string]any{"listChanged": false},
+			"resources": map[string]any{"subscribe": false, "listChanged": false},
+		},
+		"serverInfo":   map[string]any{"name": "prioritty", "version": "dev"},
+		"instructions": instructions,
+	}, nil
+}
+
+func (s *Server) listResources() any {
+	return map[string]any{
+		"resources": []map[string]any{{
+			"uri":         boardURI,
+			"name":        "board",
+			"title":       "Board",
+			"description": "All tasks by status, and the notes, with their IDs",
+			"mimeType":    "text/markdown",
+		}},
+	}
+}
+
+func (s *Server) readResource(params json.RawMessage) (any, error) {
+	var p struct {
+		URI string `json:"uri"`
+	}
+	if err := unmarshalParams(params, &p); err != nil {
+		return nil, err
+	}
+	if p.URI != boardURI {
+		return nil, &rpcError{Code: codeNotFound, Message: "resource not found: " + p.URI}
+	}
+	board, err := s.board()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"contents": []map[string]any{{"uri": boardURI, "mimeType": "text/markdown", "text": board}},
+	}, nil
+}
+
+// unmarshalParams decodes the params of a request, failing with an invalid params error.
+func unmarshalParams(params json.RawMessage, v any) error {
+	if len(params) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("invalid params: %v", err)}
+	}
+	return nil
+}
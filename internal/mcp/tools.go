@@ -0,0 +1,339 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/markelca/prioritty/internal/itemjson"
+	"github.com/markelca/prioritty/pkg/items"
+)
+
+// tool is an MCP tool: its definition and the function answering its calls.
+type tool struct {
+	name        string
+	description string
+	properties  map[string]any
+	required    []string
+	write       bool // changes items, not offered when read-only
+	call        func(args toolArgs) (any, error)
+}
+
+// toolArgs are the arguments of all tools, each uses some of them.
+type toolArgs struct {
+	Type      string `json:"type"`
+	Id        string `json:"id"`
+	Query     string `json:"query"`
+	Title     string `json:"title"`
+	Body      string `json:"body"`
+	Tag       string `json:"tag"`
+	Status    string `json:"status"`
+	Priority  string `json:"priority"`
+	Due       string `json:"due"`
+	Scheduled string `json:"scheduled"`
+}
+
+func stringProperty(description string, values ...string) map[string]any {
+	p := map[string]any{"type": "string", "description": description}
+	if len(values) > 0 {
+		p["enum"] = values
+	}
+	return p
+}
+
+var (
+	typeProperty     = stringProperty("Item type", "task", "note")
+	idProperty       = stringProperty("Item ID, as listed")
+	statusProperty   = stringProperty("Task status", "todo", "in-progress", "done", "cancelled")
+	priorityProperty = stringProperty("Task priority", "highest", "high", "medium", "low", "lowest")
+	filterProperties = map[string]any{
+		"type":     stringProperty("Only items of this type", "task", "note"),
+		"status":   stringProperty("Only tasks with these statuses, comma-separated, e.g. todo,in-progress"),
+		"tag":      stringProperty("Only items with these tags, comma-separated"),
+		"priority": stringProperty("Only tasks with these priorities, comma-separated"),
+	}
+)
+
+func (s *Server) allTools() []tool {
+	searchProperties := map[string]any{"query": stringProperty("Text to find in the title or body, case-insensitive")}
+	for k, v := range filterProperties {
+		searchProperties[k] = v
+	}
+
+	return []tool{
+		{
+			name:        "list_items",
+			description: "Lists tasks and notes, optionally filtered by type, status, tag and priority.",
+			properties:  filterProperties,
+			call:        s.listItems,
+		},
+		{
+			name:        "search_items",
+			description: "Finds the tasks and notes containing a text in their title or body.",
+			properties:  searchProperties,
+			required:    []string{"query"},
+			call:        s.listItems,
+		},
+		{
+			name:        "get_item",
+			description: "Returns a task or note with its body.",
+			properties:  map[string]any{"type": typeProperty, "id": idProperty},
+			required:    []string{"type", "id"},
+			call:        s.getItem,
+		},
+		{
+			name:        "create_item",
+			description: "Creates a task (by default) or a note.",
+			properties: map[string]any{
+				"type":      typeProperty,
+				"title":     stringProperty("Title"),
+				"body":      stringProperty("Body, in Markdown"),
+				"tag":       stringProperty("Tag name"),
+				"status":    statusProperty,
+				"priority":  priorityProperty,
+				"due":       stringProperty("Due date of a task, YYYY-MM-DD"),
+				"scheduled": stringProperty("Date to work on a task, YYYY-MM-DD"),
+			},
+			required: []string{"title"},
+			write:    true,
+			call:     s.createItem,
+		},
+		{
+			name:        "update_status",
+			description: "Sets the status of a task.",
+			properties:  map[string]any{"id": idProperty, "status": statusProperty},
+			required:    []string{"id", "status"},
+			write:       true,
+			call:        s.updateStatus,
+		},
+		{
+			name:        "set_tag",
+			description: "Sets the tag of a task or note, or removes it when the tag is empty.",
+			properties:  map[string]any{"type": typeProperty, "id": idProperty, "tag": stringProperty("Tag name, empty to remove the tag")},
+			required:    []string{"type", "id", "tag"},
+			write:       true,
+			call:        s.setTag,
+		},
+	}
+}
+
+func (s *Server) listTools() any {
+	defs := make([]map[string]any, len(s.tools))
+	for i, t := range s.tools {
+		schema := map[string]any{"type": "object", "properties": t.properties}
+		if len(t.required) > 0 {
+			schema["required"] = t.required
+		}
+		defs[i] = map[string]any{
+			"name":        t.name,
+			"description": t.description,
+			"inputSchema": schema,
+			"annotations": map[string]any{"readOnlyHint": !t.write, "destructiveHint": false},
+		}
+	}
+	return map[string]any{"tools": defs}
+}
+
+// callTool runs a tool. Its failures are reported in the result, for the assistant to see.
+func (s *Server) callTool(params json.RawMessage) (any, error) {
+	var p struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := unmarshalParams(params, &p); err != nil {
+		return nil, err
+	}
+	var found *tool
+	for i := range s.tools {
+		if s.tools[i].name == p.Name {
+			found = &s.tools[i]
+		}
+	}
+	if found == nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "unknown tool: " + p.Name}
+	}
+
+	var args toolArgs
+	if err := unmarshalParams(p.Arguments, &args); err != nil {
+		return nil, err
+	}
+	result, err := found.call(args)
+	if err != nil {
+		return toolResult(err.Error(), true), nil
+	}
+	text, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return toolResult(string(text), false), nil
+}
+
+func toolResult(text string, isError bool) map[string]any {
+	return map[string]any{
+		"content": []map[string]any{{"type": "text", "text": text}},
+		"isError": isError,
+	}
+}
+
+func (s *Server) listItems(args toolArgs) (any, error) {
+	all, err := s.service.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	filter := itemjson.Filter{Type: args.Type, Status: args.Status, Tag: args.Tag, Priority: args.Priority, Text: args.Query}
+	return filter.Records(all), nil
+}
+
+func (s *Server) getItem(args toolArgs) (any, error) {
+	i, err := s.item(args.Type, args.Id)
+	if err != nil {
+		return nil, err
+	}
+	return itemjson.RecordFrom(i), nil
+}
+
+func (s *Server) createItem(args toolArgs) (any, error) {
+	msg, err := itemjson.Input{
+		Type:      args.Type,
+		Title:     args.Title,
+		Body:      args.Body,
+		Tag:       args.Tag,
+		Status:    args.Status,
+		Priority:  args.Priority,
+		Due:       args.Due,
+		Scheduled: args.Scheduled,
+	}.EditorMsg()
+	if err != nil {
+		return nil, err
+	}
+
+	var created items.ItemInterface
+	if msg.ItemType == items.ItemTypeNote {
+		n := items.Note{Item: items.Item{Title: msg.Title, Body: msg.Body}}
+		err, created = s.service.CreateNote(&n, msg.Tag), &n
+	} else {
+		t := items.Task{
+			Item:          items.Item{Title: msg.Title, Body: msg.Body},
+			Status:        items.ParseStatus(msg.Status),
+			Priority:      msg.Priority,
+			DueDate:       msg.Due,
+			ScheduledDate: msg.Scheduled,
+		}
+		err, created = s.service.CreateTask(&t, msg.Tag), &t
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.reload(created)
+}
+
+func (s *Server) updateStatus(args toolArgs) (any, error) {
+	i, err := s.item(string(items.ItemTypeTask), args.Id)
+	if err != nil {
+		return nil, err
+	}
+	status, err := itemjson.ParseStatus(args.Status)
+	if err != nil {
+		return nil, err
+	}
+	// UpdateStatus toggles back to todo when setting the current status
+	if t := i.(*items.Task); t.Status != status {
+		if err := s.service.UpdateStatus(t, status); err != nil {
+			return nil, err
+		}
+	}
+	return s.reload(i)
+}
+
+func (s *Server) setTag(args toolArgs) (any, error) {
+	i, err := s.item(args.Type, args.Id)
+	if err != nil {
+		return nil, err
+	}
+	if name := strings.TrimSpace(args.Tag); name != "" {
+		err = s.service.SetTag(i, name)
+	} else {
+		err = s.service.UnsetTag(i)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.reload(i)
+}
+
+// item returns the item of a type and ID given as arguments.
+func (s *Server) item(itemType, id string) (items.ItemInterface, error) {
+	t := items.ParseItemType(itemType)
+	if t == "" {
+		return nil, fmt.Errorf("invalid type %q, expected task or note", itemType)
+	}
+	if id == "" {
+		return nil, errors.New("id is required")
+	}
+	i, err := s.service.GetItem(t, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", t, id, err)
+	}
+	return i, nil
+}
+
+func (s *Server) reload(i items.ItemInterface) (any, error) {
+	stored, err := s.service.Reload(i)
+	if err != nil {
+		return nil, err
+	}
+	return itemjson.RecordFrom(stored), nil
+}
+
+// board renders all items as Markdown: tasks by status, then notes.
+func (s *Server) board() (string, error) {
+	all, err := s.service.GetAll()
+	if err != nil {
+		return "", err
+	}
+
+	sections := []struct {
+		title  string
+		status items.Status
+	}{
+		{"In progress", items.InProgress},
+		{"To do", items.Todo},
+		{"Done", items.Done},
+		{"Cancelled", items.Cancelled},
+		{"Notes", items.NoteType},
+	}
+
+	var b strings.Builder
+	b.WriteString("# Board\n")
+	for _, section := range sections {
+		fmt.Fprintf(&b, "\n## %s\n\n", section.title)
+		empty := true
+		for _, i := range all {
+			rec := itemjson.RecordFrom(i)
+			status := items.Status(rec.Status)
+			if rec.Type == string(items.ItemTypeNote) {
+				status = items.NoteType
+			}
+			if status != section.status {
+				continue
+			}
+			empty = false
+			fmt.Fprintf(&b, "- %s (%s `%s`", rec.Title, rec.Type, rec.Id)
+			if rec.Tag != "" {
+				fmt.Fprintf(&b, ", @%s", rec.Tag)
+			}
+			if rec.Priority != "" {
+				fmt.Fprintf(&b, ", %s priority", rec.Priority)
+			}
+			if rec.Due != "" {
+				fmt.Fprintf(&b, ", due %s", rec.Due)
+			}
+			b.WriteString(")\n")
+		}
+		if empty {
+			b.WriteString("None\n")
+		}
+	}
+	return b.String(), nil
+}
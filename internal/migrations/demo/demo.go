@@ -0,0 +1,88 @@
+// Package demo holds the sample items used by the --demo flag.
+package demo
+
+import (
+	_ "embed"
+	"encoding/json"
+
+	"github.com/markelca/prioritty/pkg/items"
+	"github.com/markelca/prioritty/pkg/items/repository"
+)
+
+//go:embed demo_data.json
+var demoDataJSON []byte
+
+// Data represents the structure of demo_data.json
+type Data struct {
+	Tasks []Task `json:"tasks"`
+	Notes []Note `json:"notes"`
+}
+
+type Task struct {
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+	Status string `json:"status"`
+	Tag    string `json:"tag"`
+}
+
+type Note struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	Tag   string `json:"tag"`
+}
+
+// Load returns the demo items.
+func Load() (Data, error) {
+	var data Data
+	err := json.Unmarshal(demoDataJSON, &data)
+	return data, err
+}
+
+// Seed creates the demo items through the repository.
+func Seed(repo repository.Repository) error {
+	data, err := Load()
+	if err != nil {
+		return err
+	}
+
+	for _, t := range data.Tasks {
+		task := items.Task{
+			Item:   items.Item{Title: t.Title, Body: t.Body},
+			Status: items.ParseStatus(t.Status),
+		}
+		if err := repo.CreateTask(&task); err != nil {
+			return err
+		}
+		if t.Tag == "" {
+			continue
+		}
+		tag, err := repository.GetOrCreateTag(repo, t.Tag)
+		if err != nil {
+			return err
+		}
+		if err := repo.SetTaskTag(task, *tag); err != nil {
+			return err
+		}
+	}
+
+	for _, n := range data.Notes {
+		note := items.Note{
+			Item: items.Item{Title: n.Title, Body: n.Body},
+		}
+		if err := repo.CreateNote(&note); err != nil {
+			return err
+		}
+		if n.Tag == "" {
+			continue
+		}
+		tag, err := repository.GetOrCreateTag(repo, n.Tag)
+		if err != nil {
+			return err
+		}
+		if err := repo.SetNoteTag(note, *tag); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/markelca/prioritty/internal/config"
+	"github.com/markelca/prioritty/internal/migrations/demo"
 	"github.com/markelca/prioritty/pkg/items"
 	"github.com/markelca/prioritty/pkg/items/repository/obsidian"
 	"github.com/markelca/prioritty/pkg/markdown"
@@ -16,42 +18,29 @@ import (
 //go:embed all_items.base.yaml
 var allItemsBaseContent string
 
-//go:embed demo_data.json
-var demoDataJSON []byte
-
 // TypesJSON represents the Obsidian types.json structure
 type TypesJSON struct {
 	Types map[string]string `json:"types"`
 }
 
-// DemoData represents the structure of demo_data.json
-type DemoData struct {
-	Tasks []DemoTask `json:"tasks"`
-	Notes []DemoNote `json:"notes"`
-}
-
-type DemoTask struct {
-	Title  string `json:"title"`
-	Body   string `json:"body"`
-	Status string `json:"status"`
-	Tag    string `json:"tag"`
-}
-
-type DemoNote struct {
-	Title string `json:"title"`
-	Body  string `json:"body"`
-	Tag   string `json:"tag"`
-}
-
 // defaultTypes returns the default property types for Prioritty
 func defaultTypes() TypesJSON {
 	return TypesJSON{
 		Types: map[string]string{
-			"title":      "text",
-			"type":       "text",
-			"status":     "text",
-			"tag":        "text",
-			"created_at": "datetime",
+			"title":          "text",
+			"type":           "text",
+			"status":         "text",
+			"tag":            "text",
+			"priority":       "text",
+			"due":            "date",
+			"scheduled":      "date",
+			"recurrence":     "text",
+			"estimate":       "text",
+			"created_at":     "datetime",
+			"uid":            "text",
+			"completed_at":   "datetime",
+			"status_history": "multitext",
+			"time_log":       "multitext",
 		},
 	}
 }
@@ -81,7 +70,16 @@ func NewObsidianRepository(vaultPath string) (*obsidian.ObsidianRepository, erro
 		return nil, err
 	}
 
-	repo := obsidian.NewObsidianRepository(vaultPath)
+	repo := obsidian.NewObsidianRepository(vaultPath,
+		obsidian.WithInlineTasks(viper.GetBool(config.CONF_OBSIDIAN_INLINE_TASKS)),
+		obsidian.WithGitHistory(viper.GetBool(config.CONF_OBSIDIAN_GIT_HISTORY)),
+	)
+
+	if viper.GetBool(config.CONF_OBSIDIAN_GIT_HISTORY) {
+		if err := repo.CheckGitRepository(); err != nil {
+			return nil, err
+		}
+	}
 
 	// Seed demo data if demo mode
 	if viper.GetBool("demo") {
@@ -148,9 +146,9 @@ func seedDemoData(repo *obsidian.ObsidianRepository) error {
 		}
 	}
 
-	// Load demo data from embedded JSON
-	var demoData DemoData
-	if err := json.Unmarshal(demoDataJSON, &demoData); err != nil {
+	// Load the demo items
+	demoData, err := demo.Load()
+	if err != nil {
 		return err
 	}
 
@@ -2,9 +2,11 @@ package sqlite
 
 import (
 	"database/sql"
-	_ "embed"
+	"embed"
+	"fmt"
 	"log"
-	"os"
+	"path"
+	"sort"
 
 	_ "github.com/mattn/go-sqlite3"
 
@@ -18,32 +20,108 @@ var SchemaSQL string
 //go:embed sql/seed.sql
 var SeedSQL string
 
+// migrationsFS holds the schema changes applied on top of schema.sql, in filename order.
+// The number of applied migrations is tracked with PRAGMA user_version.
+//
+//go:embed sql/migrations/*.sql
+var migrationsFS embed.FS
+
+// connectionParams enables WAL so readers don't block the writer, and makes
+// concurrent pt processes wait for each other's locks instead of failing with SQLITE_BUSY.
+// Transactions take the write lock upfront so schema initialization is serialized.
+const connectionParams = "?_busy_timeout=5000&_journal_mode=WAL&_txlock=immediate"
+
 func NewSQLiteRepository(dbPath string) (*sqlite.SQLiteRepository, error) {
-	dbExists := false
-	if _, err := os.Stat(dbPath); err == nil {
-		dbExists = true
+	db, err := sql.Open("sqlite3", dbPath+connectionParams)
+	if err != nil {
+		return nil, err
 	}
 
-	db, err := sql.Open("sqlite3", dbPath)
-	if err != nil {
+	if err := initSchema(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	if err := migrate(db); err != nil {
+		db.Close()
 		return nil, err
 	}
 
-	repo := sqlite.NewSQLiteRepository(db, dbPath)
+	return sqlite.NewSQLiteRepository(db, dbPath), nil
+}
+
+// initSchema creates the schema (and demo data) if the database is empty.
+// It runs in a write transaction so two processes opening a new database at
+// the same time don't both try to create it.
+func initSchema(db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var tables int
+	row := tx.QueryRow(`SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = 'task'`)
+	if err := row.Scan(&tables); err != nil {
+		return err
+	}
+	if tables > 0 {
+		return nil
+	}
+
+	if _, err := tx.Exec(SchemaSQL); err != nil {
+		log.Printf("Error executing schema: %v", err)
+		return err
+	}
+	if viper.GetBool("demo") {
+		if _, err := tx.Exec(SeedSQL); err != nil {
+			log.Printf("Error executing seed data: %v", err)
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// migrate applies the pending migrations in a single write transaction.
+func migrate(db *sql.DB) error {
+	entries, err := migrationsFS.ReadDir("sql/migrations")
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
 
-	if !dbExists {
-		if _, err := db.Exec(SchemaSQL); err != nil {
-			log.Printf("Error executing schema: %v", err)
-			db.Close()
-			return nil, err
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var version int
+	if err := tx.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		return err
+	}
+	if version >= len(names) {
+		return nil
+	}
+
+	for i := version; i < len(names); i++ {
+		migration, err := migrationsFS.ReadFile(path.Join("sql/migrations", names[i]))
+		if err != nil {
+			return err
 		}
-		if viper.GetBool("demo") {
-			if _, err := db.Exec(SeedSQL); err != nil {
-				db.Close()
-				log.Printf("Error executing seed data: %v", err)
-				return nil, err
-			}
+		if _, err := tx.Exec(string(migration)); err != nil {
+			log.Printf("Error executing migration %s: %v", names[i], err)
+			return fmt.Errorf("migration %s failed: %w", names[i], err)
 		}
 	}
-	return repo, nil
+
+	// PRAGMA doesn't support placeholders
+	if _, err := tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, len(names))); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
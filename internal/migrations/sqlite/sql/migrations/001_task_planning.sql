@@ -0,0 +1,4 @@
+ALTER TABLE task ADD COLUMN priority TEXT NOT NULL DEFAULT '';
+ALTER TABLE task ADD COLUMN due_date TEXT;
+ALTER TABLE task ADD COLUMN scheduled_date TEXT;
+ALTER TABLE task ADD COLUMN recurrence TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+ALTER TABLE task ADD COLUMN uid TEXT;
+ALTER TABLE note ADD COLUMN uid TEXT;
@@ -0,0 +1,2 @@
+ALTER TABLE task ADD COLUMN updated_at TEXT;
+ALTER TABLE note ADD COLUMN updated_at TEXT;
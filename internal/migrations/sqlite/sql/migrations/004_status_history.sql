@@ -0,0 +1,13 @@
+ALTER TABLE task ADD COLUMN completed_at TEXT;
+-- Best guess for the tasks completed before completion times were recorded
+UPDATE task SET completed_at = COALESCE(updated_at, created_at) WHERE status_id = 2;
+
+CREATE TABLE task_status_history (
+   id INTEGER PRIMARY KEY,
+   task_id INTEGER NOT NULL,
+   status_id INTEGER NOT NULL,
+   entered_at TEXT NOT NULL,
+   FOREIGN KEY (task_id) REFERENCES task(id) ON DELETE CASCADE
+   FOREIGN KEY (status_id) REFERENCES status(id)
+);
+CREATE INDEX task_status_history_task_id ON task_status_history (task_id);
@@ -0,0 +1,8 @@
+CREATE TABLE task_time_entry (
+   id INTEGER PRIMARY KEY,
+   task_id INTEGER NOT NULL,
+   started_at TEXT NOT NULL,
+   ended_at TEXT, -- NULL while the timer is running
+   FOREIGN KEY (task_id) REFERENCES task(id) ON DELETE CASCADE
+);
+CREATE INDEX task_time_entry_task_id ON task_time_entry (task_id);
@@ -0,0 +1 @@
+ALTER TABLE task_time_entry ADD COLUMN pomodoro INTEGER NOT NULL DEFAULT 0;
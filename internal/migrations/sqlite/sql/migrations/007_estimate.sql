@@ -0,0 +1 @@
+ALTER TABLE task ADD COLUMN estimate TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,30 @@
+package todotxt
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/markelca/prioritty/internal/migrations/demo"
+	"github.com/markelca/prioritty/pkg/items/repository/todotxt"
+	"github.com/spf13/viper"
+)
+
+// NewTodoTxtRepository creates a todo.txt repository, making sure its directory exists.
+// In demo mode a missing file is seeded with the demo items.
+func NewTodoTxtRepository(path string) (*todotxt.TodoTxtRepository, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	repo := todotxt.NewTodoTxtRepository(path)
+
+	if viper.GetBool("demo") {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			if err := demo.Seed(repo); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return repo, nil
+}
@@ -0,0 +1,55 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/markelca/prioritty/internal/service"
+	"github.com/markelca/prioritty/pkg/items/repository"
+)
+
+const commandTimeout = 30 * time.Second
+
+// command runs a program for every event, with the JSON payload on stdin and the
+// event type in the PRIORITTY_EVENT environment variable. It isn't retried.
+type command struct {
+	path string
+	args []string
+}
+
+func newCommand(path string, args []string) *command {
+	return &command{path: repository.ExpandTilde(path), args: args}
+}
+
+func (c *command) deliver(e service.Event) error {
+	body, err := PayloadFrom(e)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, c.path, c.args...)
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Env = append(os.Environ(), "PRIORITTY_EVENT="+string(e.Type))
+	var output bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &output, &output
+
+	err = cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s", commandTimeout)
+	}
+	if err != nil {
+		if out := strings.TrimSpace(output.String()); out != "" {
+			return fmt.Errorf("%w: %s", err, out)
+		}
+		return err
+	}
+	return nil
+}
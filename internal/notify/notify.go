@@ -0,0 +1,153 @@
+// Package notify delivers the events of the service to the outside world: POSTed as
+// JSON to webhooks (e.g. Slack) or written to the stdin of local commands. Events are
+// delivered in the background, one at a time per sink and in order; failures are
+// logged and never undo or fail the change that caused them.
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/markelca/prioritty/internal/config"
+	"github.com/markelca/prioritty/internal/itemjson"
+	"github.com/markelca/prioritty/internal/service"
+)
+
+// queueSize is the number of events a sink can fall behind before new ones are dropped.
+const queueSize = 100
+
+// ExitTimeout is how long the program waits on exit for the pending events.
+const ExitTimeout = 2 * time.Second
+
+// pending counts the events queued or being delivered by every sink.
+var pending sync.WaitGroup
+
+// longRunning is set by LongRunning.
+var longRunning bool
+
+// LongRunning makes the webhooks created afterwards retry failed deliveries, for the
+// processes that keep running (the TUI, pt serve, pt mcp). Otherwise they're tried
+// once, briefly, so that one-shot commands don't keep the user waiting on exit.
+func LongRunning() {
+	longRunning = true
+}
+
+// Payload is the JSON sent for an event.
+type Payload struct {
+	Event          string          `json:"event"`
+	Time           string          `json:"time"`
+	Item           itemjson.Record `json:"item"`
+	PreviousStatus string          `json:"previous_status,omitempty"`
+	PreviousTag    string          `json:"previous_tag,omitempty"`
+}
+
+// PayloadFrom returns the JSON payload of an event.
+func PayloadFrom(e service.Event) ([]byte, error) {
+	return json.Marshal(Payload{
+		Event:          string(e.Type),
+		Time:           e.Time.UTC().Format(time.RFC3339),
+		Item:           itemjson.RecordFrom(e.Item),
+		PreviousStatus: string(e.PreviousStatus),
+		PreviousTag:    e.PreviousTag,
+	})
+}
+
+// New returns the sinks of the configuration, failing if any is invalid.
+func New(sinks []config.NotifySink) ([]service.Sink, error) {
+	var result []service.Sink
+	for i, cfg := range sinks {
+		events, err := parseEvents(cfg.Events)
+		if err != nil {
+			return nil, fmt.Errorf("notify #%d: %w", i+1, err)
+		}
+		switch {
+		case cfg.URL != "" && cfg.Command != "":
+			return nil, fmt.Errorf("notify #%d: set either url or command, not both", i+1)
+		case cfg.URL != "":
+			u, err := url.Parse(cfg.URL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return nil, fmt.Errorf("notify #%d: invalid url %q", i+1, cfg.URL)
+			}
+			result = append(result, newQueue(u.Redacted(), events, newWebhook(cfg.URL).deliver))
+		case cfg.Command != "":
+			result = append(result, newQueue(cfg.Command, events, newCommand(cfg.Command, cfg.Args).deliver))
+		default:
+			return nil, fmt.Errorf("notify #%d: url or command is required", i+1)
+		}
+	}
+	return result, nil
+}
+
+// parseEvents returns the set of event types to deliver, nil for all.
+func parseEvents(names []string) (map[service.EventType]bool, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	events := map[service.EventType]bool{}
+	for _, name := range names {
+		t := service.EventType(name)
+		if !slices.Contains(service.EventTypes, t) {
+			return nil, fmt.Errorf("unknown event %q", name)
+		}
+		events[t] = true
+	}
+	return events, nil
+}
+
+// Wait waits for the queued events to be delivered, at most timeout, so they aren't
+// lost when the program exits. It reports whether they were.
+func Wait(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		log.Printf("Error - Gave up delivering the pending events after %s", timeout)
+		return false
+	}
+}
+
+// queue is a sink delivering the events it's interested in, in the background.
+type queue struct {
+	name    string
+	events  map[service.EventType]bool // nil for all
+	ch      chan service.Event
+	deliver func(service.Event) error
+}
+
+func newQueue(name string, events map[service.EventType]bool, deliver func(service.Event) error) *queue {
+	q := &queue{name: name, events: events, ch: make(chan service.Event, queueSize), deliver: deliver}
+	go q.run()
+	return q
+}
+
+func (q *queue) Notify(e service.Event) {
+	if q.events != nil && !q.events[e.Type] {
+		return
+	}
+	pending.Add(1)
+	select {
+	case q.ch <- e:
+	default:
+		pending.Done()
+		log.Printf("Error notifying %s of %s: too many events pending, dropped", q.name, e.Type)
+	}
+}
+
+func (q *queue) run() {
+	for e := range q.ch {
+		if err := q.deliver(e); err != nil {
+			log.Printf("Error notifying %s of %s: %v", q.name, e.Type, err)
+		}
+		pending.Done()
+	}
+}
@@ -0,0 +1,81 @@
+package notify
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/markelca/prioritty/internal/service"
+)
+
+const (
+	webhookTimeout  = 10 * time.Second
+	webhookAttempts = 4
+	webhookBackoff  = time.Second // doubled after each failed attempt
+
+	// The single attempt of one-shot commands, within ExitTimeout
+	oneShotTimeout = 1500 * time.Millisecond
+)
+
+// errPermanent marks the delivery errors retrying won't fix.
+var errPermanent = errors.New("not retried")
+
+// webhook POSTs the events as JSON to a URL. In long-running processes, it retries
+// on network errors and on 429 and 5xx responses.
+type webhook struct {
+	url      string
+	client   *http.Client
+	attempts int
+}
+
+func newWebhook(url string) *webhook {
+	if !longRunning {
+		return &webhook{url: url, client: &http.Client{Timeout: oneShotTimeout}, attempts: 1}
+	}
+	return &webhook{url: url, client: &http.Client{Timeout: webhookTimeout}, attempts: webhookAttempts}
+}
+
+func (w *webhook) deliver(e service.Event) error {
+	body, err := PayloadFrom(e)
+	if err != nil {
+		return err
+	}
+	backoff := webhookBackoff
+	for attempt := 1; ; attempt++ {
+		err = w.post(body, e.Type)
+		if err == nil || errors.Is(err, errPermanent) || attempt == w.attempts {
+			return err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+func (w *webhook) post(body []byte, eventType service.EventType) error {
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: %v", errPermanent, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "prioritty")
+	req.Header.Set("X-Prioritty-Event", string(eventType))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	switch {
+	case resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("%s", resp.Status)
+	default:
+		return fmt.Errorf("%w: %s", errPermanent, resp.Status)
+	}
+}
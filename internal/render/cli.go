@@ -48,6 +48,12 @@ func (r CLI) renderTask(t items.Task) string {
 	}
 
 	title := style.Render(t.Title)
+	if !t.Estimate.IsZero() {
+		title += styles.Secondary.Render(" ~" + t.Estimate.String())
+	}
+	if t.TimerRunning() {
+		title += styles.TimerIcon
+	}
 
 	return icon + contentIcon + title + "\n"
 }
@@ -0,0 +1,259 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/markelca/prioritty/internal/itemjson"
+	"github.com/markelca/prioritty/pkg/items"
+)
+
+func (s *Server) listItems(w http.ResponseWriter, r *http.Request) {
+	all, err := s.service.GetAll()
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := itemjson.Filter{
+		Type:     query.Get("type"),
+		Status:   query.Get("status"),
+		Tag:      query.Get("tag"),
+		Priority: query.Get("priority"),
+		Text:     query.Get("q"),
+	}
+	writeJSON(w, http.StatusOK, filter.Records(all))
+}
+
+func (s *Server) getItem(w http.ResponseWriter, r *http.Request) {
+	i, ok := s.lookup(w, r)
+	if !ok {
+		return
+	}
+	rec := itemjson.RecordFrom(i)
+	if r.Header.Get("If-None-Match") == rec.ETag {
+		w.Header().Set("ETag", rec.ETag)
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	writeItem(w, http.StatusOK, i)
+}
+
+func (s *Server) createItem(w http.ResponseWriter, r *http.Request) {
+	var in itemjson.Input
+	if !decode(w, r, &in) {
+		return
+	}
+	msg, err := in.EditorMsg()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var created items.ItemInterface
+	if msg.ItemType == items.ItemTypeNote {
+		n := items.Note{Item: items.Item{Title: msg.Title, Body: msg.Body}}
+		err, created = s.service.CreateNote(&n, msg.Tag), &n
+	} else {
+		t := items.Task{
+			Item:          items.Item{Title: msg.Title, Body: msg.Body},
+			Status:        items.ParseStatus(msg.Status),
+			Priority:      msg.Priority,
+			DueDate:       msg.Due,
+			ScheduledDate: msg.Scheduled,
+			Recurrence:    msg.Recurrence,
+			Estimate:      msg.Estimate,
+		}
+		err, created = s.service.CreateTask(&t, msg.Tag), &t
+	}
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	stored, err := s.service.Reload(created)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.Header().Set("Location", itemPath(itemjson.RecordFrom(stored)))
+	writeItem(w, http.StatusCreated, stored)
+}
+
+func (s *Server) updateItem(w http.ResponseWriter, r *http.Request) {
+	i, ok := s.lookupForChange(w, r)
+	if !ok {
+		return
+	}
+	var in itemjson.Input
+	if !decode(w, r, &in) {
+		return
+	}
+	if in.Type == "" {
+		in.Type = itemjson.RecordFrom(i).Type
+	}
+	msg, err := in.EditorMsg()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if msg.ItemType != items.ItemType(itemjson.RecordFrom(i).Type) {
+		writeError(w, http.StatusBadRequest, errors.New("the type of an item can't be changed"))
+		return
+	}
+
+	msg.Id = i.GetId()
+	s.respondChanged(w, i, s.service.UpdateItemFromEditorMsg(i, msg))
+}
+
+func (s *Server) deleteItem(w http.ResponseWriter, r *http.Request) {
+	i, ok := s.lookupForChange(w, r)
+	if !ok {
+		return
+	}
+	if err := s.service.RemoveItem(i); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) setStatus(w http.ResponseWriter, r *http.Request) {
+	i, ok := s.lookupForChange(w, r)
+	if !ok {
+		return
+	}
+	var in struct {
+		Status string `json:"status"`
+	}
+	if !decode(w, r, &in) {
+		return
+	}
+	t, isTask := i.(*items.Task)
+	if !isTask {
+		writeError(w, http.StatusBadRequest, errors.New("only tasks have a status"))
+		return
+	}
+	status, err := itemjson.ParseStatus(in.Status)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// UpdateStatus toggles back to todo when setting the current status
+	if t.Status == status {
+		writeItem(w, http.StatusOK, t)
+		return
+	}
+	s.respondChanged(w, i, s.service.UpdateStatus(t, status))
+}
+
+func (s *Server) setTag(w http.ResponseWriter, r *http.Request) {
+	i, ok := s.lookupForChange(w, r)
+	if !ok {
+		return
+	}
+	var in struct {
+		Tag string `json:"tag"`
+	}
+	if !decode(w, r, &in) {
+		return
+	}
+	name := strings.TrimSpace(in.Tag)
+	if name == "" {
+		writeError(w, http.StatusBadRequest, errors.New("tag is required, use DELETE to remove it"))
+		return
+	}
+	s.respondChanged(w, i, s.service.SetTag(i, name))
+}
+
+func (s *Server) unsetTag(w http.ResponseWriter, r *http.Request) {
+	i, ok := s.lookupForChange(w, r)
+	if !ok {
+		return
+	}
+	s.respondChanged(w, i, s.service.UnsetTag(i))
+}
+
+func (s *Server) listTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := s.service.GetTags()
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	names := []string{}
+	for _, t := range tags {
+		names = append(names, t.Name)
+	}
+	writeJSON(w, http.StatusOK, names)
+}
+
+// itemKinds are the item types by their collection in the API paths.
+var itemKinds = map[string]items.ItemType{
+	"tasks": items.ItemTypeTask,
+	"notes": items.ItemTypeNote,
+}
+
+// itemPath returns the path of an item in the API.
+func itemPath(rec itemjson.Record) string {
+	return "/api/" + rec.Type + "s/" + url.PathEscape(rec.Id)
+}
+
+// lookup returns the item of the kind and ID in the path, answering with an error if there's none.
+func (s *Server) lookup(w http.ResponseWriter, r *http.Request) (items.ItemInterface, bool) {
+	itemType, ok := itemKinds[r.PathValue("kind")]
+	if !ok {
+		http.NotFound(w, r)
+		return nil, false
+	}
+	i, err := s.service.GetItem(itemType, r.PathValue("id"))
+	if err != nil {
+		writeServiceError(w, err)
+		return nil, false
+	}
+	return i, true
+}
+
+// lookupForChange returns the item of the ID in the path, checking the If-Match header
+// against its current ETag, if sent.
+func (s *Server) lookupForChange(w http.ResponseWriter, r *http.Request) (items.ItemInterface, bool) {
+	i, ok := s.lookup(w, r)
+	if !ok {
+		return nil, false
+	}
+	if match := r.Header.Get("If-Match"); match != "" && match != "*" {
+		current := itemjson.RecordFrom(i).ETag
+		for _, tag := range strings.Split(match, ",") {
+			if strings.TrimSpace(tag) == current {
+				return i, true
+			}
+		}
+		w.Header().Set("ETag", current)
+		writeError(w, http.StatusPreconditionFailed, errors.New("the item was modified, get it again"))
+		return nil, false
+	}
+	return i, true
+}
+
+// respondChanged answers with the item after a change, or the error of the change.
+func (s *Server) respondChanged(w http.ResponseWriter, i items.ItemInterface, err error) {
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	stored, err := s.service.Reload(i)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeItem(w, http.StatusOK, stored)
+}
+
+func writeItem(w http.ResponseWriter, status int, i items.ItemInterface) {
+	rec := itemjson.RecordFrom(i)
+	w.Header().Set("ETag", rec.ETag)
+	writeJSON(w, status, rec)
+}
@@ -0,0 +1,123 @@
+// Package server exposes the items of a repository over an HTTP/JSON API, for
+// dashboards and editor integrations.
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/markelca/prioritty/internal/service"
+	"github.com/markelca/prioritty/pkg/items/repository"
+)
+
+// Server handles the API requests with a service. Requests are handled one at a
+// time, so a change can't slip in between the ETag check and the write.
+type Server struct {
+	service service.Service
+	token   string
+	mu      sync.Mutex
+}
+
+// New returns a server for the service. When token is not empty, requests must
+// send it as "Authorization: Bearer <token>".
+func New(s service.Service, token string) *Server {
+	return &Server{service: s, token: token}
+}
+
+// Handler returns the HTTP handler of the API, where {kind} is tasks or notes:
+//
+//	GET    /api/items                 list items, filtered by ?type=, status=, tag=, priority= and q=
+//	POST   /api/items                 create an item
+//	GET    /api/{kind}/{id}           get an item
+//	PUT    /api/{kind}/{id}           replace the fields of an item
+//	DELETE /api/{kind}/{id}           delete an item
+//	PUT    /api/tasks/{id}/status     set the status of a task
+//	PUT    /api/{kind}/{id}/tag       set the tag of an item
+//	DELETE /api/{kind}/{id}/tag       remove the tag of an item
+//	GET    /api/tags                  list tags
+//
+// IDs must be escaped as a single path segment, e.g. "notes%2Ftodo.md%23L3".
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/items", s.listItems)
+	mux.HandleFunc("POST /api/items", s.createItem)
+	mux.HandleFunc("GET /api/tags", s.listTags)
+	mux.HandleFunc("GET /api/{kind}/{id}", s.getItem)
+	mux.HandleFunc("PUT /api/{kind}/{id}", s.updateItem)
+	mux.HandleFunc("DELETE /api/{kind}/{id}", s.deleteItem)
+	mux.HandleFunc("PUT /api/{kind}/{id}/status", s.setStatus)
+	mux.HandleFunc("PUT /api/{kind}/{id}/tag", s.setTag)
+	mux.HandleFunc("DELETE /api/{kind}/{id}/tag", s.unsetTag)
+	return s.authenticate(s.serialize(mux))
+}
+
+// authenticate rejects the requests without the token, if one is set.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.token != "" {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				writeError(w, http.StatusUnauthorized, errors.New("missing or invalid token"))
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) serialize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// errorResponse is the body of the error responses.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Error writing response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+// writeServiceError answers with the status matching an error of the service.
+func writeServiceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, repository.ErrConflict):
+		writeError(w, http.StatusConflict, err)
+	case errors.Is(err, service.ErrVetoed):
+		writeError(w, http.StatusUnprocessableEntity, err)
+	default:
+		log.Printf("Error handling request: %v", err)
+		writeError(w, http.StatusInternalServerError, err)
+	}
+}
+
+// decode reads the JSON body of a request, answering with an error if it's invalid.
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return false
+	}
+	return true
+}
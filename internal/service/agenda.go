@@ -0,0 +1,105 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	"github.com/markelca/prioritty/pkg/items"
+)
+
+// Agenda is the work of a day: the open tasks that are late, due, scheduled or in
+// progress, and those coming up in the next days. Each task is listed once in the
+// sections of today, in the first that applies.
+type Agenda struct {
+	Date           time.Time    // today, at midnight local time
+	Overdue        []items.Task // due before today
+	DueToday       []items.Task
+	ScheduledToday []items.Task // scheduled today or before, not done yet
+	InProgress     []items.Task // in progress, without a date for today
+	Upcoming       []AgendaDay  // the next days, from tomorrow
+}
+
+// AgendaDay lists the open tasks due or scheduled on a day.
+type AgendaDay struct {
+	Date  time.Time
+	Tasks []items.Task
+}
+
+// Len returns the number of tasks listed for today.
+func (a Agenda) Len() int {
+	return len(a.Overdue) + len(a.DueToday) + len(a.ScheduledToday) + len(a.InProgress)
+}
+
+// GetAgenda computes the agenda of today, with the given number of upcoming days.
+func (s Service) GetAgenda(days int) (Agenda, error) {
+	tasks, err := s.GetTasks()
+	if err != nil {
+		return Agenda{}, err
+	}
+	return ComputeAgenda(tasks, time.Now(), days), nil
+}
+
+// ComputeAgenda computes the agenda of the day of now, with the given number of
+// upcoming days. Done and cancelled tasks are left out.
+func ComputeAgenda(tasks []items.Task, now time.Time, days int) Agenda {
+	today := startOfDay(now)
+	agenda := Agenda{Date: today}
+	for i := 1; i <= days; i++ {
+		agenda.Upcoming = append(agenda.Upcoming, AgendaDay{Date: today.AddDate(0, 0, i)})
+	}
+
+	for _, t := range tasks {
+		if t.Status != items.Todo && t.Status != items.InProgress {
+			continue
+		}
+		due, scheduled := dateOf(t.DueDate), dateOf(t.ScheduledDate)
+		switch {
+		case !due.IsZero() && due.Before(today):
+			agenda.Overdue = append(agenda.Overdue, t)
+		case due.Equal(today):
+			agenda.DueToday = append(agenda.DueToday, t)
+		case !scheduled.IsZero() && !scheduled.After(today):
+			agenda.ScheduledToday = append(agenda.ScheduledToday, t)
+		case t.Status == items.InProgress:
+			agenda.InProgress = append(agenda.InProgress, t)
+		}
+
+		// Listed on the days it's scheduled and due
+		for i, day := range agenda.Upcoming {
+			if due.Equal(day.Date) || scheduled.Equal(day.Date) {
+				agenda.Upcoming[i].Tasks = append(agenda.Upcoming[i].Tasks, t)
+			}
+		}
+	}
+
+	for _, section := range []*[]items.Task{&agenda.Overdue, &agenda.DueToday, &agenda.ScheduledToday, &agenda.InProgress} {
+		sortAgendaTasks(*section)
+	}
+	for i := range agenda.Upcoming {
+		sortAgendaTasks(agenda.Upcoming[i].Tasks)
+	}
+	return agenda
+}
+
+// dateOf returns the day of a date field, zero if it isn't set.
+func dateOf(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return startOfDay(t)
+}
+
+// sortAgendaTasks sorts tasks by priority, then by due date (tasks without one last)
+// and title.
+func sortAgendaTasks(tasks []items.Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		a, b := tasks[i], tasks[j]
+		if a.Priority.Rank() != b.Priority.Rank() {
+			return a.Priority.Rank() > b.Priority.Rank()
+		}
+		if !a.DueDate.Equal(b.DueDate) {
+			return !a.DueDate.IsZero() && (b.DueDate.IsZero() || a.DueDate.Before(b.DueDate))
+		}
+		return a.Title < b.Title
+	})
+}
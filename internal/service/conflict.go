@@ -0,0 +1,73 @@
+package service
+
+import (
+	"github.com/markelca/prioritty/pkg/items"
+	"github.com/markelca/prioritty/pkg/items/repository"
+)
+
+// checkUnchanged compares the item as it was loaded with the version currently stored.
+// It returns repository.ErrConflict if the stored item was modified or removed since,
+// so callers don't silently overwrite changes made by another process.
+func checkUnchanged(r repository.Repository, i items.ItemInterface) error {
+	_, err := loadUnchanged(r, i)
+	return err
+}
+
+// loadUnchanged is checkUnchanged returning the stored version of the item, whose
+// fields left out of the comparison (e.g. the time log of tasks) may be newer.
+// Changes must still be made through expecting, which repeats the check atomically.
+func loadUnchanged(r repository.Repository, i items.ItemInterface) (items.ItemInterface, error) {
+	current, err := findStored(r, i)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil || !items.SameContent(i, current) {
+		return nil, repository.ErrConflict
+	}
+	return current, nil
+}
+
+// expecting returns a copy of the service whose repository changes fail with
+// repository.ErrConflict unless i, as it was loaded, is still the stored version. The
+// repository checks it holding the lock or in the transaction of each change, so no
+// other process can modify the item in between. Only the first change of i can be
+// made through it, the next ones would find the item changed.
+func (s Service) expecting(i items.ItemInterface) Service {
+	r := repository.Expect(s.repository, i)
+	s.repository, s.TaskService.repository, s.NoteService.repository = r, r, r
+	return s
+}
+
+// findStored returns the stored version of the item, or nil if it no longer exists.
+func findStored(r repository.Repository, i items.ItemInterface) (items.ItemInterface, error) {
+	switch i.(type) {
+	case *items.Task:
+		tasks, err := r.GetTasks()
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tasks {
+			if t.Id == i.GetId() {
+				return &t, nil
+			}
+		}
+	case *items.Note:
+		notes, err := r.GetNotes()
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range notes {
+			if n.Id == i.GetId() {
+				return &n, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+func tagName(i items.ItemInterface) string {
+	if tag := i.GetTag(); tag != nil {
+		return tag.Name
+	}
+	return ""
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/markelca/prioritty/pkg/items"
+)
+
+// EventType identifies what changed in an Event.
+type EventType string
+
+const (
+	EventItemCreated       EventType = "item.created"
+	EventItemUpdated       EventType = "item.updated"
+	EventItemDeleted       EventType = "item.deleted"
+	EventTaskStatusChanged EventType = "task.status_changed"
+	EventTagSet            EventType = "tag.set"
+	EventTagUnset          EventType = "tag.unset"
+)
+
+// EventTypes are all the types of events, in the order they're documented.
+var EventTypes = []EventType{
+	EventItemCreated, EventItemUpdated, EventItemDeleted,
+	EventTaskStatusChanged, EventTagSet, EventTagUnset,
+}
+
+// Event is a change made through the service, published once it's stored.
+type Event struct {
+	Type EventType
+	Time time.Time
+	Item items.ItemInterface // the item after the change, before it for deletions
+
+	// Set for task.status_changed, and for tag.set and tag.unset
+	PreviousStatus items.Status
+	PreviousTag    string
+}
+
+// Sink receives the events of a service. Notify is called while the change is being
+// made, so it must not block: slow deliveries (e.g. HTTP requests) happen in the
+// background, and failures are the sink's to report.
+type Sink interface {
+	Notify(Event)
+}
+
+// Bus publishes the events of a service to its sinks. The zero value has no sinks.
+type Bus struct {
+	mu    sync.Mutex
+	sinks []Sink
+}
+
+// Subscribe adds a sink receiving every event published from now on.
+func (b *Bus) Subscribe(sink Sink) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sinks = append(b.sinks, sink)
+}
+
+// publish notifies the sinks of an event about a copy of the item, so later changes
+// to it aren't seen by sinks delivering in the background.
+func (b *Bus) publish(e Event) {
+	b.mu.Lock()
+	sinks := b.sinks
+	b.mu.Unlock()
+	if len(sinks) == 0 {
+		return
+	}
+
+	e.Time = time.Now().UTC()
+	switch v := e.Item.(type) {
+	case *items.Task:
+		t := *v
+		e.Item = &t
+	case *items.Note:
+		n := *v
+		e.Item = &n
+	}
+	for _, sink := range sinks {
+		sink.Notify(e)
+	}
+}
+
+// Subscribe adds a sink receiving the events of the changes made through the service.
+func (s Service) Subscribe(sink Sink) {
+	s.events.Subscribe(sink)
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/markelca/prioritty/pkg/items"
+	"github.com/markelca/prioritty/pkg/items/repository"
+)
+
+// ErrHistoryNotSupported is returned by History and Revert for repositories that
+// don't keep past versions of items.
+var ErrHistoryNotSupported = errors.New("item history is only available for Obsidian vaults in a git repository")
+
+// History lists the past versions of an item, newest first.
+func (s Service) History(i items.ItemInterface) ([]repository.Revision, error) {
+	h, ok := s.repository.(repository.History)
+	if !ok {
+		return nil, ErrHistoryNotSupported
+	}
+	return h.ItemHistory(i.GetId())
+}
+
+// Revert restores an item to one of its past versions, failing with
+// repository.ErrConflict if the item was modified elsewhere since it was loaded.
+func (s Service) Revert(i items.ItemInterface, rev string) error {
+	h, ok := s.repository.(repository.History)
+	if !ok {
+		return ErrHistoryNotSupported
+	}
+	if err := checkUnchanged(s.repository, i); err != nil {
+		return err
+	}
+	if err := h.RevertItem(i.GetId(), rev); err != nil {
+		return err
+	}
+	if reverted, err := s.Reload(i); err == nil {
+		s.events.publish(Event{Type: EventItemUpdated, Item: reverted})
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/markelca/prioritty/pkg/items"
+)
+
+// Hooks validate and change the items before the service stores them, e.g. requiring
+// a tag on new tasks. Returning an error vetoes the change. The items passed have the
+// tag they'll have once stored, and the ones returned must be of the same type.
+type Hooks interface {
+	// OnAdd returns the item to create instead of i.
+	OnAdd(i items.ItemInterface) (items.ItemInterface, error)
+	// OnModify returns the item to store instead of modified, the new version of old.
+	OnModify(old, modified items.ItemInterface) (items.ItemInterface, error)
+	// OnDelete is called before an item is removed.
+	OnDelete(i items.ItemInterface) error
+}
+
+// ErrVetoed is returned, wrapped, when a hook refuses a change.
+var ErrVetoed = errors.New("refused by hook")
+
+var errHookChangedType = errors.New("hooks can't change the type of items")
+
+// WithHooks returns a copy of the service running the hooks before every change.
+func (s Service) WithHooks(h Hooks) Service {
+	s.hooks = h
+	return s
+}
+
+// onAdd runs the hooks on a new item to be tagged tag, applying their changes to it.
+// It returns the tag the item should have.
+func (s Service) onAdd(i items.ItemInterface, tag string) (string, error) {
+	if s.hooks == nil {
+		return tag, nil
+	}
+	result, err := s.hooks.OnAdd(withTag(i, tag))
+	if err != nil {
+		return "", err
+	}
+	if !sameType(i, result) {
+		return "", errHookChangedType
+	}
+	assign(i, result)
+	return tagName(result), nil
+}
+
+// onModify runs the hooks on the change of old into modified, returning the item to
+// store instead. Converting between tasks and notes is a modification.
+func (s Service) onModify(old, modified items.ItemInterface) (items.ItemInterface, error) {
+	if s.hooks == nil {
+		return modified, nil
+	}
+	result, err := s.hooks.OnModify(old, modified)
+	if err != nil {
+		return nil, err
+	}
+	if !sameType(modified, result) {
+		return nil, errHookChangedType
+	}
+	return result, nil
+}
+
+// modify stores modified, a new version of i with its tag, once accepted by the hooks,
+// and returns the stored item.
+func (s Service) modify(i, modified items.ItemInterface) (items.ItemInterface, error) {
+	result, err := s.onModify(i, modified)
+	if err != nil {
+		return nil, err
+	}
+	return s.storeModified(i, result)
+}
+
+// storeModified stores the version of item returned by the hooks, when it can't be
+// stored with a narrower update (e.g. they changed more than what was asked), and
+// returns the stored item. It fails with repository.ErrConflict if the item, as it
+// was loaded, isn't the stored version anymore.
+func (s Service) storeModified(i, result items.ItemInterface) (items.ItemInterface, error) {
+	var err error
+	guarded := s.expecting(i)
+	switch v := i.(type) {
+	case *items.Task:
+		t := *v
+		assign(&t, result)
+		err, i = guarded.UpdateTask(t), &t
+	case *items.Note:
+		n := *v
+		assign(&n, result)
+		err, i = guarded.UpdateNote(n), &n
+	}
+	if err != nil {
+		return nil, err
+	}
+	stored, err := s.Reload(i)
+	if err != nil {
+		return nil, err
+	}
+	s.events.publish(Event{Type: EventItemUpdated, Item: stored})
+	if tagName(stored) == tagName(result) {
+		return stored, nil
+	}
+	if err := s.updateTagFromEditor(stored, tagName(result)); err != nil {
+		return nil, err
+	}
+	return s.Reload(stored)
+}
+
+// withTag returns a copy of the item with the tag named name, none if empty.
+func withTag(i items.ItemInterface, name string) items.ItemInterface {
+	tag := i.GetTag()
+	if tagName(i) != name {
+		tag = nil
+		if name != "" {
+			tag = &items.Tag{Name: name}
+		}
+	}
+	switch v := i.(type) {
+	case *items.Task:
+		t := *v
+		t.Tag = tag
+		return &t
+	case *items.Note:
+		n := *v
+		n.Tag = tag
+		return &n
+	}
+	return i
+}
+
+// assign copies the fields of src, a version of dst returned by the hooks, to dst.
+// The tag isn't copied: it's stored apart from the other fields.
+func assign(dst, src items.ItemInterface) {
+	switch d := dst.(type) {
+	case *items.Task:
+		if t, ok := src.(*items.Task); ok {
+			tag := d.Tag
+			*d = *t
+			d.Tag = tag
+		}
+	case *items.Note:
+		if n, ok := src.(*items.Note); ok {
+			tag := d.Tag
+			*d = *n
+			d.Tag = tag
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"path"
+	"strings"
+
+	"github.com/markelca/prioritty/pkg/items"
+	"github.com/markelca/prioritty/pkg/markdown"
+)
+
+// Links holds the items an item links to with [[wikilinks]] in its body,
+// and the items whose bodies link to it.
+type Links struct {
+	Outgoing   []items.ItemInterface
+	Backlinks  []items.ItemInterface
+	Unresolved []string // link targets that don't match any item
+}
+
+// GetLinks resolves the outgoing links and backlinks of an item against all stored items.
+func (s Service) GetLinks(i items.ItemInterface) (Links, error) {
+	all, err := s.GetAll()
+	if err != nil {
+		return Links{}, err
+	}
+	return FindLinks(i, all), nil
+}
+
+// FindLinks resolves the outgoing links and backlinks of an item within the given items.
+// Link targets match an item by title or by filename, case-insensitively.
+func FindLinks(i items.ItemInterface, all []items.ItemInterface) Links {
+	return NewLinkIndex(all).Links(i)
+}
+
+// LinkIndex resolves the links between items, parsing their bodies once, e.g. for
+// the detail view to show the links of every item the cursor moves to.
+type LinkIndex struct {
+	all       []items.ItemInterface
+	wikilinks map[itemKey][]markdown.Wikilink
+	byTitle   map[string]items.ItemInterface   // by lowercase title
+	byKey     map[string]items.ItemInterface   // by any of the linkKeys
+	linking   map[string][]items.ItemInterface // items linking to a target, by normalized target
+}
+
+// itemKey identifies an item, tasks and notes may share IDs.
+type itemKey struct {
+	id     string
+	isTask bool
+}
+
+func keyOf(i items.ItemInterface) itemKey {
+	_, isTask := i.(*items.Task)
+	return itemKey{i.GetId(), isTask}
+}
+
+// NewLinkIndex indexes the links between the given items.
+func NewLinkIndex(all []items.ItemInterface) *LinkIndex {
+	x := &LinkIndex{
+		all:       all,
+		wikilinks: map[itemKey][]markdown.Wikilink{},
+		byTitle:   map[string]items.ItemInterface{},
+		byKey:     map[string]items.ItemInterface{},
+		linking:   map[string][]items.ItemInterface{},
+	}
+	for _, i := range all {
+		// The first item wins, as when looking for the target in order
+		if title := strings.ToLower(i.GetTitle()); x.byTitle[title] == nil {
+			x.byTitle[title] = i
+		}
+		for key := range linkKeys(i) {
+			if x.byKey[key] == nil {
+				x.byKey[key] = i
+			}
+		}
+
+		links := markdown.Wikilinks(i.GetBody())
+		x.wikilinks[keyOf(i)] = links
+		targets := map[string]bool{}
+		for _, link := range links {
+			target := normalizeLinkTarget(link.Target)
+			if !targets[target] {
+				targets[target] = true
+				x.linking[target] = append(x.linking[target], i)
+			}
+		}
+	}
+	return x
+}
+
+// Links returns the outgoing links and backlinks of an item. A nil index has none.
+func (x *LinkIndex) Links(i items.ItemInterface) Links {
+	var links Links
+	if x == nil || i == nil {
+		return links
+	}
+
+	wikilinks, ok := x.wikilinks[keyOf(i)]
+	if !ok {
+		wikilinks = markdown.Wikilinks(i.GetBody())
+	}
+	for _, link := range wikilinks {
+		target := x.resolve(link.Target)
+		if target == nil {
+			links.Unresolved = append(links.Unresolved, link.Target)
+			continue
+		}
+		if !sameItem(target, i) && !containsItem(links.Outgoing, target) {
+			links.Outgoing = append(links.Outgoing, target)
+		}
+	}
+
+	linking := map[itemKey]bool{}
+	for key := range linkKeys(i) {
+		for _, other := range x.linking[key] {
+			linking[keyOf(other)] = true
+		}
+	}
+	if len(linking) > 0 {
+		// In the order of the items
+		for _, other := range x.all {
+			if linking[keyOf(other)] && !sameItem(other, i) {
+				links.Backlinks = append(links.Backlinks, other)
+			}
+		}
+	}
+	return links
+}
+
+// resolve returns the item a link target points to, preferring title matches.
+func (x *LinkIndex) resolve(target string) items.ItemInterface {
+	key := normalizeLinkTarget(target)
+	if i := x.byTitle[key]; i != nil {
+		return i
+	}
+	return x.byKey[key]
+}
+
+// linkKeys returns the normalized names a link can use to reference the item:
+// its title, its filename derived from the title and, for file backed items, its path.
+func linkKeys(i items.ItemInterface) map[string]bool {
+	keys := map[string]bool{}
+	keys[strings.ToLower(i.GetTitle())] = true
+	keys[markdown.Slug(i.GetTitle())] = true
+	if id := i.GetId(); strings.HasSuffix(strings.ToLower(id), ".md") {
+		keys[normalizeLinkTarget(id)] = true
+		keys[normalizeLinkTarget(path.Base(id))] = true
+	}
+	return keys
+}
+
+func normalizeLinkTarget(target string) string {
+	target = strings.TrimSpace(target)
+	if strings.HasSuffix(strings.ToLower(target), ".md") {
+		target = target[:len(target)-len(".md")]
+	}
+	return strings.ToLower(target)
+}
+
+func sameItem(a, b items.ItemInterface) bool {
+	_, aIsTask := a.(*items.Task)
+	_, bIsTask := b.(*items.Task)
+	return a.GetId() == b.GetId() && aIsTask == bIsTask
+}
+
+func containsItem(list []items.ItemInterface, i items.ItemInterface) bool {
+	for _, other := range list {
+		if sameItem(other, i) {
+			return true
+		}
+	}
+	return false
+}
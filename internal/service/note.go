@@ -17,12 +17,6 @@ func (s NoteService) UpdateNote(n items.Note) error {
 	return s.repository.UpdateNote(n)
 }
 
-func (s NoteService) AddNote(title string) error {
-	t := items.Note{}
-	t.Title = title
-	return s.repository.CreateNote(&t)
-}
-
 func (s NoteService) removeNote(id string) error {
 	return s.repository.RemoveNote(id)
 }
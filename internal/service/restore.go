@@ -0,0 +1,283 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/markelca/prioritty/pkg/items"
+	"github.com/markelca/prioritty/pkg/items/repository"
+	"github.com/markelca/prioritty/pkg/items/repository/memory"
+)
+
+// RestoreResult summarizes a Restore.
+type RestoreResult struct {
+	repository.CopyResult          // items created
+	Updated               int      // items replaced by their version in the backup, when replacing
+	Removed               int      // items missing from the backup removed, when replacing
+	Unchanged             int      // items that were already there
+	Conflicts             []string // items that differ from the backup, kept as they are
+}
+
+// Restore loads items and tags, e.g. from a backup. Items present in both (same UID,
+// or same type, title and creation day) are matched, and those missing from the
+// repository are created. Matched items that differ are reported as conflicts and left
+// alone, or replaced by their version in data when replace is set, which also removes
+// the items missing from data once the rest was loaded. Inline tasks, lines of other
+// notes, are never removed. The hooks run as for any creation, edit or removal.
+func (s Service) Restore(data memory.Data, replace bool) (RestoreResult, error) {
+	var result RestoreResult
+
+	for _, tag := range data.Tags {
+		if _, err := repository.GetOrCreateTag(s.repository, tag.Name); err != nil {
+			return result, fmt.Errorf("failed to restore tag %q: %w", tag.Name, err)
+		}
+	}
+
+	tasks, err := s.repository.GetTasks()
+	if err != nil {
+		return result, err
+	}
+	notes, err := s.repository.GetNotes()
+	if err != nil {
+		return result, err
+	}
+
+	var missing memory.Data
+	var replaced [][2]items.ItemInterface // current and restored versions
+	taskMatcher := newMatcher(tasks)
+	for _, t := range data.Tasks {
+		current, ok := taskMatcher.match(t)
+		if !ok {
+			missing.Tasks = append(missing.Tasks, t)
+			continue
+		}
+		diff := taskDiff(current, t)
+		switch {
+		case len(diff) == 0:
+			result.Unchanged++
+		case replace:
+			t.Id = current.Id
+			replaced = append(replaced, [2]items.ItemInterface{&current, &t})
+		default:
+			result.Conflicts = append(result.Conflicts, fmt.Sprintf("task %q differs in %s", t.Title, strings.Join(diff, ", ")))
+		}
+	}
+	noteMatcher := newMatcher(notes)
+	for _, n := range data.Notes {
+		current, ok := noteMatcher.match(n)
+		if !ok {
+			missing.Notes = append(missing.Notes, n)
+			continue
+		}
+		diff := itemDiff(current, n)
+		switch {
+		case len(diff) == 0:
+			result.Unchanged++
+		case replace:
+			n.Id = current.Id
+			replaced = append(replaced, [2]items.ItemInterface{&current, &n})
+		default:
+			result.Conflicts = append(result.Conflicts, fmt.Sprintf("note %q differs in %s", n.Title, strings.Join(diff, ", ")))
+		}
+	}
+
+	var stale []items.ItemInterface
+	if replace {
+		stale = s.removable(taskMatcher.unmatched(), noteMatcher.unmatched())
+		// Hooks can refuse the removals before anything changes
+		if s.hooks != nil {
+			for _, i := range stale {
+				if err := s.hooks.OnDelete(i); err != nil {
+					return result, fmt.Errorf("can't remove %q: %w", i.GetTitle(), err)
+				}
+			}
+		}
+	}
+
+	// The removals wait until the backup is loaded, so a failure leaves the current items
+	for _, r := range replaced {
+		if _, err := s.modify(r[0], r[1]); err != nil {
+			return result, fmt.Errorf("failed to restore %q: %w", r[1].GetTitle(), err)
+		}
+		result.Updated++
+	}
+	for _, t := range missing.Tasks {
+		t.Id = ""
+		if err := s.CreateTask(&t, tagName(&t)); err != nil {
+			return result, fmt.Errorf("failed to restore task %q: %w", t.Title, err)
+		}
+		result.Tasks++
+	}
+	for _, n := range missing.Notes {
+		n.Id = ""
+		if err := s.CreateNote(&n, tagName(&n)); err != nil {
+			return result, fmt.Errorf("failed to restore note %q: %w", n.Title, err)
+		}
+		result.Notes++
+	}
+
+	if !replace {
+		return result, nil
+	}
+	// Backwards, so removing a line based item (todo.txt) doesn't move the previous ones
+	for k := len(stale) - 1; k >= 0; k-- {
+		if err := s.remove(stale[k]); err != nil {
+			return result, fmt.Errorf("failed to remove %q: %w", stale[k].GetTitle(), err)
+		}
+		result.Removed++
+	}
+	return result, s.removeUnusedTags(data.Tags)
+}
+
+// removable returns the items that removing all items removes, as pointers: the tasks
+// and notes besides the inline tasks.
+func (s Service) removable(tasks []items.Task, notes []items.Note) []items.ItemInterface {
+	inline, _ := s.repository.(repository.Inline)
+	var list []items.ItemInterface
+	for k := range tasks {
+		if inline != nil && inline.IsInline(tasks[k].Id) {
+			continue
+		}
+		list = append(list, &tasks[k])
+	}
+	for k := range notes {
+		list = append(list, &notes[k])
+	}
+	return list
+}
+
+// remove removes an item, once accepted by the hooks, unless it changed since loaded.
+func (s Service) remove(i items.ItemInterface) error {
+	var err error
+	switch v := i.(type) {
+	case *items.Task:
+		err = s.expecting(i).removeTask(v.Id)
+	case *items.Note:
+		err = s.expecting(i).removeNote(v.Id)
+	}
+	if err != nil {
+		return err
+	}
+	s.events.publish(Event{Type: EventItemDeleted, Item: i})
+	return nil
+}
+
+// removeUnusedTags removes the tags besides keep that no item uses.
+func (s Service) removeUnusedTags(keep []items.Tag) error {
+	tags, err := s.repository.GetTags()
+	if err != nil {
+		return err
+	}
+	kept := map[string]bool{}
+	for _, tag := range keep {
+		kept[tag.Name] = true
+	}
+	for _, tag := range tags {
+		if kept[tag.Name] {
+			continue
+		}
+		used, err := s.repository.GetItemsWithTag(tag.Name)
+		if err != nil {
+			return err
+		}
+		if len(used) > 0 {
+			continue
+		}
+		if err := s.repository.RemoveTag(tag.Name); err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("failed to remove tag %q: %w", tag.Name, err)
+		}
+	}
+	return nil
+}
+
+// matcher finds the current item matching an item of a backup. Each current item
+// is matched at most once, so duplicates in the backup are restored as duplicates.
+type matcher[T items.ItemInterface] struct {
+	items []T
+	taken []bool
+}
+
+func newMatcher[T items.ItemInterface](current []T) *matcher[T] {
+	return &matcher[T]{items: current, taken: make([]bool, len(current))}
+}
+
+func (m *matcher[T]) match(i T) (T, bool) {
+	found := -1
+	for k, current := range m.items {
+		if m.taken[k] {
+			continue
+		}
+		if i.GetUID() != "" && current.GetUID() == i.GetUID() {
+			found = k
+			break
+		}
+		if i.GetUID() != "" && current.GetUID() != "" {
+			continue
+		}
+		// Some backends only keep the creation day (e.g. todo.txt)
+		if found == -1 && current.GetTitle() == i.GetTitle() && sameDay(current.GetCreatedAt(), i.GetCreatedAt()) {
+			found = k
+		}
+	}
+	if found == -1 {
+		var zero T
+		return zero, false
+	}
+	m.taken[found] = true
+	return m.items[found], true
+}
+
+// unmatched returns the current items that no item matched.
+func (m *matcher[T]) unmatched() []T {
+	var list []T
+	for k, taken := range m.taken {
+		if !taken {
+			list = append(list, m.items[k])
+		}
+	}
+	return list
+}
+
+func sameDay(a, b time.Time) bool {
+	return items.FormatDate(a.Local()) == items.FormatDate(b.Local())
+}
+
+// itemDiff lists the fields of two items that differ.
+func itemDiff(current, restored items.ItemInterface) []string {
+	var diff []string
+	if current.GetTitle() != restored.GetTitle() {
+		diff = append(diff, "title")
+	}
+	if strings.TrimSpace(current.GetBody()) != strings.TrimSpace(restored.GetBody()) {
+		diff = append(diff, "body")
+	}
+	if tagName(current) != tagName(restored) {
+		diff = append(diff, "tag")
+	}
+	return diff
+}
+
+func taskDiff(current, restored items.Task) []string {
+	diff := itemDiff(current, restored)
+	if current.Status != restored.Status {
+		diff = append(diff, "status")
+	}
+	if current.Priority != restored.Priority {
+		diff = append(diff, "priority")
+	}
+	if items.FormatDate(current.DueDate) != items.FormatDate(restored.DueDate) {
+		diff = append(diff, "due date")
+	}
+	if items.FormatDate(current.ScheduledDate) != items.FormatDate(restored.ScheduledDate) {
+		diff = append(diff, "scheduled date")
+	}
+	if current.Recurrence != restored.Recurrence {
+		diff = append(diff, "recurrence")
+	}
+	if current.Estimate != restored.Estimate {
+		diff = append(diff, "estimate")
+	}
+	return diff
+}
@@ -4,7 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"sort"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/markelca/prioritty/internal/editor"
@@ -16,13 +16,17 @@ type Service struct {
 	TaskService
 	NoteService
 	repository repository.Repository
+	events     *Bus
+	hooks      Hooks // nil if there are none
 }
 
 func NewService(r repository.Repository) Service {
+	events := &Bus{}
 	return Service{
 		TaskService: TaskService{repository: r},
 		NoteService: NoteService{repository: r},
 		repository:  r,
+		events:      events,
 	}
 }
 
@@ -47,44 +51,119 @@ func (s Service) GetAll() ([]items.ItemInterface, error) {
 		allItems = append(allItems, &task)
 	}
 
-	sort.Slice(allItems, func(i, j int) bool {
-		return allItems[i].After(allItems[j])
-	})
-
+	items.Sort(allItems)
 	return allItems, nil
 }
 
+// List returns all items in the order they're listed, whose 1-based positions are the
+// numbers commands take (see items.List).
+func (s Service) List() ([]items.ItemInterface, error) {
+	tasks, err := s.GetTasks()
+	if err != nil {
+		return nil, err
+	}
+	notes, err := s.GetNotes()
+	if err != nil {
+		return nil, err
+	}
+	return items.List(tasks, notes), nil
+}
+
+// ItemAt returns the item at index, 0-based, in List, or repository.ErrNotFound.
+// Repositories finding it themselves (e.g. the daemon's) don't send all items.
+func (s Service) ItemAt(index int) (items.ItemInterface, error) {
+	if r, ok := s.repository.(repository.Indexed); ok {
+		return r.ItemAt(index)
+	}
+	list, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || index >= len(list) {
+		return nil, fmt.Errorf("item %d: %w", index+1, repository.ErrNotFound)
+	}
+	return list[index], nil
+}
+
 func (s Service) RemoveItem(item items.ItemInterface) error {
+	if err := checkUnchanged(s.repository, item); err != nil {
+		return err
+	}
+	if s.hooks != nil {
+		if err := s.hooks.OnDelete(item); err != nil {
+			return err
+		}
+	}
+	var err error
 	switch v := item.(type) {
 	case *items.Note:
-		return s.removeNote(v.GetId())
+		err = s.expecting(item).removeNote(v.GetId())
 	case *items.Task:
-		return s.removeTask(v.GetId())
+		err = s.expecting(item).removeTask(v.GetId())
 	default:
 		return fmt.Errorf("Cannot remove item %v", v)
 	}
+	if err != nil {
+		return err
+	}
+	s.events.publish(Event{Type: EventItemDeleted, Item: item})
+	return nil
 }
 
 func (s Service) UpdateItemFromEditorMsg(i items.ItemInterface, msg editor.EditorFinishedMsg) error {
+	// Refuse to overwrite changes made elsewhere while the editor was open
+	current, err := loadUnchanged(s.repository, i)
+	if err != nil {
+		return err
+	}
 	switch v := i.(type) {
 	case *items.Task:
 		// Check if type changed from task to note
 		if msg.ItemType == items.ItemTypeNote {
 			return s.convertTaskToNote(v, msg)
 		}
-		// Update as task
+		// Update as task, keeping the time entries logged while the editor was open
+		v.TimeLog = current.(*items.Task).TimeLog
+		original := *v
 		v.Title = msg.Title
 		v.Body = msg.Body
 		// Update status if provided
 		if msg.Status != "" {
 			v.Status = items.ParseStatus(msg.Status)
 		}
-		if err := s.UpdateTask(*v); err != nil {
+		v.Priority = msg.Priority
+		v.DueDate = msg.Due
+		v.ScheduledDate = msg.Scheduled
+		v.Recurrence = msg.Recurrence
+		v.Estimate = msg.Estimate
+		// Like UpdateStatus, the timer stops when the task leaves in-progress
+		if v.Status != items.InProgress {
+			v.StopTimer(time.Now())
+		}
+		result, err := s.onModify(&original, withTag(v, msg.Tag))
+		if err != nil {
+			*v = original
+			return err
+		}
+		assign(v, result)
+		msg.Tag = tagName(result)
+		if err := s.expecting(&original).UpdateTask(*v); err != nil {
 			log.Println("Error updating the task - ", err)
+			return err
+		}
+		// The ID changes when the title does for some repositories, e.g. Obsidian files
+		stored, err := s.Reload(v)
+		if err != nil {
+			return err
+		}
+		s.events.publish(Event{Type: EventItemUpdated, Item: stored})
+		if v.Status != original.Status {
+			s.events.publish(Event{Type: EventTaskStatusChanged, Item: stored, PreviousStatus: original.Status})
 		}
 		// Update tag if changed
-		if err := s.updateTagFromEditor(v, msg.Tag); err != nil {
+		if err := s.updateTagFromEditor(stored, msg.Tag); err != nil {
 			log.Println("Error updating tag - ", err)
+			return err
 		}
 	case *items.Note:
 		// Check if type changed from note to task
@@ -92,14 +171,30 @@ func (s Service) UpdateItemFromEditorMsg(i items.ItemInterface, msg editor.Edito
 			return s.convertNoteToTask(v, msg)
 		}
 		// Update as note
+		original := *v
 		v.Title = msg.Title
 		v.Body = msg.Body
-		if err := s.UpdateNote(*v); err != nil {
+		result, err := s.onModify(&original, withTag(v, msg.Tag))
+		if err != nil {
+			*v = original
+			return err
+		}
+		assign(v, result)
+		msg.Tag = tagName(result)
+		if err := s.expecting(&original).UpdateNote(*v); err != nil {
 			log.Println("Error updating the note - ", err)
+			return err
+		}
+		// The ID changes when the title does for some repositories, e.g. Obsidian files
+		stored, err := s.Reload(v)
+		if err != nil {
+			return err
 		}
+		s.events.publish(Event{Type: EventItemUpdated, Item: stored})
 		// Update tag if changed
-		if err := s.updateTagFromEditor(v, msg.Tag); err != nil {
+		if err := s.updateTagFromEditor(stored, msg.Tag); err != nil {
 			log.Println("Error updating tag - ", err)
+			return err
 		}
 	default:
 		return fmt.Errorf("Can't update the item, no implementation: %v", v)
@@ -109,22 +204,36 @@ func (s Service) UpdateItemFromEditorMsg(i items.ItemInterface, msg editor.Edito
 
 // convertTaskToNote converts a task to a note by deleting the task and creating a note
 func (s Service) convertTaskToNote(task *items.Task, msg editor.EditorFinishedMsg) error {
+	note := noteFromEditorMsg(msg)
+	result, err := s.onModify(task, withTag(&note, msg.Tag))
+	if err != nil {
+		return err
+	}
+	assign(&note, result)
 	// Use msg.Id which is the original item's ID (set in EditItem from the original item)
-	if err := s.removeTask(msg.Id); err != nil {
+	if err := s.expecting(task).removeTask(msg.Id); err != nil {
 		return fmt.Errorf("failed to remove task during conversion: %w", err)
 	}
+	s.events.publish(Event{Type: EventItemDeleted, Item: task})
 	// Create the new note
-	return s.CreateNoteFromEditorMsg(msg)
+	return s.createNote(&note, tagName(result))
 }
 
 // convertNoteToTask converts a note to a task by deleting the note and creating a task
 func (s Service) convertNoteToTask(note *items.Note, msg editor.EditorFinishedMsg) error {
+	task := taskFromEditorMsg(msg)
+	result, err := s.onModify(note, withTag(&task, msg.Tag))
+	if err != nil {
+		return err
+	}
+	assign(&task, result)
 	// Use msg.Id which is the original item's ID (set in EditItem from the original item)
-	if err := s.removeNote(msg.Id); err != nil {
+	if err := s.expecting(note).removeNote(msg.Id); err != nil {
 		return fmt.Errorf("failed to remove note during conversion: %w", err)
 	}
+	s.events.publish(Event{Type: EventItemDeleted, Item: note})
 	// Create the new task
-	return s.CreateTaskFromEditorMsg(msg)
+	return s.createTask(&task, tagName(result))
 }
 
 // updateTagFromEditor updates an item's tag based on the editor message.
@@ -142,49 +251,168 @@ func (s Service) updateTagFromEditor(i items.ItemInterface, tagName string) erro
 
 	// Remove tag if empty
 	if tagName == "" {
-		return s.UnsetTag(i)
+		return s.unsetTag(i)
 	}
 
 	// Set new tag
-	return s.SetTag(i, tagName)
+	return s.setTag(i, tagName)
 }
 
 func (s Service) CreateTaskFromEditorMsg(msg editor.EditorFinishedMsg) error {
-	task := items.Task{
+	task := taskFromEditorMsg(msg)
+	return s.CreateTask(&task, msg.Tag)
+}
+
+func (s Service) CreateNoteFromEditorMsg(msg editor.EditorFinishedMsg) error {
+	note := noteFromEditorMsg(msg)
+	return s.CreateNote(&note, msg.Tag)
+}
+
+func taskFromEditorMsg(msg editor.EditorFinishedMsg) items.Task {
+	return items.Task{
+		Item: items.Item{
+			Title: msg.Title,
+			Body:  msg.Body,
+		},
+		Status:        items.ParseStatus(msg.Status),
+		Priority:      msg.Priority,
+		DueDate:       msg.Due,
+		ScheduledDate: msg.Scheduled,
+		Recurrence:    msg.Recurrence,
+		Estimate:      msg.Estimate,
+	}
+}
+
+func noteFromEditorMsg(msg editor.EditorFinishedMsg) items.Note {
+	return items.Note{
 		Item: items.Item{
 			Title: msg.Title,
 			Body:  msg.Body,
 		},
-		Status: items.ParseStatus(msg.Status),
 	}
-	if err := s.repository.CreateTask(&task); err != nil {
+}
+
+// AddTask stores a new task with only a title.
+func (s Service) AddTask(title string) error {
+	t := items.Task{Status: items.Todo}
+	t.Title = title
+	return s.CreateTask(&t, "")
+}
+
+// AddNote stores a new note with only a title.
+func (s Service) AddNote(title string) error {
+	n := items.Note{}
+	n.Title = title
+	return s.CreateNote(&n, "")
+}
+
+// CreateTask stores a new task, with the tag if not empty, and sets its ID.
+func (s Service) CreateTask(t *items.Task, tag string) error {
+	tag, err := s.onAdd(t, tag)
+	if err != nil {
 		return err
 	}
+	return s.createTask(t, tag)
+}
+
+func (s Service) createTask(t *items.Task, tag string) error {
+	if err := s.repository.CreateTask(t); err != nil {
+		return err
+	}
+	s.events.publish(Event{Type: EventItemCreated, Item: t})
 	// Set tag if provided
-	if msg.Tag != "" {
-		return s.SetTag(&task, msg.Tag)
+	if tag != "" {
+		return s.setTag(t, tag)
 	}
 	return nil
 }
 
-func (s Service) CreateNoteFromEditorMsg(msg editor.EditorFinishedMsg) error {
-	note := items.Note{
-		Item: items.Item{
-			Title: msg.Title,
-			Body:  msg.Body,
-		},
+// CreateNote stores a new note, with the tag if not empty, and sets its ID.
+func (s Service) CreateNote(n *items.Note, tag string) error {
+	tag, err := s.onAdd(n, tag)
+	if err != nil {
+		return err
 	}
-	if err := s.repository.CreateNote(&note); err != nil {
+	return s.createNote(n, tag)
+}
+
+func (s Service) createNote(n *items.Note, tag string) error {
+	if err := s.repository.CreateNote(n); err != nil {
 		return err
 	}
+	s.events.publish(Event{Type: EventItemCreated, Item: n})
 	// Set tag if provided
-	if msg.Tag != "" {
-		return s.SetTag(&note, msg.Tag)
+	if tag != "" {
+		return s.setTag(n, tag)
 	}
 	return nil
 }
 
+// GetItem returns the task or note with the given ID, or repository.ErrNotFound.
+// Tasks and notes may share IDs, depending on the repository.
+func (s Service) GetItem(itemType items.ItemType, id string) (items.ItemInterface, error) {
+	var i items.ItemInterface
+	switch itemType {
+	case items.ItemTypeTask:
+		i = &items.Task{Item: items.Item{Id: id}}
+	case items.ItemTypeNote:
+		i = &items.Note{Item: items.Item{Id: id}}
+	default:
+		return nil, fmt.Errorf("invalid item type %q", itemType)
+	}
+	stored, err := findStored(s.repository, i)
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil {
+		return nil, repository.ErrNotFound
+	}
+	return stored, nil
+}
+
+// Reload returns the stored version of an item after it was updated. Items whose ID
+// changed with the update (e.g. Obsidian files renamed after their new title) are
+// found by UID, or by type, title and creation time.
+func (s Service) Reload(i items.ItemInterface) (items.ItemInterface, error) {
+	all, err := s.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, current := range all {
+		if current.GetId() == i.GetId() && sameType(current, i) {
+			return current, nil
+		}
+	}
+	for _, current := range all {
+		if !sameType(current, i) {
+			continue
+		}
+		if i.GetUID() != "" && current.GetUID() == i.GetUID() {
+			return current, nil
+		}
+		if i.GetUID() == "" && current.GetTitle() == i.GetTitle() && current.GetCreatedAt().Equal(i.GetCreatedAt()) {
+			return current, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func sameType(a, b items.ItemInterface) bool {
+	_, aIsTask := a.(*items.Task)
+	_, bIsTask := b.(*items.Task)
+	return aIsTask == bIsTask
+}
+
+// SetTag sets the tag of an item, failing with repository.ErrConflict if the
+// item was modified elsewhere since it was loaded.
 func (s Service) SetTag(i items.ItemInterface, name string) error {
+	if err := checkUnchanged(s.repository, i); err != nil {
+		return err
+	}
+	return s.changeTag(i, name)
+}
+
+func (s Service) setTag(i items.ItemInterface, name string) error {
 	var tag *items.Tag
 	var err error
 	tag, err = s.repository.GetTag(name)
@@ -200,26 +428,120 @@ func (s Service) SetTag(i items.ItemInterface, name string) error {
 			return err
 		}
 	}
+	var tagged items.ItemInterface
 	switch v := i.(type) {
 	case *items.Task:
-		return s.repository.SetTaskTag(*v, *tag)
+		err = s.repository.SetTaskTag(*v, *tag)
+		t := *v
+		t.Tag, tagged = tag, &t
 	case *items.Note:
-		return s.repository.SetNoteTag(*v, *tag)
+		err = s.repository.SetNoteTag(*v, *tag)
+		n := *v
+		n.Tag, tagged = tag, &n
 	default:
 		return fmt.Errorf("Can't update the item, no implementation: %v", v)
 	}
-
+	if err != nil {
+		return err
+	}
+	s.events.publish(Event{Type: EventTagSet, Item: tagged, PreviousTag: tagName(i)})
+	return nil
 }
 
+// UnsetTag removes the tag of an item, failing with repository.ErrConflict if the
+// item was modified elsewhere since it was loaded.
 func (s Service) UnsetTag(i items.ItemInterface) error {
+	if err := checkUnchanged(s.repository, i); err != nil {
+		return err
+	}
+	return s.changeTag(i, "")
+}
+
+// changeTag sets the tag of an item, or removes it if name is empty, once accepted
+// by the hooks.
+func (s Service) changeTag(i items.ItemInterface, name string) error {
+	result, err := s.onModify(i, withTag(i, name))
+	if err != nil {
+		return err
+	}
+	if !items.SameContent(withTag(i, tagName(result)), result) {
+		// A hook changed more than the tag
+		_, err := s.storeModified(i, result)
+		return err
+	}
+	return s.expecting(i).updateTagFromEditor(i, tagName(result))
+}
+
+// UpdateStatus sets the status of a task, or todo if it already has it, failing with
+// repository.ErrConflict if the task was modified elsewhere since it was loaded. Its
+// timer, if running, stops when it leaves in-progress.
+func (s Service) UpdateStatus(t *items.Task, status items.Status) error {
+	if t.Status == status {
+		status = items.Todo
+	}
+	current, err := loadUnchanged(s.repository, t)
+	if err != nil {
+		return err
+	}
+	modified := *t
+	modified.Status = status
+	modified.TimeLog = current.(*items.Task).TimeLog
+	timerStopped := status != items.InProgress && modified.StopTimer(time.Now())
+	result, err := s.onModify(t, &modified)
+	if err != nil {
+		return err
+	}
+
+	if !timerStopped && items.SameContent(&modified, result) {
+		previous := *t
+		if err := s.expecting(t).repository.UpdateTaskStatus(*t, status); err != nil {
+			return err
+		}
+		t.SetStatus(status)
+		if t.Status != previous.Status {
+			s.events.publish(Event{Type: EventTaskStatusChanged, Item: t, PreviousStatus: previous.Status})
+		}
+		return nil
+	}
+	// The timer stopped, or a hook changed more than the status
+	return s.storeTask(t, result)
+}
+
+// storeTask stores result, the new version of t returned by the hooks, and updates t
+// to the version stored.
+func (s Service) storeTask(t *items.Task, result items.ItemInterface) error {
+	previous := *t
+	stored, err := s.storeModified(t, result)
+	if err != nil {
+		return err
+	}
+	*t = *stored.(*items.Task)
+	if t.Status != previous.Status {
+		s.events.publish(Event{Type: EventTaskStatusChanged, Item: t, PreviousStatus: previous.Status})
+	}
+	return nil
+}
+
+func (s Service) unsetTag(i items.ItemInterface) error {
+	var err error
+	var untagged items.ItemInterface
 	switch v := i.(type) {
 	case *items.Task:
-		return s.repository.UnsetTaskTag(*v)
+		err = s.repository.UnsetTaskTag(*v)
+		t := *v
+		t.Tag, untagged = nil, &t
 	case *items.Note:
-		return s.repository.UnsetNoteTag(*v)
+		err = s.repository.UnsetNoteTag(*v)
+		n := *v
+		n.Tag, untagged = nil, &n
 	default:
 		return fmt.Errorf("Can't unset tag for item, no implementation: %v", v)
 	}
+	if err != nil {
+		return err
+	}
+	s.events.publish(Event{Type: EventTagUnset, Item: untagged, PreviousTag: tagName(i)})
+	return nil
 }
 
 func (s Service) GetTags() ([]items.Tag, error) {
@@ -247,3 +569,8 @@ func (s Service) GetItemsWithTag(name string) ([]items.ItemInterface, error) {
 func (s Service) AddWithEditor(itemType items.ItemType) (tea.Cmd, error) {
 	return editor.AddItem(itemType)
 }
+
+// AddWithTemplate opens the editor with a rendered template, showing all available fields.
+func (s Service) AddWithTemplate(itemType items.ItemType, content string) (tea.Cmd, error) {
+	return editor.AddItemFromTemplate(itemType, content)
+}
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	"github.com/markelca/prioritty/pkg/items"
+)
+
+// Stats summarizes the tasks created and completed in a period of days.
+type Stats struct {
+	Since, Until     time.Time     // first and last day of the period, at midnight local time
+	Completed        int           // tasks completed in the period
+	Created          int           // tasks created in the period
+	Open             int           // tasks open at the end of the period
+	Pomodoros        int           // pomodoros completed in the period
+	AverageLeadTime  time.Duration // from created to done, of the tasks completed in the period
+	AverageCycleTime time.Duration // from started to done, of those that were started
+	CurrentStreak    int           // consecutive days with completions up to the end of the period
+	LongestStreak    int           // longest run of days with completions in the period
+	Days             []DayStats    // every day of the period, oldest first
+	Weeks            []WeekStats   // weeks (starting on Monday) overlapping the period, oldest first
+	Tags             []TagStats    // tags of the tasks completed in the period, most completed first
+	Estimates        EstimateStats // estimated versus actual work of the tasks completed in the period
+}
+
+// EstimateStats compares the estimates of completed tasks with the time logged on them.
+// Tasks without time logged are left out, their actual work is unknown.
+type EstimateStats struct {
+	Tasks     int           // tasks estimated as a duration
+	Estimated time.Duration // sum of their estimates
+	Actual    time.Duration // time logged on them
+
+	PointTasks   int           // tasks estimated in story points
+	Points       float64       // sum of their points
+	PointsActual time.Duration // time logged on them
+}
+
+// DayStats counts the tasks of a day. Open is the number of tasks still open at its end.
+type DayStats struct {
+	Date                     time.Time
+	Completed, Created, Open int
+	Pomodoros                int
+}
+
+// WeekStats counts the tasks completed in a week.
+type WeekStats struct {
+	Start     time.Time // Monday
+	Completed int
+}
+
+// TagStats counts the tasks of a tag completed in the period. Tag is empty for untagged tasks.
+type TagStats struct {
+	Tag             string
+	Completed       int
+	AverageLeadTime time.Duration
+}
+
+// GetStats computes the statistics of all tasks between two days, both included.
+func (s Service) GetStats(since, until time.Time) (Stats, error) {
+	tasks, err := s.GetTasks()
+	if err != nil {
+		return Stats{}, err
+	}
+	return ComputeStats(tasks, since, until), nil
+}
+
+// ComputeStats computes the statistics of the given tasks between two days, both included.
+// Done tasks without a completion time (e.g. completed before it was recorded) are only
+// counted as closed.
+func ComputeStats(tasks []items.Task, since, until time.Time) Stats {
+	since, until = startOfDay(since), startOfDay(until)
+	stats := Stats{Since: since, Until: until}
+	end := until.AddDate(0, 0, 1)
+
+	for d := since; d.Before(end); d = d.AddDate(0, 0, 1) {
+		stats.Days = append(stats.Days, DayStats{Date: d})
+	}
+	for w := startOfWeek(since); w.Before(end); w = w.AddDate(0, 0, 7) {
+		stats.Weeks = append(stats.Weeks, WeekStats{Start: w})
+	}
+
+	completedDays := map[time.Time]bool{}
+	tags := map[string]*TagStats{}
+	tagLeadTimes := map[string][]time.Duration{}
+	var leadTimes, cycleTimes []time.Duration
+
+	for _, t := range tasks {
+		if !t.CreatedAt.IsZero() && inPeriod(t.CreatedAt, since, end) {
+			stats.Created++
+			stats.Days[dayIndex(t.CreatedAt, since)].Created++
+		}
+
+		// Open at the end of each day between its creation and its closing
+		closed, isClosed := closedAt(t)
+		if opened, ok := openedAt(t); ok {
+			for i := range stats.Days {
+				dayEnd := stats.Days[i].Date.AddDate(0, 0, 1)
+				if opened.Before(dayEnd) && (!isClosed || !closed.Before(dayEnd)) {
+					stats.Days[i].Open++
+				}
+			}
+		}
+
+		for _, e := range t.TimeLog {
+			if e.Pomodoro && inPeriod(e.End, since, end) {
+				stats.Pomodoros++
+				stats.Days[dayIndex(e.End, since)].Pomodoros++
+			}
+		}
+
+		if t.Status != items.Done || t.CompletedAt.IsZero() {
+			continue
+		}
+		if t.CompletedAt.Before(end) {
+			completedDays[startOfDay(t.CompletedAt)] = true
+		}
+		if !inPeriod(t.CompletedAt, since, end) {
+			continue
+		}
+
+		stats.Completed++
+		stats.Days[dayIndex(t.CompletedAt, since)].Completed++
+		week := startOfWeek(t.CompletedAt)
+		for i := range stats.Weeks {
+			if stats.Weeks[i].Start.Equal(week) {
+				stats.Weeks[i].Completed++
+			}
+		}
+
+		name := ""
+		if t.Tag != nil {
+			name = t.Tag.Name
+		}
+		if tags[name] == nil {
+			tags[name] = &TagStats{Tag: name}
+		}
+		tags[name].Completed++
+
+		if d, ok := t.LeadTime(); ok {
+			leadTimes = append(leadTimes, d)
+			tagLeadTimes[name] = append(tagLeadTimes[name], d)
+		}
+		if d, ok := t.CycleTime(); ok {
+			cycleTimes = append(cycleTimes, d)
+		}
+
+		if spent := t.TimeSpent(t.CompletedAt); spent > 0 {
+			e := &stats.Estimates
+			if t.Estimate.Duration > 0 {
+				e.Tasks++
+				e.Estimated += t.Estimate.Duration
+				e.Actual += spent
+			}
+			if t.Estimate.Points > 0 {
+				e.PointTasks++
+				e.Points += t.Estimate.Points
+				e.PointsActual += spent
+			}
+		}
+	}
+
+	if len(stats.Days) > 0 {
+		stats.Open = stats.Days[len(stats.Days)-1].Open
+	}
+	stats.AverageLeadTime = average(leadTimes)
+	stats.AverageCycleTime = average(cycleTimes)
+
+	for name, t := range tags {
+		t.AverageLeadTime = average(tagLeadTimes[name])
+		stats.Tags = append(stats.Tags, *t)
+	}
+	sort.Slice(stats.Tags, func(i, j int) bool {
+		if stats.Tags[i].Completed != stats.Tags[j].Completed {
+			return stats.Tags[i].Completed > stats.Tags[j].Completed
+		}
+		return stats.Tags[i].Tag < stats.Tags[j].Tag
+	})
+
+	stats.CurrentStreak, stats.LongestStreak = streaks(completedDays, since, until)
+	return stats
+}
+
+// streaks returns the number of consecutive days with completions up to until (or the
+// day before, if nothing was completed yet on until) and the longest run of them between
+// since and until.
+func streaks(completedDays map[time.Time]bool, since, until time.Time) (current, longest int) {
+	day := until
+	if !completedDays[day] {
+		day = day.AddDate(0, 0, -1)
+	}
+	for completedDays[day] {
+		current++
+		day = day.AddDate(0, 0, -1)
+	}
+
+	run := 0
+	for d := since; !d.After(until); d = d.AddDate(0, 0, 1) {
+		if completedDays[d] {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return current, longest
+}
+
+// openedAt returns when a task was created or, without a record of it, when its first
+// status was set. It returns false when neither is known, the task isn't counted open.
+func openedAt(t items.Task) (time.Time, bool) {
+	if !t.CreatedAt.IsZero() {
+		return t.CreatedAt, true
+	}
+	for _, change := range t.StatusHistory {
+		if !change.At.IsZero() {
+			return change.At, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// closedAt returns when a done or cancelled task was closed. Without a record of it,
+// its last update is the best guess.
+func closedAt(t items.Task) (time.Time, bool) {
+	switch t.Status {
+	case items.Done:
+		if !t.CompletedAt.IsZero() {
+			return t.CompletedAt, true
+		}
+	case items.Cancelled:
+		if n := len(t.StatusHistory); n > 0 && t.StatusHistory[n-1].Status == items.Cancelled {
+			return t.StatusHistory[n-1].At, true
+		}
+	default:
+		return time.Time{}, false
+	}
+	if !t.UpdatedAt.IsZero() {
+		return t.UpdatedAt, true
+	}
+	return t.CreatedAt, true
+}
+
+// average returns the mean of the durations, zero if there are none.
+func average(durations []time.Duration) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	var sum time.Duration
+	for _, d := range durations {
+		sum += d
+	}
+	return sum / time.Duration(len(durations))
+}
+
+func inPeriod(t, since, end time.Time) bool {
+	return !t.Before(since) && t.Before(end)
+}
+
+// dayIndex returns the index of the day of t in a period starting on since.
+func dayIndex(t, since time.Time) int {
+	day := startOfDay(t)
+	i := 0
+	for d := since; d.Before(day); d = d.AddDate(0, 0, 1) {
+		i++
+	}
+	return i
+}
+
+// startOfDay returns midnight of the day of t, in the local time zone.
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Local().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
+
+// startOfWeek returns midnight of the Monday of the week of t, in the local time zone.
+func startOfWeek(t time.Time) time.Time {
+	day := startOfDay(t)
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
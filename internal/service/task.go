@@ -23,18 +23,6 @@ func (s TaskService) UpdateTask(t items.Task) error {
 	return s.repository.UpdateTask(t)
 }
 
-func (s TaskService) UpdateStatus(t *items.Task, status items.Status) error {
-	if t.Status == status {
-		status = items.Todo
-	}
-	err := s.repository.UpdateTaskStatus(*t, status)
-	if err != nil {
-		return err
-	}
-	t.SetStatus(status)
-	return nil
-}
-
 func (s TaskService) SetTag(title string) error {
 	return nil
 }
@@ -43,12 +31,6 @@ func (s TaskService) TagItem(items items.ItemInterface, tag string) error {
 	return nil
 }
 
-func (s TaskService) AddTask(title string) error {
-	t := items.Task{}
-	t.Title = title
-	return s.repository.CreateTask(&t)
-}
-
 func (s TaskService) removeTask(id string) error {
 	return s.repository.RemoveTask(id)
 }
@@ -65,6 +47,11 @@ func (s TaskService) EditWithEditor(t items.ItemInterface) (tea.Cmd, error) {
 	case *items.Task:
 		input.ItemType = items.ItemTypeTask
 		input.Status = string(task.Status)
+		input.Priority = string(task.Priority)
+		input.Due = items.FormatDate(task.DueDate)
+		input.Scheduled = items.FormatDate(task.ScheduledDate)
+		input.Recurrence = task.Recurrence
+		input.Estimate = task.Estimate.String()
 	case *items.Note:
 		input.ItemType = items.ItemTypeNote
 	}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/markelca/prioritty/pkg/items"
+)
+
+// ErrNoTimer is returned when stopping the timer of a task that has none running.
+var ErrNoTimer = errors.New("no timer running")
+
+// RunningTimers returns the tasks whose timer is running.
+func (s Service) RunningTimers() ([]items.Task, error) {
+	tasks, err := s.GetTasks()
+	if err != nil {
+		return nil, err
+	}
+	var running []items.Task
+	for _, t := range tasks {
+		if t.TimerRunning() {
+			running = append(running, t)
+		}
+	}
+	return running, nil
+}
+
+// StartTimer starts tracking the time spent on a task, moving it to in-progress. The
+// timers of other tasks are stopped first, one runs at a time.
+func (s Service) StartTimer(t *items.Task) error {
+	current, err := loadUnchanged(s.repository, t)
+	if err != nil {
+		return err
+	}
+	running, err := s.RunningTimers()
+	if err != nil {
+		return err
+	}
+	for _, other := range running {
+		if other.Id == t.Id {
+			continue
+		}
+		if err := s.StopTimer(&other); err != nil {
+			return fmt.Errorf("stopping the timer of %q: %w", other.Title, err)
+		}
+	}
+
+	modified := *current.(*items.Task)
+	if modified.Status == items.InProgress && modified.TimerRunning() {
+		*t = modified
+		return nil
+	}
+	modified.Status = items.InProgress
+	modified.StartTimer(time.Now())
+	return s.updateTask(t, modified)
+}
+
+// StopTimer stops the running timer of a task, which stays in progress. It returns
+// ErrNoTimer if there's none.
+func (s Service) StopTimer(t *items.Task) error {
+	current, err := loadUnchanged(s.repository, t)
+	if err != nil {
+		return err
+	}
+	modified := *current.(*items.Task)
+	if !modified.StopTimer(time.Now()) {
+		return ErrNoTimer
+	}
+	return s.updateTask(t, modified)
+}
+
+// SetTimeLog replaces the time entries of a task, e.g. edited by hand.
+func (s Service) SetTimeLog(t *items.Task, timeLog []items.TimeEntry) error {
+	timeLog, err := items.NormalizeTimeLog(timeLog)
+	if err != nil {
+		return err
+	}
+	current, err := loadUnchanged(s.repository, t)
+	if err != nil {
+		return err
+	}
+	modified := *current.(*items.Task)
+	modified.TimeLog = timeLog
+	return s.updateTask(t, modified)
+}
+
+// StartFocus prepares a task for a focus session of pomodoros, moving it to
+// in-progress. The running timers are stopped: the pomodoros log their own time.
+func (s Service) StartFocus(t *items.Task) error {
+	running, err := s.RunningTimers()
+	if err != nil {
+		return err
+	}
+	for _, other := range running {
+		if err := s.StopTimer(&other); err != nil {
+			return fmt.Errorf("stopping the timer of %q: %w", other.Title, err)
+		}
+	}
+	// Its own timer may have been stopped
+	current, err := loadUnchanged(s.repository, t)
+	if err != nil {
+		return err
+	}
+	*t = *current.(*items.Task)
+	if t.Status == items.InProgress {
+		return nil
+	}
+	return s.UpdateStatus(t, items.InProgress)
+}
+
+// RecordPomodoro logs a pomodoro completed on a task: the periods of work, split by the
+// pauses, oldest first. The last one, completing the pomodoro, is flagged as such.
+func (s Service) RecordPomodoro(t *items.Task, work []items.TimeEntry) error {
+	var periods []items.TimeEntry
+	for _, e := range work {
+		if e.End.After(e.Start) {
+			periods = append(periods, items.TimeEntry{Start: e.Start, End: e.End})
+		}
+	}
+	if len(periods) == 0 {
+		return errors.New("the pomodoro has no time of work")
+	}
+	periods[len(periods)-1].Pomodoro = true
+
+	current, err := loadUnchanged(s.repository, t)
+	if err != nil {
+		return err
+	}
+	modified := *current.(*items.Task)
+	timeLog := append([]items.TimeEntry(nil), modified.TimeLog...)
+	timeLog = append(timeLog, periods...)
+	if modified.TimeLog, err = items.NormalizeTimeLog(timeLog); err != nil {
+		return err
+	}
+	return s.updateTask(t, modified)
+}
+
+// updateTask stores modified, a new version of t, once accepted by the hooks, and
+// updates t to the version stored.
+func (s Service) updateTask(t *items.Task, modified items.Task) error {
+	result, err := s.onModify(t, &modified)
+	if err != nil {
+		return err
+	}
+	return s.storeTask(t, result)
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	"github.com/markelca/prioritty/pkg/items"
+)
+
+// Timesheet is the time spent on tasks in a week, per tag and task.
+type Timesheet struct {
+	Start time.Time        // Monday, at midnight local time
+	Days  [7]time.Duration // from Monday
+	Total time.Duration
+	Tags  []TagTime // most time first
+}
+
+// TagTime is the time spent on the tasks of a tag in a week. Tag is empty for untagged tasks.
+type TagTime struct {
+	Tag   string
+	Days  [7]time.Duration
+	Total time.Duration
+	Tasks []TaskTime // most time first
+}
+
+// TaskTime is the time spent on a task in a week.
+type TaskTime struct {
+	Task  items.Task
+	Days  [7]time.Duration
+	Total time.Duration
+}
+
+// GetTimesheet computes the timesheet of the week (starting on Monday) of the given day.
+func (s Service) GetTimesheet(day time.Time) (Timesheet, error) {
+	tasks, err := s.GetTasks()
+	if err != nil {
+		return Timesheet{}, err
+	}
+	return ComputeTimesheet(tasks, day, time.Now()), nil
+}
+
+// ComputeTimesheet computes the timesheet of the week of the given day, running
+// timers counting up to now.
+func ComputeTimesheet(tasks []items.Task, day, now time.Time) Timesheet {
+	sheet := Timesheet{Start: startOfWeek(day)}
+	tags := map[string]*TagTime{}
+
+	for _, t := range tasks {
+		task := TaskTime{Task: t}
+		for i := range task.Days {
+			since := sheet.Start.AddDate(0, 0, i)
+			until := since.AddDate(0, 0, 1)
+			for _, e := range t.TimeLog {
+				task.Days[i] += e.Between(since, until, now)
+			}
+			task.Total += task.Days[i]
+		}
+		if task.Total == 0 {
+			continue
+		}
+
+		name := ""
+		if t.Tag != nil {
+			name = t.Tag.Name
+		}
+		if tags[name] == nil {
+			tags[name] = &TagTime{Tag: name}
+		}
+		tag := tags[name]
+		tag.Tasks = append(tag.Tasks, task)
+		for i, d := range task.Days {
+			tag.Days[i] += d
+			sheet.Days[i] += d
+		}
+		tag.Total += task.Total
+		sheet.Total += task.Total
+	}
+
+	for _, tag := range tags {
+		sort.SliceStable(tag.Tasks, func(i, j int) bool {
+			return tag.Tasks[i].Total > tag.Tasks[j].Total
+		})
+		sheet.Tags = append(sheet.Tags, *tag)
+	}
+	sort.Slice(sheet.Tags, func(i, j int) bool {
+		if sheet.Tags[i].Total != sheet.Tags[j].Total {
+			return sheet.Tags[i].Total > sheet.Tags[j].Total
+		}
+		return sheet.Tags[i].Tag < sheet.Tags[j].Tag
+	})
+	return sheet
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/markelca/prioritty/pkg/items"
+	"github.com/markelca/prioritty/pkg/items/repository"
+	"github.com/markelca/prioritty/pkg/items/repository/memory"
+)
+
+// ImportResult summarizes an Import.
+type ImportResult struct {
+	Created   repository.CopyResult // items new to the repository
+	Updated   repository.CopyResult // items already stored with the same UID
+	Unchanged int                   // items already stored as they are in the source
+}
+
+// Export copies all items to dst, e.g. a file in another storage format.
+func (s Service) Export(dst repository.Repository) (repository.CopyResult, error) {
+	return repository.Copy(dst, s.repository)
+}
+
+// Import adds the items of src. Those with the UID of a stored item replace it, so
+// importing the same file again updates the items instead of duplicating them. The
+// hooks run as if each item was created or edited.
+func (s Service) Import(src repository.Repository) (ImportResult, error) {
+	var result ImportResult
+
+	srcTasks, err := src.GetTasks()
+	if err != nil {
+		return result, err
+	}
+	srcNotes, err := src.GetNotes()
+	if err != nil {
+		return result, err
+	}
+	tasks, err := s.repository.GetTasks()
+	if err != nil {
+		return result, err
+	}
+	notes, err := s.repository.GetNotes()
+	if err != nil {
+		return result, err
+	}
+
+	storedTasks := map[string]*items.Task{}
+	for k := range tasks {
+		if tasks[k].UID != "" {
+			storedTasks[tasks[k].UID] = &tasks[k]
+		}
+	}
+	for _, t := range srcTasks {
+		current, ok := storedTasks[t.UID]
+		if !ok {
+			t.Id = ""
+			if err := s.CreateTask(&t, tagName(&t)); err != nil {
+				return result, fmt.Errorf("failed to import task %q: %w", t.Title, err)
+			}
+			result.Created.Tasks++
+			continue
+		}
+		t.Id = current.Id
+		// Formats without time tracking keep the time logged here
+		if len(t.TimeLog) == 0 {
+			t.TimeLog = current.TimeLog
+		}
+		if items.SameContent(current, &t) {
+			result.Unchanged++
+			continue
+		}
+		if _, err := s.modify(current, &t); err != nil {
+			return result, fmt.Errorf("failed to update task %q: %w", t.Title, err)
+		}
+		result.Updated.Tasks++
+	}
+
+	storedNotes := map[string]*items.Note{}
+	for k := range notes {
+		if notes[k].UID != "" {
+			storedNotes[notes[k].UID] = &notes[k]
+		}
+	}
+	for _, n := range srcNotes {
+		current, ok := storedNotes[n.UID]
+		if !ok {
+			n.Id = ""
+			if err := s.CreateNote(&n, tagName(&n)); err != nil {
+				return result, fmt.Errorf("failed to import note %q: %w", n.Title, err)
+			}
+			result.Created.Notes++
+			continue
+		}
+		n.Id = current.Id
+		if items.SameContent(current, &n) {
+			result.Unchanged++
+			continue
+		}
+		if _, err := s.modify(current, &n); err != nil {
+			return result, fmt.Errorf("failed to update note %q: %w", n.Title, err)
+		}
+		result.Updated.Notes++
+	}
+
+	return result, nil
+}
+
+// Snapshot returns all items and tags with their current IDs, e.g. to encode them
+// in a format that isn't a repository.
+func (s Service) Snapshot() (memory.Data, error) {
+	tasks, err := s.GetTasks()
+	if err != nil {
+		return memory.Data{}, err
+	}
+	notes, err := s.GetNotes()
+	if err != nil {
+		return memory.Data{}, err
+	}
+	tags, err := s.GetTags()
+	if err != nil {
+		return memory.Data{}, err
+	}
+	return memory.Data{Tasks: tasks, Notes: notes, Tags: tags}, nil
+}
@@ -0,0 +1,166 @@
+// Package templates loads the item templates: markdown files with frontmatter, named
+// after their file in the templates folder (bug.md is the bug template). Their text
+// may have placeholders, replaced when an item is created from them:
+//
+//	{{date}}             today, YYYY-MM-DD
+//	{{time}}             the current time, HH:MM
+//	{{title}}            the title given when creating the item, if any
+//	{{prompt:Customer}}  the answer to a question asked when creating the item
+package templates
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned by Load for templates that don't exist.
+var ErrNotFound = errors.New("template not found")
+
+// ext is the extension of the template files.
+const ext = ".md"
+
+var placeholder = regexp.MustCompile(`\{\{\s*(date|time|title|prompt:\s*([^}]*?))\s*\}\}`)
+
+// Template is a template of items.
+type Template struct {
+	Name    string
+	Content string
+}
+
+// Values are what the placeholders of a template are replaced with.
+type Values struct {
+	Now     time.Time
+	Title   string
+	Answers map[string]string // by prompt
+}
+
+// List returns the names of the templates in dir, sorted. There are none if dir
+// doesn't exist.
+func List(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || filepath.Ext(name) != ext {
+			continue
+		}
+		names = append(names, strings.TrimSuffix(name, ext))
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Load reads the template of the given name from dir.
+func Load(dir, name string) (Template, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return Template{}, fmt.Errorf("invalid template name %q", name)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, name+ext))
+	if errors.Is(err, fs.ErrNotExist) {
+		return Template{}, fmt.Errorf("%w: %s (in %s)", ErrNotFound, name, dir)
+	}
+	if err != nil {
+		return Template{}, err
+	}
+	return Template{Name: name, Content: string(content)}, nil
+}
+
+// Prompts returns the questions of the {{prompt:...}} placeholders, once each and
+// in the order they appear.
+func (t Template) Prompts() []string {
+	var prompts []string
+	seen := map[string]bool{}
+	for _, match := range placeholder.FindAllStringSubmatch(t.Content, -1) {
+		if !strings.HasPrefix(match[1], "prompt:") || seen[match[2]] {
+			continue
+		}
+		seen[match[2]] = true
+		prompts = append(prompts, match[2])
+	}
+	return prompts
+}
+
+// UsesTitle reports whether the template has a {{title}} placeholder.
+func (t Template) UsesTitle() bool {
+	for _, match := range placeholder.FindAllStringSubmatch(t.Content, -1) {
+		if match[1] == "title" {
+			return true
+		}
+	}
+	return false
+}
+
+// Render returns the content of the template with its placeholders replaced. The
+// frontmatter values with placeholders are quoted, so any title or answer is valid.
+func (t Template) Render(v Values) string {
+	frontmatter, body, ok := splitFrontmatter(t.Content)
+	if !ok {
+		return v.replace(t.Content)
+	}
+	lines := strings.Split(frontmatter, "\n")
+	for i, line := range lines {
+		match := field.FindStringSubmatch(line)
+		if match == nil || !placeholder.MatchString(match[2]) {
+			continue
+		}
+		value := match[2]
+		if unquoted, err := strconv.Unquote(value); err == nil && strings.HasPrefix(value, `"`) {
+			value = unquoted
+		} else if len(value) >= 2 && strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'") {
+			value = strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+		}
+		// JSON strings are valid YAML
+		quoted, _ := json.Marshal(v.replace(value))
+		lines[i] = match[1] + string(quoted)
+	}
+	return strings.Join(lines, "\n") + v.replace(body)
+}
+
+// field matches the "key: value" lines of a frontmatter.
+var field = regexp.MustCompile(`^(\s*[\w-]+:\s*)(.*?)\s*$`)
+
+// splitFrontmatter splits content into its frontmatter, from the opening --- to the
+// line before the closing one, and the rest.
+func splitFrontmatter(content string) (frontmatter, rest string, ok bool) {
+	if !strings.HasPrefix(content, "---\n") && !strings.HasPrefix(content, "---\r\n") {
+		return "", content, false
+	}
+	end := strings.Index(content[3:], "\n---")
+	if end == -1 {
+		return "", content, false
+	}
+	end += 3
+	return content[:end], content[end:], true
+}
+
+// replace replaces the placeholders of s.
+func (v Values) replace(s string) string {
+	return placeholder.ReplaceAllStringFunc(s, func(s string) string {
+		match := placeholder.FindStringSubmatch(s)
+		switch match[1] {
+		case "date":
+			return v.Now.Format(time.DateOnly)
+		case "time":
+			return v.Now.Format("15:04")
+		case "title":
+			return v.Title
+		default:
+			return v.Answers[match[2]]
+		}
+	})
+}
@@ -0,0 +1,232 @@
+package tui
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/markelca/prioritty/internal/config"
+	"github.com/markelca/prioritty/internal/service"
+	"github.com/markelca/prioritty/internal/tui/styles"
+	"github.com/markelca/prioritty/pkg/items"
+	"github.com/spf13/viper"
+)
+
+// agendaScreen is the state of the agenda screen: the sections of the day, with a
+// cursor over their tasks.
+type agendaScreen struct {
+	agenda service.Agenda
+	cursor int
+}
+
+// agendaSection is a titled list of tasks of the agenda. Dates equal to day aren't
+// repeated next to its tasks.
+type agendaSection struct {
+	title string
+	style lipgloss.Style
+	day   time.Time
+	tasks []items.Task
+}
+
+// agendaSections returns the sections of the agenda in the order they're shown, empty
+// ones left out.
+func agendaSections(a service.Agenda) []agendaSection {
+	all := []agendaSection{
+		{"Overdue", styles.Cancelled, a.Date, a.Overdue},
+		{"Due today", styles.Default, a.Date, a.DueToday},
+		{"Scheduled", styles.Default, a.Date, a.ScheduledToday},
+		{"In progress", styles.InProgress, a.Date, a.InProgress},
+	}
+	for i, day := range a.Upcoming {
+		title := day.Date.Format("Monday 02 Jan")
+		if i == 0 {
+			title = "Tomorrow · " + title
+		}
+		all = append(all, agendaSection{title, styles.Secondary, day.Date, day.Tasks})
+	}
+	var sections []agendaSection
+	for _, s := range all {
+		if len(s.tasks) > 0 {
+			sections = append(sections, s)
+		}
+	}
+	return sections
+}
+
+// agendaDays returns the number of upcoming days shown by the agenda.
+func agendaDays() int {
+	return max(0, viper.GetInt(config.CONF_AGENDA_DAYS))
+}
+
+// startAgenda opens the agenda screen, computed from the items listed.
+func (m Model) startAgenda() (Model, tea.Cmd) {
+	m.state.agenda = agendaScreen{}
+	m.refreshAgenda()
+	m.state.Mode = ModeAgenda
+	return m, nil
+}
+
+// refreshAgenda computes the agenda again, after the items changed.
+func (m *Model) refreshAgenda() {
+	var tasks []items.Task
+	for _, item := range m.state.items {
+		if t, ok := item.(*items.Task); ok {
+			tasks = append(tasks, *t)
+		}
+	}
+	a := &m.state.agenda
+	a.agenda = service.ComputeAgenda(tasks, time.Now(), agendaDays())
+	a.cursor = max(0, min(a.cursor, len(a.tasks())-1))
+}
+
+// tasks returns the tasks of the agenda in the order they're shown.
+func (a agendaScreen) tasks() []items.Task {
+	var tasks []items.Task
+	for _, s := range agendaSections(a.agenda) {
+		tasks = append(tasks, s.tasks...)
+	}
+	return tasks
+}
+
+// agendaTask returns the listed task under the cursor of the agenda, nil if empty.
+func (m Model) agendaTask() *items.Task {
+	tasks := m.state.agenda.tasks()
+	if m.state.agenda.cursor >= len(tasks) {
+		return nil
+	}
+	index := m.IndexOf(&tasks[m.state.agenda.cursor])
+	if index == -1 {
+		return nil
+	}
+	t, _ := m.state.items[index].(*items.Task)
+	return t
+}
+
+// updateAgenda handles the keys of the agenda screen: moving, changing the status of
+// the task under the cursor, focusing on it or opening it in the list.
+func (m Model) updateAgenda(msg tea.KeyMsg) (Model, tea.Cmd) {
+	a := &m.state.agenda
+	n := len(a.tasks())
+	task := m.agendaTask()
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "q", "g":
+		m.state.Mode = ModeList
+	case "up", "k":
+		if n > 0 {
+			a.cursor = (a.cursor - 1 + n) % n
+		}
+	case "down", "j":
+		if n > 0 {
+			a.cursor = (a.cursor + 1) % n
+		}
+	case "p", "t", "d", "c":
+		if task != nil {
+			m.handleMutationErr(m.updateStatus(msg, task))
+			m.refreshItems()
+			m.refreshAgenda()
+		}
+	case "f":
+		if task != nil {
+			return m.startFocus(task)
+		}
+	case "enter", "s":
+		if task != nil {
+			m.state.Mode = ModeList
+			m.state.cursor = m.IndexOf(task)
+			m.state.contentView.ready = false
+			m.state.contentView.show(task, m.state.links.Links(task))
+			m.state.contentView.viewport.GotoTop()
+		}
+	}
+	return m, nil
+}
+
+// agendaView renders the agenda screen.
+func (m Model) agendaView() string {
+	view := m.renderAgenda(m.state.agenda.agenda, m.state.agenda.cursor)
+	if m.state.notice != "" {
+		view += "\n  " + styles.Cancelled.Render(m.state.notice) + "\n"
+	}
+	return view + "\n  " + styles.Secondary.Render("↑/↓ move · p/t/d/c status · f focus · enter open · esc back") + "\n"
+}
+
+// AgendaView renders an agenda, numbering its tasks as the list does.
+func (m Model) AgendaView(a service.Agenda) string {
+	return m.renderAgenda(a, -1)
+}
+
+// renderAgenda renders the sections of an agenda, the task at cursor selected.
+func (m Model) renderAgenda(a service.Agenda, cursor int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n  %s\n", styles.Default.Bold(true).Render("Agenda · "+a.Date.Format("Monday 02 January")))
+
+	if a.Len() == 0 {
+		b.WriteString("\n  " + styles.Secondary.Render("Nothing due, scheduled or in progress today") + "\n")
+	}
+
+	line := 0
+	for _, s := range agendaSections(a) {
+		fmt.Fprintf(&b, "\n  %s%s\n", s.style.Underline(true).Render(s.title), styles.Secondary.Render(fmt.Sprintf(" [%d]", len(s.tasks))))
+		for i := range s.tasks {
+			t := &s.tasks[i]
+			selected := " "
+			if line == cursor {
+				selected = ">"
+			}
+			line++
+			b.WriteString("  " + selected + styles.Secondary.Render(fmt.Sprintf(" %2d. ", m.IndexOf(t)+1)))
+			b.WriteString(strings.TrimSuffix(t.Render(m.renderer), "\n"))
+			b.WriteString(agendaHints(t, s.day, a.Date) + "\n")
+		}
+	}
+	if len(a.Upcoming) > 0 && line == a.Len() {
+		b.WriteString("\n  " + styles.Secondary.Render(fmt.Sprintf("Nothing in the next %d days", len(a.Upcoming))) + "\n")
+	}
+	return b.String()
+}
+
+// agendaHints returns the tag of a task and its dates other than day, relative to today.
+func agendaHints(t *items.Task, day, today time.Time) string {
+	var hints []string
+	if t.Tag != nil {
+		hints = append(hints, "@"+t.Tag.Name)
+	}
+	if !t.DueDate.IsZero() && !sameDay(t.DueDate, day) {
+		hints = append(hints, "due "+relativeDay(t.DueDate, today))
+	}
+	if !t.ScheduledDate.IsZero() && !sameDay(t.ScheduledDate, day) {
+		hints = append(hints, "scheduled "+relativeDay(t.ScheduledDate, today))
+	}
+	if len(hints) == 0 {
+		return ""
+	}
+	return " " + styles.Secondary.Render(strings.Join(hints, " · "))
+}
+
+func sameDay(a, b time.Time) bool {
+	return items.FormatDate(a) == items.FormatDate(b)
+}
+
+// relativeDay names a day relative to today: "today", "tomorrow", "in 3 days", "2
+// days ago", or its date when further than a week.
+func relativeDay(day, today time.Time) string {
+	n := int(math.Round(startOfToday(day).Sub(today).Hours() / 24))
+	switch {
+	case n == 0:
+		return "today"
+	case n == 1:
+		return "tomorrow"
+	case n == -1:
+		return "yesterday"
+	case n > 1 && n < 7:
+		return fmt.Sprintf("in %d days", n)
+	case n < -1 && n > -7:
+		return fmt.Sprintf("%d days ago", -n)
+	}
+	return items.FormatDate(day)
+}
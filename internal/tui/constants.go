@@ -7,4 +7,5 @@ const (
 	ExitCodeRepositoryCreate
 	ExitCodeGetItems
 	ExitCodeDestroyDemo
+	ExitCodeHooks
 )
@@ -0,0 +1,305 @@
+package tui
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/markelca/prioritty/internal/config"
+	"github.com/markelca/prioritty/internal/service"
+	"github.com/markelca/prioritty/internal/tui/styles"
+	"github.com/markelca/prioritty/pkg/items"
+	"github.com/spf13/viper"
+)
+
+// focusPhase is a period of a focus session: working on the task or having a break.
+type focusPhase int
+
+const (
+	phaseWork focusPhase = iota
+	phaseBreak
+	phaseLongBreak
+)
+
+func (p focusPhase) String() string {
+	switch p {
+	case phaseBreak:
+		return "Break"
+	case phaseLongBreak:
+		return "Long break"
+	}
+	return "Focus"
+}
+
+// longBreakEvery is the number of pomodoros between long breaks.
+const longBreakEvery = 4
+
+// focusSession is the state of the focus screen: pomodoros of work on a task, with
+// breaks between them.
+type focusSession struct {
+	task      *items.Task
+	id        int // tells the ticks of this session apart from those of previous ones
+	phase     focusPhase
+	ends      time.Time     // end of the phase, while running
+	remaining time.Duration // left of the phase, while paused
+	paused    bool
+	work      []items.TimeEntry // periods of work of the pomodoro, split by the pauses; the last one runs
+	completed int               // pomodoros completed in the session
+	back      Mode              // screen to go back to, the list or the agenda
+}
+
+// focusTickMsg updates the countdown of the focus session id every second.
+type focusTickMsg struct {
+	id int
+	at time.Time
+}
+
+func focusTick(id int) tea.Cmd {
+	return tea.Tick(time.Second, func(at time.Time) tea.Msg {
+		return focusTickMsg{id: id, at: at}
+	})
+}
+
+// left returns the time left of the phase.
+func (f focusSession) left(now time.Time) time.Duration {
+	if f.paused {
+		return f.remaining
+	}
+	return max(0, f.ends.Sub(now))
+}
+
+// toggleWork ends the period of work running, or starts a new one, e.g. when the
+// pomodoro is paused and resumed.
+func (f *focusSession) toggleWork(now time.Time) {
+	if n := len(f.work); n > 0 && f.work[n-1].Running() {
+		f.work[n-1].End = now
+		return
+	}
+	f.work = append(f.work, items.TimeEntry{Start: now})
+}
+
+// phaseLength returns the configured length of a phase.
+func phaseLength(p focusPhase) time.Duration {
+	key, fallback := config.CONF_POMODORO_WORK, 25*time.Minute
+	switch p {
+	case phaseBreak:
+		key, fallback = config.CONF_POMODORO_BREAK, 5*time.Minute
+	case phaseLongBreak:
+		key, fallback = config.CONF_POMODORO_LONG_BREAK, 15*time.Minute
+	}
+	if d := viper.GetDuration(key); d > 0 {
+		return d
+	}
+	return fallback
+}
+
+// startFocus opens the focus screen on a task, moving it to in-progress, and starts
+// the first pomodoro.
+func (m Model) startFocus(item items.ItemInterface) (Model, tea.Cmd) {
+	task, ok := item.(*items.Task)
+	if !ok {
+		m.state.notice = "Only tasks can be focused on"
+		return m, nil
+	}
+	t := *task
+	if err := m.Service.StartFocus(&t); err != nil {
+		m.handleMutationErr(err)
+		if m.state.notice == "" {
+			m.state.notice = err.Error()
+		}
+		return m, nil
+	}
+	m.refreshItems()
+
+	now := time.Now()
+	m.state.focus = focusSession{
+		task:  &t,
+		id:    m.state.focus.id + 1,
+		phase: phaseWork,
+		ends:  now.Add(phaseLength(phaseWork)),
+		work:  []items.TimeEntry{{Start: now}},
+		back:  ModeList,
+	}
+	if m.state.Mode == ModeAgenda {
+		m.state.focus.back = ModeAgenda
+	}
+	m.state.Mode = ModeFocus
+	m.state.contentView.setItem(&t, service.Links{})
+	m.state.contentView.viewport.GotoTop()
+	return m, focusTick(m.state.focus.id)
+}
+
+// updateFocus handles the keys of the focus screen. The others scroll the task body.
+func (m Model) updateFocus(msg tea.KeyMsg) (Model, tea.Cmd) {
+	f := &m.state.focus
+	now := time.Now()
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "q":
+		return m.stopFocus(), nil
+	case " ":
+		if f.paused {
+			f.ends = now.Add(f.remaining)
+		} else {
+			f.remaining = f.left(now)
+		}
+		f.paused = !f.paused
+		if f.phase == phaseWork {
+			f.toggleWork(now)
+		}
+	case "n":
+		// Skipping work doesn't complete a pomodoro
+		m.nextPhase(now, false)
+	case "d":
+		err := m.Service.UpdateStatus(f.task, items.Done)
+		m.handleMutationErr(err)
+		return m.stopFocus(), nil
+	default:
+		var cmd tea.Cmd
+		m.state.contentView.viewport, cmd = m.state.contentView.viewport.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// tickFocus moves the focus session to the next phase when the current one is over.
+func (m Model) tickFocus(msg focusTickMsg) (Model, tea.Cmd) {
+	f := &m.state.focus
+	if m.state.Mode != ModeFocus || msg.id != f.id {
+		// A session that was left
+		return m, nil
+	}
+	if f.left(msg.at) == 0 {
+		m.nextPhase(msg.at, f.phase == phaseWork)
+	}
+	return m, focusTick(f.id)
+}
+
+// nextPhase ends the current phase, recording the pomodoro if the work was completed.
+// Work follows breaks, paused until the user is back.
+func (m *Model) nextPhase(now time.Time, completed bool) {
+	f := &m.state.focus
+	if f.phase != phaseWork {
+		f.phase, f.paused, f.remaining = phaseWork, true, phaseLength(phaseWork)
+		f.work = nil
+		return
+	}
+	if completed {
+		f.toggleWork(now)
+		err := m.Service.RecordPomodoro(f.task, f.work)
+		if err != nil {
+			log.Println("Error recording the pomodoro:", err)
+			m.state.notice = "The pomodoro couldn't be recorded: " + err.Error()
+		}
+		f.completed++
+	}
+	f.phase, f.work = phaseBreak, nil
+	if completed && f.completed%longBreakEvery == 0 {
+		f.phase = phaseLongBreak
+	}
+	f.paused, f.ends = false, now.Add(phaseLength(f.phase))
+}
+
+// stopFocus leaves the focus screen, back to the list or the agenda.
+func (m Model) stopFocus() Model {
+	m.state.Mode = m.state.focus.back
+	m.state.focus.task = nil
+	m.refreshItems()
+	if m.state.Mode == ModeAgenda {
+		m.refreshAgenda()
+	}
+	if item := m.state.GetCurrentItem(); item != nil {
+		m.state.contentView.setItem(item, m.state.links.Links(item))
+	}
+	return m
+}
+
+// focusView renders the focus screen: the countdown, the pomodoros completed and the
+// task body.
+func (m Model) focusView() string {
+	f := m.state.focus
+	now := time.Now()
+	vp := m.state.contentView.viewport
+	width := max(vp.Width, 40)
+
+	title := styles.TitleStyle.Render(GetItemIcon(f.task) + f.task.Title)
+	header := lipgloss.JoinHorizontal(lipgloss.Center, title, strings.Repeat("─", max(0, width-lipgloss.Width(title))))
+
+	style := styles.InProgress
+	if f.phase != phaseWork {
+		style = styles.Done
+	}
+	phase := f.phase.String()
+	if f.paused {
+		style = styles.Secondary
+		phase += " · paused"
+	}
+	left := f.left(now).Round(time.Second)
+	clock := bigClock(fmt.Sprintf("%02d:%02d", int(left/time.Minute), int(left%time.Minute/time.Second)))
+
+	cycle := f.completed % longBreakEvery
+	if f.completed > 0 && cycle == 0 && f.phase == phaseLongBreak {
+		cycle = longBreakEvery
+	}
+	dots := strings.Repeat("●", cycle) + strings.Repeat("○", longBreakEvery-cycle)
+	today := f.task.PomodorosBetween(startOfToday(now), time.Time{})
+	counter := fmt.Sprintf("%s  %d this session · %d today · %s spent",
+		dots, f.completed, today, items.FormatWorkTime(f.task.TimeSpent(now)))
+
+	panel := lipgloss.JoinVertical(lipgloss.Center,
+		"",
+		style.Render(clock),
+		"",
+		style.Bold(true).Render(phase),
+		styles.Secondary.Render(counter),
+		"",
+	)
+	panel = lipgloss.PlaceHorizontal(width, lipgloss.Center, panel)
+
+	vp.Height = max(1, vp.Height-lipgloss.Height(panel))
+	help := styles.Secondary.Render("space pause · n skip · d done · ↑/↓ scroll · esc leave")
+	view := fmt.Sprintf("%s\n%s\n%s\n%s", header, panel, vp.View(), help)
+	if m.state.notice != "" {
+		view += "\n" + styles.Cancelled.Render(m.state.notice)
+	}
+	return view
+}
+
+func startOfToday(now time.Time) time.Time {
+	y, mo, d := now.Date()
+	return time.Date(y, mo, d, 0, 0, 0, 0, now.Location())
+}
+
+// bigDigits are the glyphs of the countdown, 5 lines high.
+var bigDigits = map[rune][5]string{
+	'0': {"█▀▀█", "█  █", "█  █", "█  █", "█▄▄█"},
+	'1': {" ▄█ ", "  █ ", "  █ ", "  █ ", " ▄█▄"},
+	'2': {"▀▀▀█", "   █", "█▀▀▀", "█   ", "█▄▄▄"},
+	'3': {"▀▀▀█", "   █", " ▀▀█", "   █", "▄▄▄█"},
+	'4': {"█  █", "█  █", "▀▀▀█", "   █", "   █"},
+	'5': {"█▀▀▀", "█   ", "▀▀▀█", "   █", "▄▄▄█"},
+	'6': {"█▀▀▀", "█   ", "█▀▀█", "█  █", "█▄▄█"},
+	'7': {"▀▀▀█", "   █", "  █ ", " █  ", " █  "},
+	'8': {"█▀▀█", "█  █", "█▀▀█", "█  █", "█▄▄█"},
+	'9': {"█▀▀█", "█  █", "▀▀▀█", "   █", "▄▄▄█"},
+	':': {" ", "▪", " ", "▪", " "},
+}
+
+// bigClock renders a time as MM:SS in big digits.
+func bigClock(s string) string {
+	var lines [5]string
+	for i, r := range s {
+		glyph := bigDigits[r]
+		for l := range lines {
+			if i > 0 {
+				lines[l] += " "
+			}
+			lines[l] += glyph[l]
+		}
+	}
+	return strings.Join(lines[:], "\n")
+}
@@ -21,6 +21,9 @@ type keyMap struct {
 	Edit       key.Binding
 	Add        key.Binding
 	Remove     key.Binding
+	FollowLink key.Binding
+	Focus      key.Binding
+	Agenda     key.Binding
 }
 
 var keys = keyMap{
@@ -86,6 +89,18 @@ var keys = keyMap{
 		key.WithKeys("r"),
 		key.WithHelp("r", "Remove"),
 	),
+	FollowLink: key.NewBinding(
+		key.WithKeys("1", "2", "3", "4", "5", "6", "7", "8", "9"),
+		key.WithHelp("1-9", "Follow link"),
+	),
+	Focus: key.NewBinding(
+		key.WithKeys("f"),
+		key.WithHelp("f", "Focus"),
+	),
+	Agenda: key.NewBinding(
+		key.WithKeys("g"),
+		key.WithHelp("g", "Agenda"),
+	),
 }
 
 // ShortHelp returns keybindings to be shown in the mini help view. It's part
@@ -100,7 +115,7 @@ func (k keyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Up, k.Down, k.Left, k.Right}, // first column
 		{k.InProgress, k.ToDo, k.Done, k.Cancelled},
-		{k.Show, k.Edit, k.Add, k.Remove},
+		{k.Show, k.Edit, k.Add, k.Remove, k.FollowLink, k.Focus, k.Agenda},
 		{k.Help, k.Quit}, // second column
 	}
 }
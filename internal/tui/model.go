@@ -1,48 +1,30 @@
 package tui
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"os"
 
 	"github.com/charmbracelet/bubbles/help"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/markelca/prioritty/internal/config"
+	"github.com/markelca/prioritty/internal/hooks"
+	jsonMigrations "github.com/markelca/prioritty/internal/migrations/jsonfile"
 	obsidianMigrations "github.com/markelca/prioritty/internal/migrations/obsidian"
 	sqliteMigrations "github.com/markelca/prioritty/internal/migrations/sqlite"
+	todotxtMigrations "github.com/markelca/prioritty/internal/migrations/todotxt"
+	"github.com/markelca/prioritty/internal/notify"
 	"github.com/markelca/prioritty/internal/render"
 	"github.com/markelca/prioritty/internal/service"
 	"github.com/markelca/prioritty/pkg/items"
 	"github.com/markelca/prioritty/pkg/items/repository"
+	"github.com/markelca/prioritty/pkg/items/repository/remote"
 	"github.com/spf13/viper"
 )
 
 var Help = help.New()
 
-// sortItemsByTag groups items by tag in the order tags first appear.
-// Items without tags come first (under "My Board").
-func sortItemsByTag(itemList []items.ItemInterface) []items.ItemInterface {
-	var result []items.ItemInterface
-	tagOrder := []string{}
-	itemsByTag := make(map[string][]items.ItemInterface)
-
-	for _, item := range itemList {
-		var tagKey string
-		if tag := item.GetTag(); tag != nil {
-			tagKey = tag.Name
-		}
-
-		if _, exists := itemsByTag[tagKey]; !exists {
-			tagOrder = append(tagOrder, tagKey)
-		}
-		itemsByTag[tagKey] = append(itemsByTag[tagKey], item)
-	}
-
-	for _, tagKey := range tagOrder {
-		result = append(result, itemsByTag[tagKey]...)
-	}
-	return result
-}
-
 // Mode represents the current operation mode of the TUI
 type Mode string
 
@@ -51,6 +33,9 @@ const (
 	ModeCreate        Mode = "create"         // creating a new item
 	ModeEdit          Mode = "edit"           // editing an existing item
 	ModeDeleteConfirm Mode = "delete_confirm" // confirming item deletion
+	ModeTemplate      Mode = "template"       // picking the template of a new item
+	ModeFocus         Mode = "focus"          // working on a task in pomodoros
+	ModeAgenda        Mode = "agenda"         // browsing the agenda of the day
 )
 
 // Params controls the behavior of the TUI model
@@ -70,43 +55,112 @@ func InitialModel(isTUI bool) Model {
 	isDemo := viper.GetBool("demo")
 	repoType := viper.GetString(config.CONF_REPOSITORY_TYPE)
 
-	var repo repository.Repository
 	dbPath, err := repository.GetDatabasePath(repoType, isDemo)
 	if err != nil {
 		log.Printf("Error - %s:", err)
 		os.Exit(ExitCodeRepositoryNotSupported)
 	}
 
-	switch repoType {
-	case repository.RepoTypeObsidian:
-		repo, err = obsidianMigrations.NewObsidianRepository(dbPath)
-	case repository.RepoTypeSQLite:
-		repo, err = sqliteMigrations.NewSQLiteRepository(dbPath)
-	default:
-		log.Println("Error - Repository type not supported: ", repoType)
+	repo, err := connectOrOpenRepository(repoType, dbPath)
+	if errors.Is(err, ErrRepositoryNotSupported) {
+		log.Println("Error -", err)
 		os.Exit(ExitCodeRepositoryNotSupported)
 	}
-
 	if err != nil {
 		log.Println("Error - Failed to create repository:", err)
 		os.Exit(ExitCodeRepositoryCreate)
 	}
 
 	service := service.NewService(repo)
+	subscribeNotifySinks(service)
+	if scripts, err := hooks.Load(repository.ExpandTilde(viper.GetString(config.CONF_HOOKS_PATH))); err != nil {
+		log.Println("Error - Failed to load the hooks:", err)
+		os.Exit(ExitCodeHooks)
+	} else if scripts != nil {
+		service = service.WithHooks(scripts)
+	}
 
-	itemList, err := service.GetAll()
+	m := Model{
+		state:    State{contentView: ItemContent{}},
+		params:   Params{IsTUI: isTUI},
+		Service:  service,
+		renderer: render.CLI{},
+	}
+	// Commands find their items by index, without loading them all (e.g. from the daemon)
+	if isTUI {
+		m.LoadItems()
+	}
+	return m
+}
+
+// LoadItems loads the item list, needed to show it. Until then, GetItemAt asks the
+// service for each item.
+func (m *Model) LoadItems() {
+	itemList, err := m.Service.List()
 	if err != nil {
 		log.Println("Error - Failed to get the tasks:", err)
 		os.Exit(ExitCodeGetItems)
 	}
-	itemList = sortItemsByTag(itemList)
+	m.setItems(itemList)
+}
 
-	return Model{
-		state:    State{contentView: ItemContent{}, items: itemList},
-		params:   Params{IsTUI: isTUI},
-		Service:  service,
-		renderer: render.CLI{},
+// subscribeNotifySinks sends the events of the service to the sinks configured in
+// notify. An invalid configuration is logged, and nothing is sent.
+func subscribeNotifySinks(s service.Service) {
+	var cfg []config.NotifySink
+	if err := viper.UnmarshalKey(config.CONF_NOTIFY, &cfg); err != nil {
+		log.Println("Error - Invalid notify configuration:", err)
+		return
+	}
+	sinks, err := notify.New(cfg)
+	if err != nil {
+		log.Println("Error - Invalid notify configuration:", err)
+		return
+	}
+	for _, sink := range sinks {
+		s.Subscribe(sink)
+	}
+}
+
+// ErrRepositoryNotSupported is returned by OpenRepository for unknown repository types.
+var ErrRepositoryNotSupported = errors.New("repository type not supported")
+
+// OpenRepository opens the repository of the given type stored at dbPath,
+// creating and migrating it if needed.
+func OpenRepository(repoType, dbPath string) (repository.Repository, error) {
+	var repo repository.Repository
+	var err error
+	switch repoType {
+	case repository.RepoTypeObsidian:
+		repo, err = obsidianMigrations.NewObsidianRepository(dbPath)
+	case repository.RepoTypeSQLite:
+		repo, err = sqliteMigrations.NewSQLiteRepository(dbPath)
+	case repository.RepoTypeJSON:
+		repo, err = jsonMigrations.NewJSONRepository(dbPath)
+	case repository.RepoTypeTodoTxt:
+		repo, err = todotxtMigrations.NewTodoTxtRepository(dbPath)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrRepositoryNotSupported, repoType)
+	}
+	if err != nil {
+		return nil, err
 	}
+	return repo, nil
+}
+
+// connectOrOpenRepository returns a client of the daemon (pt daemon) when it's running
+// and serves the repository, or opens the repository directly.
+func connectOrOpenRepository(repoType, dbPath string) (repository.Repository, error) {
+	if socketPath := repository.ExpandTilde(viper.GetString(config.CONF_DAEMON_SOCKET_PATH)); socketPath != "" {
+		repo, err := remote.Open(socketPath, repoType, dbPath)
+		if err == nil {
+			return repo, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Printf("Not using the daemon: %v", err)
+		}
+	}
+	return OpenRepository(repoType, dbPath)
 }
 
 func (m Model) Init() tea.Cmd {
@@ -114,13 +168,49 @@ func (m Model) Init() tea.Cmd {
 	return m.initCmd
 }
 
+// GetItemAt returns the item at index, 0-based, in the list, or nil.
 func (m Model) GetItemAt(index int) items.ItemInterface {
+	if !m.state.loaded {
+		item, err := m.Service.ItemAt(index)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			log.Println("Error getting the item:", err)
+		}
+		return item
+	}
 	if index < 0 || index >= len(m.state.items) {
 		return nil
 	}
 	return m.state.items[index]
 }
 
+// setItems replaces the item list, indexing the links between the items.
+func (m *Model) setItems(list []items.ItemInterface) {
+	m.state.items = list
+	m.state.loaded = true
+	m.state.links = service.NewLinkIndex(list)
+}
+
+// Items returns the item list, empty until loaded by LoadItems.
+func (m Model) Items() []items.ItemInterface {
+	return m.state.items
+}
+
+// IndexOf returns the position of the item in the list, or -1 if it isn't listed.
+func (m Model) IndexOf(item items.ItemInterface) int {
+	for index, i := range m.state.items {
+		if i.GetId() == item.GetId() && sameType(i, item) {
+			return index
+		}
+	}
+	return -1
+}
+
+func sameType(a, b items.ItemInterface) bool {
+	_, aIsTask := a.(*items.Task)
+	_, bIsTask := b.(*items.Task)
+	return aIsTask == bIsTask
+}
+
 func (m Model) DestroyDemo() {
 	err := m.Service.DestroyDemo()
 	if err != nil {
@@ -131,12 +221,29 @@ func (m Model) DestroyDemo() {
 
 // refreshItems reloads the item list from the service.
 func (m *Model) refreshItems() {
-	itemList, err := m.Service.GetAll()
+	itemList, err := m.Service.List()
 	if err != nil {
 		log.Println("Error refreshing items:", err)
 		return
 	}
-	m.state.items = sortItemsByTag(itemList)
+	m.setItems(itemList)
+}
+
+// handleMutationErr reports a failed mutation. Conflicts with changes made by
+// another process are shown to the user and the list is reloaded, so the
+// latest stored version is displayed instead of being overwritten.
+func (m *Model) handleMutationErr(err error) {
+	if err == nil {
+		return
+	}
+	log.Println("Error updating item:", err)
+	if errors.Is(err, repository.ErrConflict) {
+		m.state.notice = "Item was modified elsewhere, reloaded the latest version"
+		m.refreshItems()
+		if m.state.cursor >= len(m.state.items) && m.state.cursor > 0 {
+			m.state.cursor = len(m.state.items) - 1
+		}
+	}
 }
 
 // CreateModel returns a model configured for CLI item creation
@@ -156,7 +263,7 @@ func CreateModel(itemType items.ItemType) Model {
 // EditModel returns a model configured for CLI item editing
 func EditModel(item items.ItemInterface) Model {
 	m := InitialModel(false)
-	m.state.items = []items.ItemInterface{item}
+	m.setItems([]items.ItemInterface{item})
 	m.state.cursor = 0
 	m.state.Mode = ModeEdit
 	cmd, err := m.Service.EditWithEditor(item)
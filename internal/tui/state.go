@@ -1,23 +1,34 @@
 package tui
 
 import (
+	"fmt"
+
 	"github.com/charmbracelet/bubbles/viewport"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/markelca/prioritty/internal/service"
+	"github.com/markelca/prioritty/internal/tui/styles"
 	"github.com/markelca/prioritty/pkg/items"
 )
 
 type State struct {
 	cursor        int
 	items         []items.ItemInterface
-	contentView   ItemContent            // viewport for displaying item details
-	Mode          Mode                   // current operation mode
-	pendingDelete items.ItemInterface    // item awaiting deletion confirmation
+	loaded        bool                // items holds the list, see Model.LoadItems
+	links         *service.LinkIndex  // links between the items, indexed with the list
+	contentView   ItemContent         // viewport for displaying item details
+	Mode          Mode                // current operation mode
+	pendingDelete items.ItemInterface // item awaiting deletion confirmation
+	notice        string              // message shown until the next key press
+	templateForm  templateForm        // template of the item being added
+	focus         focusSession        // pomodoros of the focus screen
+	agenda        agendaScreen        // sections of the agenda screen
 }
 
 type ItemContent struct {
 	content  string
 	ready    bool
 	viewport viewport.Model
+	links    []items.ItemInterface // linked items of the shown item, numbered for the follow link keys
 }
 
 type ItemContentDimensions struct {
@@ -57,15 +68,39 @@ func (itemContent *ItemContent) init(dimensions ItemContentDimensions) {
 
 }
 
-func (content *ItemContent) show(item items.ItemInterface) {
-	style := lipgloss.NewStyle().Width(content.viewport.Width)
+func (content *ItemContent) show(item items.ItemInterface, links service.Links) {
 	if content.ready {
 		content.ready = false
 	} else {
-		body := item.GetBody()
-		contentStr := style.Render(body)
-		content.viewport.SetContent(contentStr)
+		content.setItem(item, links)
 		content.ready = true
 	}
 
 }
+
+// setItem renders the item body followed by its outgoing links and backlinks.
+func (content *ItemContent) setItem(item items.ItemInterface, links service.Links) {
+	style := lipgloss.NewStyle().Width(content.viewport.Width)
+	contentStr := style.Render(item.GetBody())
+
+	content.links = nil
+	for _, section := range []struct {
+		header string
+		items  []items.ItemInterface
+	}{
+		{"Links", links.Outgoing},
+		{"Backlinks", links.Backlinks},
+	} {
+		if len(section.items) == 0 {
+			continue
+		}
+		contentStr += "\n\n" + styles.Default.Underline(true).Render(section.header) + "\n"
+		for _, linked := range section.items {
+			content.links = append(content.links, linked)
+			contentStr += styles.Secondary.Render(fmt.Sprintf("[%d] ", len(content.links))) +
+				GetItemIcon(linked) + linked.GetTitle() + "\n"
+		}
+	}
+
+	content.viewport.SetContent(contentStr)
+}
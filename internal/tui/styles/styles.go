@@ -48,6 +48,10 @@ var (
 			PaddingRight(1).
 			String()
 
+	TimerIcon = InProgress.SetString("⏱").
+			PaddingLeft(1).
+			String()
+
 	TitleStyle = func() lipgloss.Style {
 		b := lipgloss.RoundedBorder()
 		b.Right = "├"
@@ -67,6 +71,11 @@ var (
 				MarginTop(1)
 
 	DeleteDialogTitleStyle = Cancelled.Bold(true)
+
+	TemplateDialogStyle = DeleteDialogStyle.
+				BorderForeground(lipgloss.Color("#7aa0df"))
+
+	TemplateDialogTitleStyle = InProgress.Bold(true)
 )
 
 func RenderDeleteDialog(itemTitle string) string {
@@ -84,3 +93,41 @@ func RenderDeleteDialog(itemTitle string) string {
 
 	return DeleteDialogStyle.Render(dialog)
 }
+
+// RenderTemplatePicker renders the list of templates for a new item, "" standing for
+// an empty item.
+func RenderTemplatePicker(names []string, cursor int) string {
+	dialog := TemplateDialogTitleStyle.Render("New item from template") + "\n\n"
+	for i, name := range names {
+		if name == "" {
+			name = "(empty)"
+		}
+		if i == cursor {
+			dialog += InProgress.Render("> "+name) + "\n"
+		} else {
+			dialog += Default.Render("  "+name) + "\n"
+		}
+	}
+	dialog += "\n" +
+		Secondary.Render("Press ") +
+		Done.Render("enter") +
+		Secondary.Render(" to choose, ") +
+		Cancelled.Render("esc") +
+		Secondary.Render(" to cancel")
+
+	return TemplateDialogStyle.Render(dialog)
+}
+
+// RenderTemplatePrompt renders a question of a template, with the input answering it.
+func RenderTemplatePrompt(templateName, prompt, input string) string {
+	dialog := TemplateDialogTitleStyle.Render(templateName) + "\n\n" +
+		Default.Render(prompt) + "\n" +
+		input + "\n\n" +
+		Secondary.Render("Press ") +
+		Done.Render("enter") +
+		Secondary.Render(" to continue, ") +
+		Cancelled.Render("esc") +
+		Secondary.Render(" to cancel")
+
+	return TemplateDialogStyle.Render(dialog)
+}
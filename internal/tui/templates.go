@@ -0,0 +1,178 @@
+package tui
+
+import (
+	"log"
+	"slices"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/markelca/prioritty/internal/config"
+	"github.com/markelca/prioritty/internal/templates"
+	"github.com/markelca/prioritty/pkg/items"
+	"github.com/markelca/prioritty/pkg/items/repository"
+	"github.com/spf13/viper"
+)
+
+// TemplateNames returns the names of the item templates, sorted.
+func TemplateNames() ([]string, error) {
+	return templates.List(templatesDir())
+}
+
+// LoadTemplate loads an item template by name.
+func LoadTemplate(name string) (templates.Template, error) {
+	return templates.Load(templatesDir(), name)
+}
+
+// DefaultTemplate returns the name of the template configured for new items of the
+// type, empty if there's none.
+func DefaultTemplate(itemType items.ItemType) string {
+	switch itemType {
+	case items.ItemTypeTask:
+		return viper.GetString(config.CONF_TASK_TEMPLATE)
+	case items.ItemTypeNote:
+		return viper.GetString(config.CONF_NOTE_TEMPLATE)
+	}
+	return ""
+}
+
+func templatesDir() string {
+	return repository.ExpandTilde(viper.GetString(config.CONF_TEMPLATES_PATH))
+}
+
+// CreateModelFromTemplate creates a Model for creating an item from a rendered template
+// in the editor.
+func CreateModelFromTemplate(itemType items.ItemType, content string) Model {
+	m := InitialModel(false)
+	m.state.Mode = ModeCreate
+	cmd, err := m.Service.AddWithTemplate(itemType, content)
+	if err != nil {
+		log.Println("Error opening editor:", err)
+		m.initCmd = tea.Quit
+	} else {
+		m.initCmd = cmd
+	}
+	return m
+}
+
+// templateForm picks the template of a new item, then asks the questions of its
+// prompts one at a time.
+type templateForm struct {
+	names    []string // "" first, for an empty item
+	cursor   int
+	template *templates.Template // nil while picking
+	prompts  []string
+	answers  map[string]string
+	input    textinput.Model
+}
+
+// newTemplateForm returns a form picking one of the templates, the default one for
+// tasks preselected.
+func newTemplateForm(names []string) templateForm {
+	f := templateForm{names: append([]string{""}, names...)}
+	if i := slices.Index(f.names, DefaultTemplate(items.ItemTypeTask)); i > 0 {
+		f.cursor = i
+	}
+	return f
+}
+
+// prompt returns the question being asked.
+func (f templateForm) prompt() string {
+	return f.prompts[len(f.answers)]
+}
+
+// startAdd shows the template picker when there are templates, otherwise it opens
+// the editor with an empty task.
+func (m Model) startAdd() (Model, tea.Cmd) {
+	names, err := TemplateNames()
+	if err != nil {
+		log.Println("Error listing the templates:", err)
+	}
+	if len(names) > 0 {
+		m.state.templateForm = newTemplateForm(names)
+		m.state.Mode = ModeTemplate
+		return m, nil
+	}
+	return m.openAddEditor(nil)
+}
+
+// openAddEditor opens the editor for a new task, from the rendered template if any.
+func (m Model) openAddEditor(content *string) (Model, tea.Cmd) {
+	m.state.Mode = ModeCreate
+	var cmd tea.Cmd
+	var err error
+	if content != nil {
+		cmd, err = m.Service.AddWithTemplate(items.ItemTypeTask, *content)
+	} else {
+		cmd, err = m.Service.AddWithEditor(items.ItemTypeTask)
+	}
+	if err != nil {
+		log.Println(err)
+		m.state.notice = err.Error()
+		m.state.Mode = ModeList
+	}
+	return m, cmd
+}
+
+// updateTemplateForm handles the keys while picking a template or answering its prompts.
+func (m Model) updateTemplateForm(msg tea.KeyMsg) (Model, tea.Cmd) {
+	f := &m.state.templateForm
+	if msg.String() == "esc" {
+		m.state.templateForm = templateForm{}
+		m.state.Mode = ModeList
+		return m, nil
+	}
+
+	// Answering the prompts
+	if f.template != nil {
+		if msg.String() != "enter" {
+			var cmd tea.Cmd
+			f.input, cmd = f.input.Update(msg)
+			return m, cmd
+		}
+		f.answers[f.prompt()] = f.input.Value()
+		f.input.SetValue("")
+		if len(f.answers) < len(f.prompts) {
+			return m, nil
+		}
+		return m.finishTemplateForm()
+	}
+
+	// Picking the template
+	switch msg.String() {
+	case "up", "k":
+		f.cursor = (f.cursor - 1 + len(f.names)) % len(f.names)
+	case "down", "j":
+		f.cursor = (f.cursor + 1) % len(f.names)
+	case "enter":
+		name := f.names[f.cursor]
+		if name == "" {
+			m.state.templateForm = templateForm{}
+			return m.openAddEditor(nil)
+		}
+		t, err := LoadTemplate(name)
+		if err != nil {
+			log.Println("Error loading the template:", err)
+			m.state.notice = err.Error()
+			m.state.templateForm = templateForm{}
+			m.state.Mode = ModeList
+			return m, nil
+		}
+		f.template, f.prompts, f.answers = &t, t.Prompts(), map[string]string{}
+		if len(f.prompts) == 0 {
+			return m.finishTemplateForm()
+		}
+		f.input = textinput.New()
+		f.input.Focus()
+		return m, textinput.Blink
+	}
+	return m, nil
+}
+
+// finishTemplateForm opens the editor with the chosen template, rendered.
+func (m Model) finishTemplateForm() (Model, tea.Cmd) {
+	f := m.state.templateForm
+	content := f.template.Render(templates.Values{Now: time.Now(), Answers: f.answers})
+	m.state.templateForm = templateForm{}
+	return m.openAddEditor(&content)
+}
@@ -7,8 +7,10 @@ import (
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/markelca/prioritty/internal/config"
 	"github.com/markelca/prioritty/internal/editor"
 	"github.com/markelca/prioritty/pkg/items"
+	"github.com/spf13/viper"
 )
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -22,15 +24,15 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 
 	case tea.KeyMsg:
+		m.state.notice = ""
+
 		// Handle delete confirmation mode separately
 		if m.state.Mode == ModeDeleteConfirm {
 			switch msg.String() {
 			case "y", "Y":
 				if m.state.pendingDelete != nil {
 					err := m.Service.RemoveItem(m.state.pendingDelete)
-					if err != nil {
-						log.Println("Error deleting item:", err)
-					}
+					m.handleMutationErr(err)
 					m.refreshItems()
 					// Adjust cursor if needed
 					if m.state.cursor >= len(m.state.items) && m.state.cursor > 0 {
@@ -49,6 +51,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
+		if m.state.Mode == ModeTemplate {
+			return m.updateTemplateForm(msg)
+		}
+
+		if m.state.Mode == ModeFocus {
+			return m.updateFocus(msg)
+		}
+
+		if m.state.Mode == ModeAgenda {
+			return m.updateAgenda(msg)
+		}
+
 		switch {
 
 		case key.Matches(msg, keys.Help):
@@ -75,10 +89,16 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			key.Matches(msg, keys.ToDo),
 			key.Matches(msg, keys.Done),
 			key.Matches(msg, keys.Cancelled):
-			m.updateStatus(msg, item)
+			m.handleMutationErr(m.updateStatus(msg, item))
 
 		case key.Matches(msg, keys.Show):
-			m.state.contentView.show(item)
+			if item != nil {
+				m.state.contentView.show(item, m.state.links.Links(item))
+			}
+		case key.Matches(msg, keys.FollowLink):
+			if m.state.contentView.ready {
+				m.followLink(int(msg.String()[0] - '1'))
+			}
 		case key.Matches(msg, keys.Edit):
 			m.state.Mode = ModeEdit
 			cmd, err := m.Service.EditWithEditor(item)
@@ -87,12 +107,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, cmd
 		case key.Matches(msg, keys.Add):
-			m.state.Mode = ModeCreate
-			cmd, err := m.Service.AddWithEditor(items.ItemTypeTask)
-			if err != nil {
-				log.Println(err)
-			}
-			return m, cmd
+			return m.startAdd()
+		case key.Matches(msg, keys.Focus):
+			return m.startFocus(item)
+		case key.Matches(msg, keys.Agenda):
+			return m.startAgenda()
 		case key.Matches(msg, keys.Remove):
 			if item != nil {
 				m.state.pendingDelete = item
@@ -108,6 +127,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			footerHeight: lipgloss.Height(m.footerView()),
 		})
 
+	case focusTickMsg:
+		return m.tickFocus(msg)
+
 	case editor.EditorFinishedMsg:
 		// Check if the editor operation was cancelled (no content)
 		if msg.Err != nil {
@@ -136,9 +158,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Update the existing item
 			item := m.state.GetCurrentItem()
 			err := m.Service.UpdateItemFromEditorMsg(item, msg)
-			if err != nil {
-				log.Println("Error updating item:", err)
-			}
+			m.handleMutationErr(err)
 		}
 
 		// After action: quit (CLI) or return to list (TUI)
@@ -155,12 +175,16 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	// return m, nil
 	m.state.contentView.viewport, cmd = m.state.contentView.viewport.Update(msg)
 	cmds = append(cmds, cmd)
+	if m.state.Mode == ModeTemplate && m.state.templateForm.template != nil {
+		// Keep the cursor of the prompt input blinking
+		m.state.templateForm.input, cmd = m.state.templateForm.input.Update(msg)
+		cmds = append(cmds, cmd)
+	}
 
 	return m, tea.Batch(cmds...)
 }
 
 func (m *Model) move(msg tea.KeyMsg) {
-	style := lipgloss.NewStyle().Width(m.state.contentView.viewport.Width)
 	switch {
 	case key.Matches(msg, keys.Up):
 		if m.state.cursor == 0 {
@@ -176,8 +200,26 @@ func (m *Model) move(msg tea.KeyMsg) {
 		}
 	}
 	item := m.state.GetCurrentItem()
-	content := style.Render(item.GetBody())
-	m.state.contentView.viewport.SetContent(content)
+	if item == nil {
+		return
+	}
+	m.state.contentView.setItem(item, m.state.links.Links(item))
+}
+
+// followLink moves the cursor to the n-th (0-based) linked item of the detail view and shows it.
+func (m *Model) followLink(n int) {
+	links := m.state.contentView.links
+	if n < 0 || n >= len(links) {
+		return
+	}
+	index := m.IndexOf(links[n])
+	if index == -1 {
+		return
+	}
+	m.state.cursor = index
+	item := m.state.GetCurrentItem()
+	m.state.contentView.setItem(item, m.state.links.Links(item))
+	m.state.contentView.viewport.GotoTop()
 }
 
 func (m *Model) updateStatus(msg tea.KeyMsg, item items.ItemInterface) error {
@@ -193,6 +235,12 @@ func (m *Model) updateStatus(msg tea.KeyMsg, item items.ItemInterface) error {
 
 	switch {
 	case key.Matches(msg, keys.InProgress):
+		if viper.GetBool(config.CONF_TIMER_ON_START) && task.Status != items.InProgress {
+			err := m.Service.StartTimer(task)
+			// The timer of another task may have stopped
+			m.refreshItems()
+			return err
+		}
 		s = items.InProgress
 	case key.Matches(msg, keys.ToDo):
 		s = items.Todo
@@ -204,6 +252,5 @@ func (m *Model) updateStatus(msg tea.KeyMsg, item items.ItemInterface) error {
 		return fmt.Errorf("Error - the message cannot be mapped to a task status (%v)", task)
 	}
 
-	m.Service.UpdateStatus(task, s)
-	return nil
+	return m.Service.UpdateStatus(task, s)
 }
@@ -17,6 +17,13 @@ var taskIcons = map[items.Status]string{
 }
 
 func (m Model) View() string {
+	if m.state.Mode == ModeFocus {
+		return m.focusView()
+	}
+	if m.state.Mode == ModeAgenda {
+		return m.agendaView()
+	}
+
 	view := ""
 	counts := make(map[items.Status]int)
 
@@ -28,12 +35,13 @@ func (m Model) View() string {
 				SetString(Help.View(keys)).
 				Render()
 		}
-		return view
+		return view + m.dialogView()
 	}
 
 	// Track current tag to print headers when it changes
 	var currentTag *string
 	tagStats := make(map[string]struct{ completed, total int })
+	tagTasks := make(map[string][]*items.Task)
 
 	// First pass: calculate stats per tag
 	for _, item := range m.state.items {
@@ -48,6 +56,7 @@ func (m Model) View() string {
 				stats.completed++
 			}
 			tagStats[tagKey] = stats
+			tagTasks[tagKey] = append(tagTasks[tagKey], task)
 		}
 	}
 
@@ -73,6 +82,13 @@ func (m Model) View() string {
 			if stats := tagStats[tagKey]; stats.total > 0 {
 				view += styles.Secondary.Render(fmt.Sprintf(" [%d/%d]", stats.completed, stats.total))
 			}
+			if remaining, total := items.RemainingEstimate(tagTasks[tagKey]); !total.IsZero() {
+				left := remaining.String()
+				if remaining.IsZero() {
+					left = "nothing"
+				}
+				view += styles.Secondary.Render(fmt.Sprintf(" · %s of %s left", left, total))
+			}
 
 			view += "\n"
 		}
@@ -108,6 +124,10 @@ func (m Model) View() string {
 	view += renderSummary(counts)
 	view += renderContentCount(m.state.items)
 
+	if m.state.notice != "" {
+		view += "\n  " + styles.Cancelled.Render(m.state.notice) + "\n"
+	}
+
 	if m.params.IsTUI {
 		view += styles.Default.
 			MarginTop(1).
@@ -119,14 +139,30 @@ func (m Model) View() string {
 		view = fmt.Sprintf("%s\n%s\n%s", m.headerView(), m.state.contentView.viewport.View(), m.footerView())
 	}
 
-	// Show delete confirmation dialog
-	if m.state.Mode == ModeDeleteConfirm && m.state.pendingDelete != nil {
-		view += "\n" + styles.RenderDeleteDialog(m.state.pendingDelete.GetTitle())
-	}
+	view += m.dialogView()
 
 	return view
 }
 
+// dialogView renders the dialog of the current mode, if any.
+func (m Model) dialogView() string {
+	switch m.state.Mode {
+	case ModeDeleteConfirm:
+		// Show delete confirmation dialog
+		if m.state.pendingDelete != nil {
+			return "\n" + styles.RenderDeleteDialog(m.state.pendingDelete.GetTitle())
+		}
+	case ModeTemplate:
+		// Show the template picker, or the question being asked
+		f := m.state.templateForm
+		if f.template == nil {
+			return "\n" + styles.RenderTemplatePicker(f.names, f.cursor)
+		}
+		return "\n" + styles.RenderTemplatePrompt(f.template.Name, f.prompt(), f.input.View())
+	}
+	return ""
+}
+
 func renderDonePercentage(taskList []items.ItemInterface, counts map[items.Status]int) string {
 	var taskCount int
 	for _, t := range taskList {
@@ -0,0 +1,86 @@
+// Package backup reads and writes backups: all items and tags of a repository in
+// a single JSON file that any storage backend can be restored from.
+package backup
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/markelca/prioritty/pkg/items/repository/jsonfile"
+	"github.com/markelca/prioritty/pkg/items/repository/memory"
+)
+
+// formatName identifies backup files.
+const formatName = "prioritty-backup"
+
+// formatVersion is the version of the backup format, bumped on incompatible changes.
+const formatVersion = 1
+
+// Archive is the contents of a backup.
+type Archive struct {
+	CreatedAt time.Time
+	Source    string // repository type the backup was made from, e.g. "sqlite"
+	Data      memory.Data
+}
+
+// document is the layout of a backup file. Items and tags are stored like in
+// the JSON repository backend.
+type document struct {
+	Format    string          `json:"format"`
+	Version   int             `json:"version"`
+	CreatedAt string          `json:"created_at"`
+	Source    string          `json:"source,omitempty"`
+	Items     json.RawMessage `json:"items"`
+}
+
+// Encode writes the archive.
+func Encode(w io.Writer, a Archive) error {
+	content, err := jsonfile.Encode(a.Data)
+	if err != nil {
+		return err
+	}
+	doc := document{
+		Format:    formatName,
+		Version:   formatVersion,
+		CreatedAt: a.CreatedAt.UTC().Format(time.RFC3339),
+		Source:    a.Source,
+		Items:     content,
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// Decode reads an archive, failing if it isn't a backup or was written by a newer version.
+func Decode(r io.Reader) (Archive, error) {
+	var doc document
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return Archive{}, fmt.Errorf("invalid backup: %w", err)
+	}
+	if doc.Format != formatName {
+		return Archive{}, fmt.Errorf("not a prioritty backup")
+	}
+	if doc.Version > formatVersion {
+		return Archive{}, fmt.Errorf("unsupported backup version %d", doc.Version)
+	}
+
+	createdAt, err := time.Parse(time.RFC3339, doc.CreatedAt)
+	if err != nil {
+		return Archive{}, fmt.Errorf("invalid backup created_at: %w", err)
+	}
+	data, err := jsonfile.Decode(doc.Items)
+	if err != nil {
+		return Archive{}, err
+	}
+	return Archive{CreatedAt: createdAt, Source: doc.Source, Data: data}, nil
+}
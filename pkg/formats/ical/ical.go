@@ -0,0 +1,406 @@
+// Package ical converts tasks from and to iCalendar (RFC 5545) VTODO components,
+// the format calendar apps use for to-dos.
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/markelca/prioritty/pkg/items"
+	"github.com/markelca/prioritty/pkg/items/repository/memory"
+)
+
+const (
+	dateLayout     = "20060102"
+	dateTimeLayout = "20060102T150405"
+	prodID         = "-//prioritty//prioritty//EN"
+	maxLineOctets  = 75
+)
+
+// statuses maps task statuses to VTODO STATUS values.
+var statuses = map[items.Status]string{
+	items.Todo:       "NEEDS-ACTION",
+	items.InProgress: "IN-PROCESS",
+	items.Done:       "COMPLETED",
+	items.Cancelled:  "CANCELLED",
+}
+
+// priorities maps task priorities to PRIORITY values, 1 being the highest.
+var priorities = map[items.Priority]int{
+	items.PriorityHighest: 1,
+	items.PriorityHigh:    3,
+	items.PriorityMedium:  5,
+	items.PriorityLow:     7,
+	items.PriorityLowest:  9,
+}
+
+// frequencies maps recurrence units to RRULE frequencies.
+var frequencies = map[string]string{
+	"day":   "DAILY",
+	"week":  "WEEKLY",
+	"month": "MONTHLY",
+	"year":  "YEARLY",
+}
+
+// property is a content line such as "DUE;VALUE=DATE:20250701".
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Encode writes the tasks of data as a calendar of VTODO components. Notes are
+// left out. The UID of each to-do comes from items.StableUID, so calendar apps
+// update the same to-dos when a later export is imported.
+func Encode(w io.Writer, data memory.Data) error {
+	bw := bufio.NewWriter(w)
+	writeLine(bw, "BEGIN:VCALENDAR")
+	writeLine(bw, "VERSION:2.0")
+	writeLine(bw, "PRODID:"+prodID)
+	for _, t := range data.Tasks {
+		writeTodo(bw, t)
+	}
+	writeLine(bw, "END:VCALENDAR")
+	return bw.Flush()
+}
+
+func writeTodo(w *bufio.Writer, t items.Task) {
+	stamp := t.CreatedAt
+	if stamp.IsZero() {
+		stamp = time.Now()
+	}
+
+	writeLine(w, "BEGIN:VTODO")
+	writeLine(w, "UID:"+escapeText(items.StableUID(&t)))
+	writeLine(w, "DTSTAMP:"+formatDateTime(stamp))
+	if !t.CreatedAt.IsZero() {
+		writeLine(w, "CREATED:"+formatDateTime(t.CreatedAt))
+	}
+	writeLine(w, "SUMMARY:"+escapeText(t.Title))
+	body, tags := items.SplitTagLine(t.Body)
+	if body != "" {
+		writeLine(w, "DESCRIPTION:"+escapeText(body))
+	}
+	if t.Tag != nil {
+		tags = append([]string{t.Tag.Name}, tags...)
+	}
+	if len(tags) > 0 {
+		var categories []string
+		for _, tag := range tags {
+			categories = append(categories, escapeText(tag))
+		}
+		writeLine(w, "CATEGORIES:"+strings.Join(categories, ","))
+	}
+	if status, ok := statuses[t.Status]; ok {
+		writeLine(w, "STATUS:"+status)
+	}
+	if t.Status == items.Done && !t.CompletedAt.IsZero() {
+		writeLine(w, "COMPLETED:"+formatDateTime(t.CompletedAt))
+	}
+	if p, ok := priorities[t.Priority]; ok {
+		writeLine(w, "PRIORITY:"+strconv.Itoa(p))
+	}
+	// Scheduled dates are when the task can be started
+	if !t.ScheduledDate.IsZero() {
+		writeLine(w, "DTSTART;VALUE=DATE:"+t.ScheduledDate.Format(dateLayout))
+	}
+	if !t.DueDate.IsZero() {
+		writeLine(w, "DUE;VALUE=DATE:"+t.DueDate.Format(dateLayout))
+	}
+	if rule := formatRRule(t.Recurrence); rule != "" {
+		writeLine(w, "RRULE:"+rule)
+	}
+	writeLine(w, "END:VTODO")
+}
+
+// writeLine writes a content line, folded to lines of at most 75 octets. Continuation
+// lines start with a space, which counts towards their length.
+func writeLine(w *bufio.Writer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		// Don't split UTF-8 sequences
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		limit = maxLineOctets - 1
+	}
+	w.WriteString(line + "\r\n")
+}
+
+func escapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+func unescapeText(s string) string {
+	return strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n").Replace(s)
+}
+
+func formatDateTime(t time.Time) string {
+	return t.UTC().Format(dateTimeLayout) + "Z"
+}
+
+// Decode reads the VTODO components of a calendar, other components are skipped.
+func Decode(r io.Reader) (memory.Data, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return memory.Data{}, err
+	}
+
+	var data memory.Data
+	var todo []property
+	inTodo := false
+	for n, line := range lines {
+		if line == "" {
+			continue
+		}
+		p, err := parseProperty(line)
+		if err != nil {
+			return memory.Data{}, fmt.Errorf("invalid iCalendar line %d: %w", n+1, err)
+		}
+		switch {
+		case p.name == "BEGIN" && strings.EqualFold(p.value, "VTODO"):
+			inTodo, todo = true, nil
+		case p.name == "END" && strings.EqualFold(p.value, "VTODO"):
+			t, err := taskFromTodo(todo)
+			if err != nil {
+				return memory.Data{}, err
+			}
+			t.Id = strconv.Itoa(len(data.Tasks) + 1)
+			data.Tasks = append(data.Tasks, t)
+			inTodo = false
+		case inTodo:
+			todo = append(todo, p)
+		}
+	}
+	return data, nil
+}
+
+// unfold reads the content lines, joining the continuation lines of folded ones.
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// parseProperty splits a content line into its name, parameters and value.
+func parseProperty(line string) (property, error) {
+	// The value starts at the first colon outside of quoted parameter values
+	quoted := false
+	colon := -1
+	for i, c := range line {
+		if c == '"' {
+			quoted = !quoted
+		} else if c == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon == -1 {
+		return property{}, fmt.Errorf("missing value in %q", line)
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	p := property{
+		name:   strings.ToUpper(parts[0]),
+		params: map[string]string{},
+		value:  line[colon+1:],
+	}
+	for _, param := range parts[1:] {
+		if k, v, ok := strings.Cut(param, "="); ok {
+			p.params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+		}
+	}
+	return p, nil
+}
+
+func taskFromTodo(props []property) (items.Task, error) {
+	t := items.Task{Status: items.Todo}
+	var categories []string
+	for _, p := range props {
+		var err error
+		switch p.name {
+		case "UID":
+			t.UID = unescapeText(p.value)
+		case "SUMMARY":
+			t.Title = unescapeText(p.value)
+		case "DESCRIPTION":
+			t.Body = unescapeText(p.value)
+		case "CATEGORIES":
+			for _, c := range splitList(p.value) {
+				if c = strings.TrimSpace(unescapeText(c)); c != "" {
+					categories = append(categories, c)
+				}
+			}
+		case "STATUS":
+			for status, value := range statuses {
+				if strings.EqualFold(p.value, value) {
+					t.Status = status
+				}
+			}
+		case "PRIORITY":
+			n, _ := strconv.Atoi(p.value)
+			t.Priority = parsePriority(n)
+		case "CREATED":
+			t.CreatedAt, err = parseDateTime(p)
+		case "COMPLETED":
+			t.CompletedAt, err = parseDateTime(p)
+		case "DTSTART":
+			t.ScheduledDate, err = parseDate(p)
+		case "DUE":
+			t.DueDate, err = parseDate(p)
+		case "RRULE":
+			t.Recurrence = parseRRule(p.value)
+		}
+		if err != nil {
+			return t, fmt.Errorf("invalid %s in to-do %q: %w", p.name, t.Title, err)
+		}
+	}
+
+	// Like other imports, the first category is the tag and the rest are kept in the body
+	if len(categories) > 0 {
+		t.Tag = &items.Tag{Name: categories[0]}
+	}
+	if len(categories) > 1 {
+		t.Body = items.AppendTagLine(t.Body, categories[1:])
+	}
+	return t, nil
+}
+
+// splitList splits a comma separated value, ignoring escaped commas.
+func splitList(s string) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case ',':
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func parsePriority(n int) items.Priority {
+	switch {
+	case n == 1:
+		return items.PriorityHighest
+	case n >= 2 && n <= 4:
+		return items.PriorityHigh
+	case n == 5:
+		return items.PriorityMedium
+	case n >= 6 && n <= 8:
+		return items.PriorityLow
+	case n == 9:
+		return items.PriorityLowest
+	default:
+		return items.PriorityNone
+	}
+}
+
+// parseDateTime parses a DATE or DATE-TIME value. Times without a zone are
+// local, as are those in a time zone that can't be loaded.
+func parseDateTime(p property) (time.Time, error) {
+	if len(p.value) == len(dateLayout) {
+		return time.ParseInLocation(dateLayout, p.value, time.Local)
+	}
+	if strings.HasSuffix(p.value, "Z") {
+		t, err := time.Parse(dateTimeLayout, strings.TrimSuffix(p.value, "Z"))
+		return t.Local(), err
+	}
+	loc := time.Local
+	if tzid := p.params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation(dateTimeLayout, p.value, loc)
+	return t.Local(), err
+}
+
+// parseDate parses a value into a date-only field, on the local day it falls.
+func parseDate(p property) (time.Time, error) {
+	t, err := parseDateTime(p)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local), nil
+}
+
+// formatRRule converts rules like "every 2 weeks" to "FREQ=WEEKLY;INTERVAL=2".
+// "when done" rules have no equivalent and are written as plain recurrences.
+func formatRRule(recurrence string) string {
+	fields := strings.Fields(strings.ToLower(recurrence))
+	if n := len(fields); n >= 2 && fields[n-2] == "when" && fields[n-1] == "done" {
+		fields = fields[:n-2]
+	}
+	if len(fields) < 2 || fields[0] != "every" {
+		return ""
+	}
+	interval := 1
+	if len(fields) == 3 {
+		n, err := strconv.Atoi(fields[1])
+		if err != nil || n < 1 {
+			return ""
+		}
+		interval = n
+		fields = append(fields[:1], fields[2:]...)
+	}
+	if len(fields) != 2 {
+		return ""
+	}
+	freq, ok := frequencies[strings.TrimSuffix(fields[1], "s")]
+	if !ok {
+		return ""
+	}
+	if interval == 1 {
+		return "FREQ=" + freq
+	}
+	return fmt.Sprintf("FREQ=%s;INTERVAL=%d", freq, interval)
+}
+
+// parseRRule is the reverse of formatRRule. Rules with other parts (BYDAY, COUNT...)
+// are approximated by their frequency and interval.
+func parseRRule(rule string) string {
+	var unit string
+	interval := 1
+	for _, part := range strings.Split(rule, ";") {
+		k, v, _ := strings.Cut(part, "=")
+		switch strings.ToUpper(k) {
+		case "FREQ":
+			for u, freq := range frequencies {
+				if strings.EqualFold(v, freq) {
+					unit = u
+				}
+			}
+		case "INTERVAL":
+			if n, err := strconv.Atoi(v); err == nil && n > 0 {
+				interval = n
+			}
+		}
+	}
+	if unit == "" {
+		return ""
+	}
+	if interval == 1 {
+		return "every " + unit
+	}
+	return fmt.Sprintf("every %d %ss", interval, unit)
+}
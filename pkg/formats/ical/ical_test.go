@@ -0,0 +1,232 @@
+package ical_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/markelca/prioritty/pkg/formats/ical"
+	"github.com/markelca/prioritty/pkg/items"
+	"github.com/markelca/prioritty/pkg/items/repository/memory"
+)
+
+func date(t *testing.T, s string) time.Time {
+	t.Helper()
+	d, err := items.ParseDate(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestRoundTrip(t *testing.T) {
+	created := time.Date(2025, 6, 1, 9, 30, 0, 0, time.UTC)
+	completed := time.Date(2025, 6, 3, 18, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		task func(t *testing.T) items.Task
+		want func(t *testing.T) items.Task // if it differs from the task
+	}{
+		{
+			name: "to-do",
+			task: func(t *testing.T) items.Task {
+				return items.Task{Item: items.Item{Title: "Write report", CreatedAt: created}, Status: items.Todo}
+			},
+		},
+		{
+			name: "all fields",
+			task: func(t *testing.T) items.Task {
+				return items.Task{
+					Item: items.Item{
+						UID:       "7c6c9d2e-2b7b-4f3e-9a53-2a3b1c4d5e6f",
+						Title:     "Pay rent; flat 3, second floor",
+						Body:      "Transfer to the landlord\\bank\n#home #money",
+						Tag:       &items.Tag{Name: "bills"},
+						CreatedAt: created,
+					},
+					Status:        items.InProgress,
+					Priority:      items.PriorityHigh,
+					DueDate:       date(t, "2025-07-01"),
+					ScheduledDate: date(t, "2025-06-28"),
+					Recurrence:    "every 2 weeks",
+				}
+			},
+		},
+		{
+			name: "completed",
+			task: func(t *testing.T) items.Task {
+				return items.Task{Item: items.Item{Title: "Book the room", CreatedAt: created}, Status: items.Done, CompletedAt: completed}
+			},
+		},
+		{
+			name: "cancelled, every priority kept",
+			task: func(t *testing.T) items.Task {
+				return items.Task{Item: items.Item{Title: "Someday", CreatedAt: created}, Priority: items.PriorityLowest, Status: items.Cancelled}
+			},
+		},
+		{
+			name: "when done rules written as plain recurrences",
+			task: func(t *testing.T) items.Task {
+				return items.Task{Item: items.Item{Title: "Haircut", CreatedAt: created}, Status: items.Todo, Recurrence: "every month when done"}
+			},
+			want: func(t *testing.T) items.Task {
+				return items.Task{Item: items.Item{Title: "Haircut", CreatedAt: created}, Status: items.Todo, Recurrence: "every month"}
+			},
+		},
+		{
+			name: "long text folded",
+			task: func(t *testing.T) items.Task {
+				return items.Task{
+					Item: items.Item{
+						Title:     strings.Repeat("Très long résumé ", 12),
+						Body:      strings.Repeat("ünïcödé ", 40),
+						CreatedAt: created,
+					},
+					Status: items.Todo,
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := tt.task(t)
+			task.Id = "1"
+			var buf bytes.Buffer
+			if err := ical.Encode(&buf, memory.Data{Tasks: []items.Task{task}}); err != nil {
+				t.Fatal(err)
+			}
+			data, err := ical.Decode(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(data.Tasks) != 1 {
+				t.Fatalf("decoded %d tasks, want 1", len(data.Tasks))
+			}
+
+			want := task
+			if tt.want != nil {
+				want = tt.want(t)
+			}
+			if want.UID == "" {
+				want.UID = items.StableUID(&task)
+			}
+			checkTask(t, data.Tasks[0], want)
+		})
+	}
+}
+
+func checkTask(t *testing.T, got, want items.Task) {
+	t.Helper()
+	if got.UID != want.UID || got.Title != want.Title || got.Body != want.Body || got.Status != want.Status ||
+		got.Priority != want.Priority || got.Recurrence != want.Recurrence {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if tagName(got.Tag) != tagName(want.Tag) {
+		t.Errorf("tag = %q, want %q", tagName(got.Tag), tagName(want.Tag))
+	}
+	for _, d := range []struct {
+		name      string
+		got, want time.Time
+	}{
+		{"created", got.CreatedAt, want.CreatedAt},
+		{"completed", got.CompletedAt, want.CompletedAt},
+		{"due", got.DueDate, want.DueDate},
+		{"scheduled", got.ScheduledDate, want.ScheduledDate},
+	} {
+		if !d.got.Equal(d.want) {
+			t.Errorf("%s = %v, want %v", d.name, d.got, d.want)
+		}
+	}
+}
+
+func tagName(tag *items.Tag) string {
+	if tag == nil {
+		return ""
+	}
+	return tag.Name
+}
+
+// RFC 5545 limits lines to 75 octets, the leading space of continuation lines included.
+func TestEncodeFoldsLines(t *testing.T) {
+	for _, title := range []string{
+		strings.Repeat("a", 300),
+		strings.Repeat("é", 150),
+		strings.Repeat("x€", 100),
+	} {
+		task := items.Task{Item: items.Item{Id: "1", Title: title, CreatedAt: time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)}}
+		var buf bytes.Buffer
+		if err := ical.Encode(&buf, memory.Data{Tasks: []items.Task{task}}); err != nil {
+			t.Fatal(err)
+		}
+		for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+			if len(line) > 75 {
+				t.Errorf("line of %d octets: %q", len(line), line)
+			}
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	calendar := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VEVENT",
+		"SUMMARY:Not a to-do",
+		"END:VEVENT",
+		"BEGIN:VTODO",
+		"UID:abc",
+		"SUMMARY:Call the ",
+		" plumber",
+		"CATEGORIES:home,urgent\\,now",
+		"CATEGORIES:later",
+		"PRIORITY:2",
+		"STATUS:NEEDS-ACTION",
+		"DTSTART;TZID=Europe/Madrid:20250630T230000",
+		"DUE;VALUE=DATE:20250701",
+		"RRULE:FREQ=WEEKLY;INTERVAL=3;BYDAY=MO",
+		"END:VTODO",
+		"BEGIN:VTODO",
+		"SUMMARY:Second",
+		"PRIORITY:0",
+		"STATUS:COMPLETED",
+		"COMPLETED:20250601T100000Z",
+		"END:VTODO",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	local := time.Local
+	time.Local = time.UTC
+	t.Cleanup(func() { time.Local = local })
+
+	data, err := ical.Decode(strings.NewReader(calendar))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Tasks) != 2 {
+		t.Fatalf("decoded %d tasks, want 2", len(data.Tasks))
+	}
+	checkTask(t, data.Tasks[0], items.Task{
+		Item: items.Item{
+			UID:   "abc",
+			Title: "Call the plumber",
+			Body:  items.AppendTagLine("", []string{"urgent,now", "later"}),
+			Tag:   &items.Tag{Name: "home"},
+		},
+		Status:        items.Todo,
+		Priority:      items.PriorityHigh,
+		ScheduledDate: date(t, "2025-06-30"),
+		DueDate:       date(t, "2025-07-01"),
+		Recurrence:    "every 3 weeks",
+	})
+	checkTask(t, data.Tasks[1], items.Task{
+		Item:        items.Item{Title: "Second"},
+		Status:      items.Done,
+		CompletedAt: time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC),
+	})
+
+	if _, err := ical.Decode(strings.NewReader("BEGIN:VTODO\r\nSUMMARY\r\nEND:VTODO")); err == nil {
+		t.Fatal("Decode of a line without value succeeded")
+	}
+}
@@ -0,0 +1,108 @@
+// Package taskbook reads the storage file of taskbook (https://github.com/klaudiosinani/taskbook),
+// usually ~/.taskbook/storage/storage.json.
+package taskbook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/markelca/prioritty/pkg/items"
+	"github.com/markelca/prioritty/pkg/items/repository/memory"
+)
+
+// defaultBoard holds the items added without a board. Like in prioritty, where
+// untagged items are listed under it, it doesn't become a tag.
+const defaultBoard = "My Board"
+
+// record is a taskbook task or note, stored by ID.
+type record struct {
+	Id          int      `json:"_id"`
+	Timestamp   int64    `json:"_timestamp"` // creation time in milliseconds
+	IsTask      bool     `json:"_isTask"`
+	Description string   `json:"description"`
+	Boards      []string `json:"boards"`
+	IsStarred   bool     `json:"isStarred"`
+	IsComplete  bool     `json:"isComplete"`
+	InProgress  bool     `json:"inProgress"`
+	Priority    int      `json:"priority"`
+}
+
+// priorities maps taskbook priorities (1 normal, 2 medium, 3 high) to prioritty ones.
+var priorities = map[int]items.Priority{
+	2: items.PriorityMedium,
+	3: items.PriorityHigh,
+}
+
+// Decode reads the items of a taskbook storage file, ordered by their taskbook ID.
+// The first board other than "My Board" becomes the tag, other boards are kept as a
+// "#board" line in the body. Starred tasks get the highest priority. Taskbook has no
+// UIDs: the items get the one from items.StableUID, derived from their taskbook ID and
+// creation time, so importing the file again updates the same items.
+func Decode(r io.Reader) (memory.Data, error) {
+	var storage map[string]record
+	if err := json.NewDecoder(r).Decode(&storage); err != nil {
+		return memory.Data{}, fmt.Errorf("invalid taskbook storage: %w", err)
+	}
+
+	records := make([]record, 0, len(storage))
+	for _, rec := range storage {
+		records = append(records, rec)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Id < records[j].Id
+	})
+
+	var data memory.Data
+	for _, rec := range records {
+		item := items.Item{
+			Id:    strconv.Itoa(rec.Id),
+			Title: strings.TrimSpace(rec.Description),
+		}
+		if rec.Timestamp != 0 {
+			item.CreatedAt = time.UnixMilli(rec.Timestamp)
+		}
+
+		var boards []string
+		for _, b := range rec.Boards {
+			if b = strings.TrimPrefix(b, "@"); b != "" && b != defaultBoard {
+				boards = append(boards, b)
+			}
+		}
+		if len(boards) > 0 {
+			item.Tag = &items.Tag{Name: boards[0]}
+		}
+		if len(boards) > 1 {
+			item.Body = items.AppendTagLine("", boards[1:])
+		}
+
+		if !rec.IsTask {
+			n := items.Note{Item: item}
+			n.UID = items.StableUID(&n)
+			data.Notes = append(data.Notes, n)
+			continue
+		}
+
+		t := items.Task{
+			Item:     item,
+			Status:   items.Todo,
+			Priority: priorities[rec.Priority],
+		}
+		if rec.IsStarred {
+			t.Priority = items.PriorityHighest
+		}
+		switch {
+		case rec.IsComplete:
+			t.Status = items.Done
+		case rec.InProgress:
+			t.Status = items.InProgress
+		}
+		t.UID = items.StableUID(&t)
+		data.Tasks = append(data.Tasks, t)
+	}
+	return data, nil
+}
@@ -0,0 +1,130 @@
+package taskbook_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/markelca/prioritty/pkg/formats/taskbook"
+	"github.com/markelca/prioritty/pkg/items"
+)
+
+func TestDecodeTasks(t *testing.T) {
+	tests := []struct {
+		name   string
+		record string
+		want   items.Task
+	}{
+		{
+			name:   "pending task on the default board",
+			record: `{"_id":1,"_timestamp":1748764800000,"_isTask":true,"description":" Write report ","boards":["My Board"],"priority":1}`,
+			want: items.Task{
+				Item:   items.Item{Id: "1", Title: "Write report", CreatedAt: time.UnixMilli(1748764800000)},
+				Status: items.Todo,
+			},
+		},
+		{
+			name:   "board becomes the tag, the others a tag line",
+			record: `{"_id":2,"_isTask":true,"description":"Pay rent","boards":["@home","My Board","@money","@bills"],"priority":2}`,
+			want: items.Task{
+				Item:     items.Item{Id: "2", Title: "Pay rent", Tag: &items.Tag{Name: "home"}, Body: "#money #bills"},
+				Status:   items.Todo,
+				Priority: items.PriorityMedium,
+			},
+		},
+		{
+			name:   "complete, high priority",
+			record: `{"_id":3,"_isTask":true,"description":"Book the room","boards":["My Board"],"isComplete":true,"priority":3}`,
+			want: items.Task{
+				Item:     items.Item{Id: "3", Title: "Book the room"},
+				Status:   items.Done,
+				Priority: items.PriorityHigh,
+			},
+		},
+		{
+			name:   "in progress and starred",
+			record: `{"_id":4,"_isTask":true,"description":"Review","boards":["work"],"inProgress":true,"isStarred":true,"priority":1}`,
+			want: items.Task{
+				Item:     items.Item{Id: "4", Title: "Review", Tag: &items.Tag{Name: "work"}},
+				Status:   items.InProgress,
+				Priority: items.PriorityHighest,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := taskbook.Decode(strings.NewReader(`{"1":` + tt.record + `}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(data.Tasks) != 1 || len(data.Notes) != 0 {
+				t.Fatalf("decoded %d tasks and %d notes, want 1 task", len(data.Tasks), len(data.Notes))
+			}
+			got := data.Tasks[0]
+			if got.Id != tt.want.Id || got.Title != tt.want.Title || got.Body != tt.want.Body ||
+				got.Status != tt.want.Status || got.Priority != tt.want.Priority || !got.CreatedAt.Equal(tt.want.CreatedAt) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+			if tagName(got.Tag) != tagName(tt.want.Tag) {
+				t.Errorf("tag = %q, want %q", tagName(got.Tag), tagName(tt.want.Tag))
+			}
+		})
+	}
+}
+
+func tagName(tag *items.Tag) string {
+	if tag == nil {
+		return ""
+	}
+	return tag.Name
+}
+
+func TestDecodeOrderAndNotes(t *testing.T) {
+	storage := `{
+		"10": {"_id":10,"_isTask":true,"description":"Third","boards":["My Board"]},
+		"2": {"_id":2,"_isTask":false,"description":"A note","boards":["@ideas"]},
+		"1": {"_id":1,"_isTask":true,"description":"First","boards":["My Board"]}
+	}`
+	data, err := taskbook.Decode(strings.NewReader(storage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Tasks) != 2 || data.Tasks[0].Title != "First" || data.Tasks[1].Title != "Third" {
+		t.Fatalf("tasks = %+v, want First and Third, ordered by ID", data.Tasks)
+	}
+	if len(data.Notes) != 1 || data.Notes[0].Title != "A note" || tagName(data.Notes[0].Tag) != "ideas" {
+		t.Fatalf("notes = %+v, want the note tagged ideas", data.Notes)
+	}
+}
+
+func TestDecodeStableUIDs(t *testing.T) {
+	storage := `{
+		"1": {"_id":1,"_timestamp":1748764800000,"_isTask":true,"description":"Write report","boards":["My Board"]},
+		"2": {"_id":2,"_timestamp":1748764800000,"_isTask":true,"description":"Write report","boards":["My Board"]}
+	}`
+	decode := func() []items.Task {
+		data, err := taskbook.Decode(strings.NewReader(storage))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data.Tasks
+	}
+	first, again := decode(), decode()
+	if first[0].UID == "" || first[0].UID == first[1].UID {
+		t.Fatalf("UIDs = %q and %q, want two different ones", first[0].UID, first[1].UID)
+	}
+	for i := range first {
+		if again[i].UID != first[i].UID {
+			t.Errorf("UID of %s = %q, then %q", first[i].Id, first[i].UID, again[i].UID)
+		}
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	for _, storage := range []string{"", "[]", `{"1":`} {
+		if _, err := taskbook.Decode(strings.NewReader(storage)); err == nil {
+			t.Errorf("Decode(%q) succeeded", storage)
+		}
+	}
+}
@@ -0,0 +1,332 @@
+// Package taskwarrior converts items from and to the JSON used by Taskwarrior's
+// "task export" and "task import" commands.
+package taskwarrior
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/markelca/prioritty/pkg/items"
+	"github.com/markelca/prioritty/pkg/items/repository/memory"
+)
+
+// timeLayout is the format of Taskwarrior dates, always in UTC.
+const timeLayout = "20060102T150405Z"
+
+// Taskwarrior statuses.
+const (
+	statusPending   = "pending"
+	statusCompleted = "completed"
+	statusDeleted   = "deleted"
+	statusWaiting   = "waiting"
+	statusRecurring = "recurring"
+)
+
+// record is a task as written by "task export". Attributes prioritty doesn't use are ignored.
+type record struct {
+	UUID        string       `json:"uuid"`
+	Description string       `json:"description"`
+	Status      string       `json:"status"`
+	Entry       string       `json:"entry,omitempty"`
+	Start       string       `json:"start,omitempty"`
+	End         string       `json:"end,omitempty"`
+	Due         string       `json:"due,omitempty"`
+	Scheduled   string       `json:"scheduled,omitempty"`
+	Recur       string       `json:"recur,omitempty"`
+	Project     string       `json:"project,omitempty"`
+	Tags        []string     `json:"tags,omitempty"`
+	Priority    string       `json:"priority,omitempty"`
+	Annotations []annotation `json:"annotations,omitempty"`
+}
+
+type annotation struct {
+	Entry       string `json:"entry"`
+	Description string `json:"description"`
+}
+
+var priorityByLetter = map[string]items.Priority{
+	"H": items.PriorityHigh,
+	"M": items.PriorityMedium,
+	"L": items.PriorityLow,
+}
+
+// Taskwarrior only has three priorities, highest and lowest fold into high and low.
+var letterByPriority = map[items.Priority]string{
+	items.PriorityHighest: "H",
+	items.PriorityHigh:    "H",
+	items.PriorityMedium:  "M",
+	items.PriorityLow:     "L",
+	items.PriorityLowest:  "L",
+}
+
+// Decode reads the tasks of a "task export" file. Both the JSON array of current
+// versions and the one task per line of older ones are accepted. Recurring task
+// templates are skipped, their pending instances carry the recurrence.
+func Decode(r io.Reader) (memory.Data, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return memory.Data{}, err
+	}
+
+	var records []record
+	if trimmed := bytes.TrimSpace(content); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &records); err != nil {
+			return memory.Data{}, fmt.Errorf("invalid taskwarrior export: %w", err)
+		}
+	} else {
+		scanner := bufio.NewScanner(bytes.NewReader(content))
+		scanner.Buffer(nil, len(content)+1)
+		for n := 1; scanner.Scan(); n++ {
+			line := strings.TrimSuffix(strings.TrimSpace(scanner.Text()), ",")
+			if line == "" {
+				continue
+			}
+			var rec record
+			if err := json.Unmarshal([]byte(line), &rec); err != nil {
+				return memory.Data{}, fmt.Errorf("invalid taskwarrior export on line %d: %w", n, err)
+			}
+			records = append(records, rec)
+		}
+		if err := scanner.Err(); err != nil {
+			return memory.Data{}, err
+		}
+	}
+
+	var data memory.Data
+	for _, rec := range records {
+		if rec.Status == statusRecurring {
+			continue
+		}
+		task, err := taskFromRecord(rec)
+		if err != nil {
+			return memory.Data{}, fmt.Errorf("task %q: %w", rec.Description, err)
+		}
+		task.Id = strconv.Itoa(len(data.Tasks) + 1)
+		data.Tasks = append(data.Tasks, task)
+	}
+	return data, nil
+}
+
+func taskFromRecord(rec record) (items.Task, error) {
+	var err error
+	t := items.Task{
+		Item: items.Item{
+			UID:   rec.UUID,
+			Title: rec.Description,
+		},
+		Priority:   priorityByLetter[strings.ToUpper(rec.Priority)],
+		Recurrence: parseRecur(rec.Recur),
+	}
+
+	switch rec.Status {
+	case statusCompleted:
+		t.Status = items.Done
+	case statusDeleted:
+		t.Status = items.Cancelled
+	default:
+		t.Status = items.Todo
+		if rec.Start != "" {
+			t.Status = items.InProgress
+		}
+	}
+
+	if t.CreatedAt, err = parseTime(rec.Entry); err != nil {
+		return t, err
+	}
+	if t.Status == items.Done {
+		if t.CompletedAt, err = parseTime(rec.End); err != nil {
+			return t, err
+		}
+	}
+	if t.DueDate, err = parseDate(rec.Due); err != nil {
+		return t, err
+	}
+	if t.ScheduledDate, err = parseDate(rec.Scheduled); err != nil {
+		return t, err
+	}
+
+	// The project is the tag, else the first tag. The remaining tags go to the body.
+	tags := rec.Tags
+	tagName := rec.Project
+	if tagName == "" && len(tags) > 0 {
+		tagName, tags = tags[0], tags[1:]
+	}
+	if tagName != "" {
+		t.Tag = &items.Tag{Name: tagName}
+	}
+
+	var body []string
+	for _, a := range rec.Annotations {
+		body = append(body, a.Description)
+	}
+	t.Body = items.AppendTagLine(strings.Join(body, "\n"), tags)
+
+	return t, nil
+}
+
+// Encode writes the tasks of data as a JSON array that "task import" accepts. Notes
+// are left out, Taskwarrior has nothing to hold them. Items without a UUID get the
+// one from items.StableUID, so importing an export again updates the same tasks.
+func Encode(w io.Writer, data memory.Data) error {
+	records := []record{}
+	for _, t := range data.Tasks {
+		records = append(records, recordFromTask(t))
+	}
+	content, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(content, '\n'))
+	return err
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+func recordFromTask(t items.Task) record {
+	uid := items.StableUID(&t)
+	if !uuidPattern.MatchString(uid) {
+		// Taskwarrior requires UUIDs, e.g. the 🆔 of an Obsidian inline task isn't one
+		t.UID = ""
+		uid = items.StableUID(&t)
+	}
+
+	entry := formatTime(t.CreatedAt)
+	rec := record{
+		UUID:        uid,
+		Description: t.Title,
+		Status:      statusPending,
+		Entry:       entry,
+		Due:         formatDate(t.DueDate),
+		Scheduled:   formatDate(t.ScheduledDate),
+		Priority:    letterByPriority[t.Priority],
+	}
+
+	switch t.Status {
+	case items.Done:
+		rec.Status = statusCompleted
+		rec.End = entry
+		if !t.CompletedAt.IsZero() {
+			rec.End = formatTime(t.CompletedAt)
+		}
+	case items.Cancelled:
+		rec.Status = statusDeleted
+		rec.End = entry
+	case items.InProgress:
+		// The start time isn't tracked, the creation time marks the task as started
+		rec.Start = entry
+	}
+
+	// Taskwarrior recurrence needs a due date
+	if !t.DueDate.IsZero() {
+		rec.Recur = formatRecur(t.Recurrence)
+	}
+
+	if t.Tag != nil {
+		rec.Project = t.Tag.Name
+	}
+
+	// Taskwarrior tells annotations apart by their entry, each line gets its own second
+	annotated := t.CreatedAt
+	if annotated.IsZero() {
+		annotated = time.Now()
+	}
+	body, tags := items.SplitTagLine(t.Body)
+	rec.Tags = tags
+	for _, line := range strings.Split(body, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		at := annotated.Add(time.Duration(len(rec.Annotations)) * time.Second)
+		rec.Annotations = append(rec.Annotations, annotation{Entry: formatTime(at), Description: line})
+	}
+
+	return rec
+}
+
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(timeLayout, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", s)
+	}
+	return t.Local(), nil
+}
+
+// parseDate converts a Taskwarrior date to a date-only field, on the local day it falls.
+func parseDate(s string) (time.Time, error) {
+	t, err := parseTime(s)
+	if err != nil || t.IsZero() {
+		return t, err
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local), nil
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(timeLayout)
+}
+
+// formatDate writes a date-only field as the start of the local day, like "task add due:2025-07-01".
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return formatTime(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local))
+}
+
+var (
+	recurPattern      = regexp.MustCompile(`^(\d*)\s*([a-z]+)$`)
+	recurrencePattern = regexp.MustCompile(`^every\s+(?:(\d+)\s+)?(day|week|month|year)s?$`)
+)
+
+// recurUnits maps Taskwarrior duration units to prioritty recurrence units.
+var recurUnits = map[string]string{
+	"d": "day", "day": "day", "days": "day", "daily": "day",
+	"w": "week", "wk": "week", "wks": "week", "week": "week", "weeks": "week", "weekly": "week",
+	"mo": "month", "mos": "month", "month": "month", "months": "month", "monthly": "month",
+	"y": "year", "yr": "year", "yrs": "year", "year": "year", "years": "year", "yearly": "year", "annual": "year",
+}
+
+// parseRecur converts a Taskwarrior recurrence ("weekly", "2w", "3months") to a
+// rule like "every 2 weeks". Periods it doesn't understand are dropped.
+func parseRecur(s string) string {
+	m := recurPattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(s)))
+	if m == nil {
+		return ""
+	}
+	unit, ok := recurUnits[m[2]]
+	if !ok {
+		return ""
+	}
+	n, _ := strconv.Atoi(m[1])
+	if n <= 1 {
+		return "every " + unit
+	}
+	return fmt.Sprintf("every %d %ss", n, unit)
+}
+
+// formatRecur is the reverse of parseRecur. "when done" rules have no Taskwarrior
+// equivalent and are written as plain recurrences.
+func formatRecur(s string) string {
+	s = strings.TrimSpace(strings.TrimSuffix(strings.ToLower(strings.TrimSpace(s)), "when done"))
+	m := recurrencePattern.FindStringSubmatch(s)
+	if m == nil {
+		return ""
+	}
+	n, _ := strconv.Atoi(m[1])
+	if n <= 1 {
+		return map[string]string{"day": "daily", "week": "weekly", "month": "monthly", "year": "yearly"}[m[2]]
+	}
+	return strconv.Itoa(n) + map[string]string{"day": "d", "week": "w", "month": "mo", "year": "y"}[m[2]]
+}
@@ -0,0 +1,249 @@
+package taskwarrior_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/markelca/prioritty/pkg/formats/taskwarrior"
+	"github.com/markelca/prioritty/pkg/items"
+	"github.com/markelca/prioritty/pkg/items/repository/memory"
+)
+
+func date(t *testing.T, s string) time.Time {
+	t.Helper()
+	d, err := items.ParseDate(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestRoundTrip(t *testing.T) {
+	created := time.Date(2025, 6, 1, 9, 30, 0, 0, time.UTC)
+	completed := time.Date(2025, 6, 3, 18, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		task func(t *testing.T) items.Task
+		want func(t *testing.T) items.Task // if it differs from the task
+	}{
+		{
+			name: "pending task",
+			task: func(t *testing.T) items.Task {
+				return items.Task{Item: items.Item{Title: "Write report", CreatedAt: created}, Status: items.Todo}
+			},
+		},
+		{
+			name: "all fields",
+			task: func(t *testing.T) items.Task {
+				return items.Task{
+					Item: items.Item{
+						UID:       "7c6c9d2e-2b7b-4f3e-9a53-2a3b1c4d5e6f",
+						Title:     "Pay rent",
+						Body:      "Transfer to the landlord\nReference: flat 3\n#home #money",
+						Tag:       &items.Tag{Name: "bills"},
+						CreatedAt: created,
+					},
+					Status:        items.Todo,
+					Priority:      items.PriorityHigh,
+					DueDate:       date(t, "2025-07-01"),
+					ScheduledDate: date(t, "2025-06-28"),
+					Recurrence:    "every month",
+				}
+			},
+		},
+		{
+			name: "done",
+			task: func(t *testing.T) items.Task {
+				return items.Task{Item: items.Item{Title: "Book the room", CreatedAt: created}, Status: items.Done, CompletedAt: completed}
+			},
+		},
+		{
+			name: "in progress",
+			task: func(t *testing.T) items.Task {
+				return items.Task{Item: items.Item{Title: "Review", CreatedAt: created}, Status: items.InProgress}
+			},
+		},
+		{
+			name: "priorities fold into three",
+			task: func(t *testing.T) items.Task {
+				return items.Task{Item: items.Item{Title: "Someday", CreatedAt: created}, Priority: items.PriorityLowest, Status: items.Cancelled}
+			},
+			want: func(t *testing.T) items.Task {
+				return items.Task{Item: items.Item{Title: "Someday", CreatedAt: created}, Priority: items.PriorityLow, Status: items.Cancelled}
+			},
+		},
+		{
+			name: "blank body lines dropped",
+			task: func(t *testing.T) items.Task {
+				return items.Task{Item: items.Item{Title: "Plan", Body: "First\n\nSecond\nThird", CreatedAt: created}, Status: items.Todo}
+			},
+			want: func(t *testing.T) items.Task {
+				return items.Task{Item: items.Item{Title: "Plan", Body: "First\nSecond\nThird", CreatedAt: created}, Status: items.Todo}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := tt.task(t)
+			task.Id = "1"
+			var buf bytes.Buffer
+			if err := taskwarrior.Encode(&buf, memory.Data{Tasks: []items.Task{task}}); err != nil {
+				t.Fatal(err)
+			}
+			data, err := taskwarrior.Decode(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(data.Tasks) != 1 {
+				t.Fatalf("decoded %d tasks, want 1", len(data.Tasks))
+			}
+
+			want := task
+			if tt.want != nil {
+				want = tt.want(t)
+			}
+			got := data.Tasks[0]
+			if want.UID == "" {
+				want.UID = items.StableUID(&task)
+			}
+			checkTask(t, got, want)
+		})
+	}
+}
+
+func checkTask(t *testing.T, got, want items.Task) {
+	t.Helper()
+	if got.UID != want.UID || got.Title != want.Title || got.Body != want.Body || got.Status != want.Status ||
+		got.Priority != want.Priority || got.Recurrence != want.Recurrence {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if tagName(got.Tag) != tagName(want.Tag) {
+		t.Errorf("tag = %q, want %q", tagName(got.Tag), tagName(want.Tag))
+	}
+	for _, d := range []struct {
+		name      string
+		got, want time.Time
+	}{
+		{"created", got.CreatedAt, want.CreatedAt},
+		{"completed", got.CompletedAt, want.CompletedAt},
+		{"due", got.DueDate, want.DueDate},
+		{"scheduled", got.ScheduledDate, want.ScheduledDate},
+	} {
+		if !d.got.Equal(d.want) {
+			t.Errorf("%s = %v, want %v", d.name, d.got, d.want)
+		}
+	}
+}
+
+func tagName(tag *items.Tag) string {
+	if tag == nil {
+		return ""
+	}
+	return tag.Name
+}
+
+// Taskwarrior identifies the annotations of a task by their entry, so importing
+// the export must not merge them.
+func TestEncodeDistinctAnnotationEntries(t *testing.T) {
+	task := items.Task{Item: items.Item{Id: "1", Title: "Notes", Body: "one\ntwo\nthree", CreatedAt: time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)}}
+	var buf bytes.Buffer
+	if err := taskwarrior.Encode(&buf, memory.Data{Tasks: []items.Task{task}}); err != nil {
+		t.Fatal(err)
+	}
+	var records []struct {
+		Annotations []struct {
+			Entry       string `json:"entry"`
+			Description string `json:"description"`
+		} `json:"annotations"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &records); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"20250601T090000Z", "20250601T090001Z", "20250601T090002Z"}
+	if len(records) != 1 || len(records[0].Annotations) != len(want) {
+		t.Fatalf("records = %+v, want 1 task with %d annotations", records, len(want))
+	}
+	for i, a := range records[0].Annotations {
+		if a.Entry != want[i] {
+			t.Errorf("annotation %d entry = %s, want %s", i, a.Entry, want[i])
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name   string
+		export string
+		want   []string // titles, in order
+	}{
+		{
+			name:   "JSON array",
+			export: `[{"uuid":"a","description":"One","status":"pending"},{"uuid":"b","description":"Two","status":"completed","end":"20250601T100000Z"}]`,
+			want:   []string{"One", "Two"},
+		},
+		{
+			name: "one task per line",
+			export: `{"uuid":"a","description":"One","status":"pending"},
+{"uuid":"b","description":"Two","status":"waiting"}`,
+			want: []string{"One", "Two"},
+		},
+		{
+			name:   "recurring templates skipped",
+			export: `[{"uuid":"a","description":"Every week","status":"recurring","recur":"weekly"},{"uuid":"b","description":"Every week","status":"pending","recur":"weekly","due":"20250601T000000Z"}]`,
+			want:   []string{"Every week"},
+		},
+		{
+			name:   "empty",
+			export: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := taskwarrior.Decode(strings.NewReader(tt.export))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var titles []string
+			for _, task := range data.Tasks {
+				titles = append(titles, task.Title)
+			}
+			if strings.Join(titles, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("titles = %v, want %v", titles, tt.want)
+			}
+		})
+	}
+
+	if _, err := taskwarrior.Decode(strings.NewReader(`[{"uuid":`)); err == nil {
+		t.Fatal("Decode of invalid JSON succeeded")
+	}
+}
+
+func TestRecurrence(t *testing.T) {
+	tests := []struct {
+		recur string
+		want  string
+	}{
+		{"daily", "every day"},
+		{"weekly", "every week"},
+		{"2w", "every 2 weeks"},
+		{"3months", "every 3 months"},
+		{"yearly", "every year"},
+		{"fortnight", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.recur, func(t *testing.T) {
+			export := `[{"uuid":"a","description":"Task","status":"pending","due":"20250601T000000Z","recur":"` + tt.recur + `"}]`
+			data, err := taskwarrior.Decode(strings.NewReader(export))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := data.Tasks[0].Recurrence; got != tt.want {
+				t.Fatalf("recurrence = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package items
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Estimate is the expected effort of a task, in story points or as a duration of
+// work. The zero Estimate means none. Sums of estimates (see Add) may have both.
+type Estimate struct {
+	Points   float64
+	Duration time.Duration
+}
+
+// pointSuffixes are the units accepted after story points, longest first.
+var pointSuffixes = []string{"points", "point", "pts", "pt", "p"}
+
+// ParseEstimate parses an estimate: story points as a number, optionally followed by
+// "pt" or "points" (e.g. "3", "0.5pt"), or a duration in hours and minutes (e.g.
+// "2h", "1h30m", "45m"). An empty string returns the zero Estimate.
+func ParseEstimate(s string) (Estimate, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return Estimate{}, nil
+	}
+	number := s
+	for _, suffix := range pointSuffixes {
+		if trimmed, ok := strings.CutSuffix(s, suffix); ok {
+			number = strings.TrimSpace(trimmed)
+			break
+		}
+	}
+	if points, err := strconv.ParseFloat(number, 64); err == nil {
+		if math.IsNaN(points) || math.IsInf(points, 0) {
+			return Estimate{}, fmt.Errorf("invalid estimate %q: points must be a finite number", s)
+		}
+		if points < 0 {
+			return Estimate{}, fmt.Errorf("invalid estimate %q: negative points", s)
+		}
+		return Estimate{Points: points}, nil
+	}
+	d, err := time.ParseDuration(strings.ReplaceAll(s, " ", ""))
+	if err != nil || d < 0 {
+		return Estimate{}, fmt.Errorf("invalid estimate %q, expected points (e.g. 3pt) or a duration (e.g. 1h30m)", s)
+	}
+	return Estimate{Duration: d.Truncate(time.Minute)}, nil
+}
+
+// IsZero reports whether there's no estimate.
+func (e Estimate) IsZero() bool {
+	return e.Points == 0 && e.Duration == 0
+}
+
+// Add returns the sum of two estimates.
+func (e Estimate) Add(o Estimate) Estimate {
+	return Estimate{Points: e.Points + o.Points, Duration: e.Duration + o.Duration}
+}
+
+// String formats the estimate as parsed by ParseEstimate, e.g. "3pt" or "1h30m". Sums
+// with both points and a duration are joined with " + ".
+func (e Estimate) String() string {
+	var parts []string
+	if e.Points != 0 {
+		parts = append(parts, strconv.FormatFloat(e.Points, 'f', -1, 64)+"pt")
+	}
+	if e.Duration != 0 {
+		parts = append(parts, strings.ReplaceAll(FormatWorkTime(e.Duration), " ", ""))
+	}
+	return strings.Join(parts, " + ")
+}
+
+// RemainingEstimate returns the sum of the estimates of the open tasks (to do or in
+// progress) and of all the tasks, cancelled ones excluded.
+func RemainingEstimate(tasks []*Task) (remaining, total Estimate) {
+	for _, t := range tasks {
+		switch t.Status {
+		case Cancelled:
+			continue
+		case Todo, InProgress:
+			remaining = remaining.Add(t.Estimate)
+		}
+		total = total.Add(t.Estimate)
+	}
+	return remaining, total
+}
@@ -27,10 +27,12 @@ func ParseItemType(s string) ItemType {
 
 type Base interface {
 	GetId() string
+	GetUID() string
 	GetTitle() string
 	GetBody() string
 	GetTag() *Tag
 	GetCreatedAt() time.Time
+	GetUpdatedAt() time.Time
 	After(ItemInterface) bool
 }
 
@@ -46,9 +48,11 @@ type Tag struct {
 
 type Item struct {
 	Id        string
+	UID       string // identity in other tools (e.g. a Taskwarrior UUID), empty if unknown
 	Title     string
 	Body      string
 	CreatedAt time.Time
+	UpdatedAt time.Time // last modification, zero if unknown
 	Tag       *Tag
 }
 
@@ -56,6 +60,10 @@ func (i Item) GetId() string {
 	return i.Id
 }
 
+func (i Item) GetUID() string {
+	return i.UID
+}
+
 func (i Item) GetBody() string {
 	return i.Body
 }
@@ -67,6 +75,11 @@ func (i Item) GetTitle() string {
 func (i Item) GetCreatedAt() time.Time {
 	return i.CreatedAt
 }
+
+func (i Item) GetUpdatedAt() time.Time {
+	return i.UpdatedAt
+}
+
 func (i Item) GetTag() *Tag {
 	return i.Tag
 }
@@ -86,3 +99,41 @@ func (i Item) After(u ItemInterface) bool {
 	// 2. If both have (or don't have) tags, sort by CreatedAt (most recent first)
 	return i.GetCreatedAt().Before(u.GetCreatedAt())
 }
+
+// SameContent reports whether a and b are versions of an item with the same type and
+// contents: UID, title, body, tag and, for tasks, status, priority, due and scheduled
+// dates, recurrence and estimate. The timestamps, status history and time log are left
+// out, they change along with these or while a timer runs.
+func SameContent(a, b ItemInterface) bool {
+	if a.GetUID() != b.GetUID() || a.GetTitle() != b.GetTitle() || a.GetBody() != b.GetBody() {
+		return false
+	}
+	if tagName(a.GetTag()) != tagName(b.GetTag()) {
+		return false
+	}
+	ta, aIsTask := asTask(a)
+	tb, bIsTask := asTask(b)
+	if aIsTask != bIsTask {
+		return false
+	}
+	return !aIsTask || ta.Status == tb.Status && ta.Priority == tb.Priority && ta.Recurrence == tb.Recurrence &&
+		ta.Estimate == tb.Estimate && FormatDate(ta.DueDate) == FormatDate(tb.DueDate) &&
+		FormatDate(ta.ScheduledDate) == FormatDate(tb.ScheduledDate)
+}
+
+func asTask(i ItemInterface) (Task, bool) {
+	switch v := i.(type) {
+	case *Task:
+		return *v, true
+	case Task:
+		return v, true
+	}
+	return Task{}, false
+}
+
+func tagName(tag *Tag) string {
+	if tag == nil {
+		return ""
+	}
+	return tag.Name
+}
@@ -0,0 +1,50 @@
+package items
+
+import "sort"
+
+// List returns the items in the order they're listed, whose positions are the item
+// numbers given to commands: newest first, grouped by tag in the order tags first
+// appear. Items without tags come first (under "My Board").
+func List(tasks []Task, notes []Note) []ItemInterface {
+	var all []ItemInterface
+	for k := range notes {
+		all = append(all, &notes[k])
+	}
+	for k := range tasks {
+		all = append(all, &tasks[k])
+	}
+	Sort(all)
+	return GroupByTag(all)
+}
+
+// Sort sorts items newest first, items with a tag before the others (see Item.After).
+func Sort(list []ItemInterface) {
+	// Stable so items with equal timestamps (e.g. inline tasks of one file) keep their index
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].After(list[j])
+	})
+}
+
+// GroupByTag groups items by tag in the order tags first appear.
+func GroupByTag(list []ItemInterface) []ItemInterface {
+	var result []ItemInterface
+	tagOrder := []string{}
+	itemsByTag := make(map[string][]ItemInterface)
+
+	for _, item := range list {
+		var tagKey string
+		if tag := item.GetTag(); tag != nil {
+			tagKey = tag.Name
+		}
+
+		if _, exists := itemsByTag[tagKey]; !exists {
+			tagOrder = append(tagOrder, tagKey)
+		}
+		itemsByTag[tagKey] = append(itemsByTag[tagKey], item)
+	}
+
+	for _, tagKey := range tagOrder {
+		result = append(result, itemsByTag[tagKey]...)
+	}
+	return result
+}
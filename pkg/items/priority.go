@@ -0,0 +1,50 @@
+package items
+
+import "strings"
+
+type Priority string
+
+const (
+	PriorityNone    Priority = ""
+	PriorityLowest  Priority = "lowest"
+	PriorityLow     Priority = "low"
+	PriorityMedium  Priority = "medium"
+	PriorityHigh    Priority = "high"
+	PriorityHighest Priority = "highest"
+)
+
+// ParsePriority converts a string to Priority. Returns PriorityNone if invalid.
+func ParsePriority(s string) Priority {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "lowest":
+		return PriorityLowest
+	case "low":
+		return PriorityLow
+	case "medium":
+		return PriorityMedium
+	case "high":
+		return PriorityHigh
+	case "highest":
+		return PriorityHighest
+	default:
+		return PriorityNone
+	}
+}
+
+// Rank orders priorities, tasks without priority rank between medium and low.
+func (p Priority) Rank() int {
+	switch p {
+	case PriorityHighest:
+		return 3
+	case PriorityHigh:
+		return 2
+	case PriorityMedium:
+		return 1
+	case PriorityLow:
+		return -1
+	case PriorityLowest:
+		return -2
+	default:
+		return 0
+	}
+}
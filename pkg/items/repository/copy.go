@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/markelca/prioritty/pkg/items"
+)
+
+// CopyResult counts the items copied by Copy.
+type CopyResult struct {
+	Tasks int
+	Notes int
+}
+
+// Copy creates all tasks and notes of src in dst, with their tags. It's used to
+// convert between storage formats, IDs are assigned by dst.
+func Copy(dst, src Repository) (CopyResult, error) {
+	var result CopyResult
+
+	tasks, err := src.GetTasks()
+	if err != nil {
+		return result, err
+	}
+	notes, err := src.GetNotes()
+	if err != nil {
+		return result, err
+	}
+
+	for _, t := range tasks {
+		if _, err := CopyTask(dst, t); err != nil {
+			return result, err
+		}
+		result.Tasks++
+	}
+
+	for _, n := range notes {
+		if _, err := CopyNote(dst, n); err != nil {
+			return result, err
+		}
+		result.Notes++
+	}
+
+	return result, nil
+}
+
+// CopyTask creates t in dst with its tag, returning the new task. Its ID is assigned by dst.
+// Repositories that can't keep time logs (ErrNoTimeLog) get the task without it.
+func CopyTask(dst Repository, t items.Task) (items.Task, error) {
+	tag := t.Tag
+	t.Id = ""
+	t.Tag = nil
+	err := dst.CreateTask(&t)
+	if errors.Is(err, ErrNoTimeLog) {
+		t.TimeLog = nil
+		err = dst.CreateTask(&t)
+	}
+	if err != nil {
+		return t, fmt.Errorf("failed to copy task %q: %w", t.Title, err)
+	}
+	if tag != nil {
+		dstTag, err := GetOrCreateTag(dst, tag.Name)
+		if err != nil {
+			return t, err
+		}
+		if err := dst.SetTaskTag(t, *dstTag); err != nil {
+			return t, fmt.Errorf("failed to tag task %q: %w", t.Title, err)
+		}
+		t.Tag = dstTag
+	}
+	return t, nil
+}
+
+// CopyNote creates n in dst with its tag, returning the new note. Its ID is assigned by dst.
+func CopyNote(dst Repository, n items.Note) (items.Note, error) {
+	tag := n.Tag
+	n.Id = ""
+	n.Tag = nil
+	if err := dst.CreateNote(&n); err != nil {
+		return n, fmt.Errorf("failed to copy note %q: %w", n.Title, err)
+	}
+	if tag != nil {
+		dstTag, err := GetOrCreateTag(dst, tag.Name)
+		if err != nil {
+			return n, err
+		}
+		if err := dst.SetNoteTag(n, *dstTag); err != nil {
+			return n, fmt.Errorf("failed to tag note %q: %w", n.Title, err)
+		}
+		n.Tag = dstTag
+	}
+	return n, nil
+}
+
+// GetOrCreateTag returns the tag with the given name, creating it if it doesn't exist.
+func GetOrCreateTag(r Repository, name string) (*items.Tag, error) {
+	tag, err := r.GetTag(name)
+	if errors.Is(err, ErrNotFound) {
+		return r.CreateTag(name)
+	}
+	return tag, err
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"github.com/markelca/prioritty/pkg/items"
+)
+
+// CheckExpected returns ErrConflict unless the item with the ID and type of expected
+// is among tasks or notes, with the same content. It's nil when expected is, so
+// repositories call it unconditionally from the changes of their Expecting views.
+func CheckExpected(expected items.ItemInterface, tasks []items.Task, notes []items.Note) error {
+	if expected == nil {
+		return nil
+	}
+	switch expected.(type) {
+	case *items.Task, items.Task:
+		for _, t := range tasks {
+			if t.Id == expected.GetId() {
+				return checkContent(expected, &t)
+			}
+		}
+	case *items.Note, items.Note:
+		for _, n := range notes {
+			if n.Id == expected.GetId() {
+				return checkContent(expected, &n)
+			}
+		}
+	}
+	return ErrConflict
+}
+
+// CheckStored is CheckExpected with the items of r, which are only read if expected
+// isn't nil.
+func CheckStored(r Repository, expected items.ItemInterface) error {
+	switch expected.(type) {
+	case nil:
+		return nil
+	case *items.Task, items.Task:
+		tasks, err := r.GetTasks()
+		if err != nil {
+			return err
+		}
+		return CheckExpected(expected, tasks, nil)
+	default:
+		notes, err := r.GetNotes()
+		if err != nil {
+			return err
+		}
+		return CheckExpected(expected, nil, notes)
+	}
+}
+
+func checkContent(expected, stored items.ItemInterface) error {
+	if !items.SameContent(expected, stored) {
+		return ErrConflict
+	}
+	return nil
+}
+
+// Expect returns r.Expecting(expected) if r is an Expecter. Otherwise, the changes of
+// existing items through the view returned check expected with CheckStored first, which
+// leaves other processes a moment to change the item in between.
+func Expect(r Repository, expected items.ItemInterface) Repository {
+	if e, ok := r.(Expecter); ok {
+		return e.Expecting(expected)
+	}
+	return checking{Repository: r, expected: expected}
+}
+
+// checking is the view returned by Expect for repositories that aren't Expecters.
+type checking struct {
+	Repository
+	expected items.ItemInterface
+}
+
+func (c checking) check(change func() error) error {
+	if err := CheckStored(c.Repository, c.expected); err != nil {
+		return err
+	}
+	return change()
+}
+
+func (c checking) UpdateTask(t items.Task) error {
+	return c.check(func() error { return c.Repository.UpdateTask(t) })
+}
+
+func (c checking) RemoveTask(id string) error {
+	return c.check(func() error { return c.Repository.RemoveTask(id) })
+}
+
+func (c checking) UpdateTaskStatus(t items.Task, s items.Status) error {
+	return c.check(func() error { return c.Repository.UpdateTaskStatus(t, s) })
+}
+
+func (c checking) SetTaskTag(t items.Task, tag items.Tag) error {
+	return c.check(func() error { return c.Repository.SetTaskTag(t, tag) })
+}
+
+func (c checking) UnsetTaskTag(t items.Task) error {
+	return c.check(func() error { return c.Repository.UnsetTaskTag(t) })
+}
+
+func (c checking) UpdateNote(n items.Note) error {
+	return c.check(func() error { return c.Repository.UpdateNote(n) })
+}
+
+func (c checking) RemoveNote(id string) error {
+	return c.check(func() error { return c.Repository.RemoveNote(id) })
+}
+
+func (c checking) SetNoteTag(n items.Note, tag items.Tag) error {
+	return c.check(func() error { return c.Repository.SetNoteTag(n, tag) })
+}
+
+func (c checking) UnsetNoteTag(n items.Note) error {
+	return c.check(func() error { return c.Repository.UnsetNoteTag(n) })
+}
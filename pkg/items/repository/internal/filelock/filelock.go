@@ -0,0 +1,46 @@
+// Package filelock provides advisory, cross-process file locks used by the
+// file based repositories to serialize mutations.
+package filelock
+
+import (
+	"fmt"
+	"os"
+)
+
+// Lock is an exclusive advisory lock held on a file.
+type Lock struct {
+	file *os.File
+}
+
+// Acquire blocks until an exclusive lock on path is held by this process.
+// The lock file is created if it doesn't exist.
+func Acquire(path string) (*Lock, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+	if err := lock(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to acquire lock on %s: %w", path, err)
+	}
+	return &Lock{file: f}, nil
+}
+
+// Release releases the lock and closes the underlying file.
+func (l *Lock) Release() error {
+	if err := unlock(l.file); err != nil {
+		l.file.Close()
+		return err
+	}
+	return l.file.Close()
+}
+
+// With runs fn while holding the lock on path.
+func With(path string, fn func() error) error {
+	l, err := Acquire(path)
+	if err != nil {
+		return err
+	}
+	defer l.Release()
+	return fn()
+}
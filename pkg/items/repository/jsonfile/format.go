@@ -0,0 +1,299 @@
+package jsonfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/markelca/prioritty/pkg/items"
+	"github.com/markelca/prioritty/pkg/items/repository/memory"
+)
+
+// formatVersion is the version of the file format, bumped on incompatible changes.
+const formatVersion = 1
+
+const timeFormat = time.RFC3339
+
+// document is the layout of the JSON file. Items are referenced by ID and tags by name,
+// times are stored in UTC so the file doesn't change between timezones.
+type document struct {
+	Version int          `json:"version"`
+	Tags    []tagRecord  `json:"tags"`
+	Tasks   []taskRecord `json:"tasks"`
+	Notes   []noteRecord `json:"notes"`
+}
+
+type tagRecord struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type taskRecord struct {
+	Id            string         `json:"id"`
+	UID           string         `json:"uid,omitempty"`
+	Title         string         `json:"title"`
+	Body          string         `json:"body,omitempty"`
+	Status        string         `json:"status"`
+	Tag           string         `json:"tag,omitempty"`
+	Priority      string         `json:"priority,omitempty"`
+	Due           string         `json:"due,omitempty"`
+	Scheduled     string         `json:"scheduled,omitempty"`
+	Recurrence    string         `json:"recurrence,omitempty"`
+	Estimate      string         `json:"estimate,omitempty"`
+	CreatedAt     string         `json:"created_at"`
+	UpdatedAt     string         `json:"updated_at,omitempty"`
+	CompletedAt   string         `json:"completed_at,omitempty"`
+	StatusHistory []statusRecord `json:"status_history,omitempty"`
+	TimeLog       []timeRecord   `json:"time_log,omitempty"`
+}
+
+// statusRecord is an entry of a task's status history.
+type statusRecord struct {
+	Status string `json:"status"`
+	At     string `json:"at"`
+}
+
+// timeRecord is an entry of a task's time log, without end while its timer is running.
+type timeRecord struct {
+	Start    string `json:"start"`
+	End      string `json:"end,omitempty"`
+	Pomodoro bool   `json:"pomodoro,omitempty"`
+}
+
+type noteRecord struct {
+	Id        string `json:"id"`
+	UID       string `json:"uid,omitempty"`
+	Title     string `json:"title"`
+	Body      string `json:"body,omitempty"`
+	Tag       string `json:"tag,omitempty"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
+
+func tagNameOf(tag *items.Tag) string {
+	if tag == nil {
+		return ""
+	}
+	return tag.Name
+}
+
+func tagFromName(name string) *items.Tag {
+	if name == "" {
+		return nil
+	}
+	return &items.Tag{Name: name}
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeFormat)
+}
+
+// formatUpdatedAt leaves updated_at out until the item is modified after its creation.
+func formatUpdatedAt(i items.Item) string {
+	if i.UpdatedAt.IsZero() || i.UpdatedAt.Equal(i.CreatedAt) {
+		return ""
+	}
+	return formatTime(i.UpdatedAt)
+}
+
+// parseUpdatedAt defaults to the creation time when updated_at is missing.
+func parseUpdatedAt(s string, createdAt time.Time) (time.Time, error) {
+	if s == "" {
+		return createdAt, nil
+	}
+	return parseTime(s)
+}
+
+func parseTime(s string) (time.Time, error) {
+	return items.ParseTimestamp(s, time.UTC)
+}
+
+func formatStatusHistory(history []items.StatusChange) []statusRecord {
+	var records []statusRecord
+	for _, c := range history {
+		records = append(records, statusRecord{Status: string(c.Status), At: formatTime(c.At)})
+	}
+	return records
+}
+
+func parseStatusHistory(records []statusRecord) ([]items.StatusChange, error) {
+	var history []items.StatusChange
+	for _, rec := range records {
+		at, err := parseTime(rec.At)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, items.StatusChange{Status: items.ParseStatus(rec.Status), At: at})
+	}
+	return history, nil
+}
+
+func formatTimeLog(timeLog []items.TimeEntry) []timeRecord {
+	var records []timeRecord
+	for _, e := range timeLog {
+		records = append(records, timeRecord{Start: formatTime(e.Start), End: items.FormatTimestamp(e.End), Pomodoro: e.Pomodoro})
+	}
+	return records
+}
+
+func parseTimeLog(records []timeRecord) ([]items.TimeEntry, error) {
+	var timeLog []items.TimeEntry
+	for _, rec := range records {
+		start, err := parseTime(rec.Start)
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseTime(rec.End)
+		if err != nil {
+			return nil, err
+		}
+		timeLog = append(timeLog, items.TimeEntry{Start: start, End: end, Pomodoro: rec.Pomodoro})
+	}
+	return timeLog, nil
+}
+
+// Encode serializes items and tags in the file format, with a trailing newline.
+func Encode(data memory.Data) ([]byte, error) {
+	doc := document{
+		Version: formatVersion,
+		Tags:    []tagRecord{},
+		Tasks:   []taskRecord{},
+		Notes:   []noteRecord{},
+	}
+	for _, tag := range data.Tags {
+		doc.Tags = append(doc.Tags, tagRecord{Id: tag.Id, Name: tag.Name})
+	}
+	for _, t := range data.Tasks {
+		doc.Tasks = append(doc.Tasks, taskRecord{
+			Id:            t.Id,
+			UID:           t.UID,
+			Title:         t.Title,
+			Body:          t.Body,
+			Status:        string(t.Status),
+			Tag:           tagNameOf(t.Tag),
+			Priority:      string(t.Priority),
+			Due:           items.FormatDate(t.DueDate),
+			Scheduled:     items.FormatDate(t.ScheduledDate),
+			Recurrence:    t.Recurrence,
+			Estimate:      t.Estimate.String(),
+			CreatedAt:     formatTime(t.CreatedAt),
+			UpdatedAt:     formatUpdatedAt(t.Item),
+			CompletedAt:   items.FormatTimestamp(t.CompletedAt),
+			StatusHistory: formatStatusHistory(t.StatusHistory),
+			TimeLog:       formatTimeLog(t.TimeLog),
+		})
+	}
+	for _, n := range data.Notes {
+		doc.Notes = append(doc.Notes, noteRecord{
+			Id:        n.Id,
+			UID:       n.UID,
+			Title:     n.Title,
+			Body:      n.Body,
+			Tag:       tagNameOf(n.Tag),
+			CreatedAt: formatTime(n.CreatedAt),
+			UpdatedAt: formatUpdatedAt(n.Item),
+		})
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decode parses content in the file format.
+func Decode(content []byte) (memory.Data, error) {
+	var data memory.Data
+	var doc document
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return data, fmt.Errorf("invalid JSON file: %w", err)
+	}
+	if doc.Version > formatVersion {
+		return data, fmt.Errorf("unsupported JSON file version %d", doc.Version)
+	}
+
+	for _, tag := range doc.Tags {
+		data.Tags = append(data.Tags, items.Tag{Id: tag.Id, Name: tag.Name})
+	}
+	for _, t := range doc.Tasks {
+		createdAt, err := parseTime(t.CreatedAt)
+		if err != nil {
+			return data, fmt.Errorf("task %s: invalid created_at: %w", t.Id, err)
+		}
+		updatedAt, err := parseUpdatedAt(t.UpdatedAt, createdAt)
+		if err != nil {
+			return data, fmt.Errorf("task %s: invalid updated_at: %w", t.Id, err)
+		}
+		due, err := items.ParseDate(t.Due)
+		if err != nil {
+			return data, fmt.Errorf("task %s: invalid due date: %w", t.Id, err)
+		}
+		scheduled, err := items.ParseDate(t.Scheduled)
+		if err != nil {
+			return data, fmt.Errorf("task %s: invalid scheduled date: %w", t.Id, err)
+		}
+		completedAt, err := parseTime(t.CompletedAt)
+		if err != nil {
+			return data, fmt.Errorf("task %s: invalid completed_at: %w", t.Id, err)
+		}
+		history, err := parseStatusHistory(t.StatusHistory)
+		if err != nil {
+			return data, fmt.Errorf("task %s: invalid status_history: %w", t.Id, err)
+		}
+		timeLog, err := parseTimeLog(t.TimeLog)
+		if err != nil {
+			return data, fmt.Errorf("task %s: invalid time_log: %w", t.Id, err)
+		}
+		estimate, err := items.ParseEstimate(t.Estimate)
+		if err != nil {
+			return data, fmt.Errorf("task %s: %w", t.Id, err)
+		}
+		data.Tasks = append(data.Tasks, items.Task{
+			Item: items.Item{
+				Id:        t.Id,
+				UID:       t.UID,
+				Title:     t.Title,
+				Body:      t.Body,
+				CreatedAt: createdAt,
+				UpdatedAt: updatedAt,
+				Tag:       tagFromName(t.Tag),
+			},
+			Status:        items.ParseStatus(t.Status),
+			Priority:      items.ParsePriority(t.Priority),
+			DueDate:       due,
+			ScheduledDate: scheduled,
+			Recurrence:    t.Recurrence,
+			Estimate:      estimate,
+			CompletedAt:   completedAt,
+			StatusHistory: history,
+			TimeLog:       timeLog,
+		})
+	}
+	for _, n := range doc.Notes {
+		createdAt, err := parseTime(n.CreatedAt)
+		if err != nil {
+			return data, fmt.Errorf("note %s: invalid created_at: %w", n.Id, err)
+		}
+		updatedAt, err := parseUpdatedAt(n.UpdatedAt, createdAt)
+		if err != nil {
+			return data, fmt.Errorf("note %s: invalid updated_at: %w", n.Id, err)
+		}
+		data.Notes = append(data.Notes, items.Note{
+			Item: items.Item{
+				Id:        n.Id,
+				UID:       n.UID,
+				Title:     n.Title,
+				Body:      n.Body,
+				CreatedAt: createdAt,
+				UpdatedAt: updatedAt,
+				Tag:       tagFromName(n.Tag),
+			},
+		})
+	}
+	return data, nil
+}
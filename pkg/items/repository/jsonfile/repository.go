@@ -0,0 +1,230 @@
+// Package jsonfile implements repository.Repository on a single JSON file, meant to be
+// kept under version control: the file is indented, written atomically and its
+// ordering is stable, so a change to one item is a small diff.
+package jsonfile
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/markelca/prioritty/pkg/items"
+	"github.com/markelca/prioritty/pkg/items/repository"
+	"github.com/markelca/prioritty/pkg/items/repository/internal/atomicfile"
+	"github.com/markelca/prioritty/pkg/items/repository/internal/filelock"
+	"github.com/markelca/prioritty/pkg/items/repository/memory"
+)
+
+var (
+	_ repository.Repository = (*JSONRepository)(nil)
+	_ repository.Expecter   = (*JSONRepository)(nil)
+)
+
+// JSONRepository stores all items and tags in one JSON file. Every operation reads
+// the file, so changes made by other processes (or by hand) are always picked up.
+type JSONRepository struct {
+	path     string
+	expected items.ItemInterface // checked before changes, see Expecting
+}
+
+// NewJSONRepository creates a JSONRepository for the given file. The file is created
+// on the first write.
+func NewJSONRepository(path string) *JSONRepository {
+	return &JSONRepository{path: path}
+}
+
+// Path returns the path to the JSON file.
+func (r *JSONRepository) Path() string {
+	return r.path
+}
+
+// Expecting returns a view of the repository whose changes fail with
+// repository.ErrConflict if the item expected isn't stored as it is.
+func (r *JSONRepository) Expecting(expected items.ItemInterface) repository.Repository {
+	return &JSONRepository{path: r.path, expected: expected}
+}
+
+// lockPath is a hidden file next to the JSON file used to serialize writes across processes.
+func (r *JSONRepository) lockPath() string {
+	return filepath.Join(filepath.Dir(r.path), "."+filepath.Base(r.path)+".lock")
+}
+
+func (r *JSONRepository) load() (*memory.Repository, error) {
+	content, err := os.ReadFile(r.path)
+	if os.IsNotExist(err) {
+		return memory.NewRepository(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	data, err := Decode(content)
+	if err != nil {
+		return nil, err
+	}
+	return memory.NewRepositoryFrom(data), nil
+}
+
+func (r *JSONRepository) save(m *memory.Repository) error {
+	content, err := Encode(m.Data())
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(r.path, content)
+}
+
+// view runs fn on the current contents of the file.
+func (r *JSONRepository) view(fn func(*memory.Repository) error) error {
+	m, err := r.load()
+	if err != nil {
+		return err
+	}
+	return fn(m)
+}
+
+// update runs fn on the current contents of the file and writes them back if fn
+// succeeds, while holding the lock. The item expected by an Expecting view is
+// checked first.
+func (r *JSONRepository) update(fn func(*memory.Repository) error) error {
+	return filelock.With(r.lockPath(), func() error {
+		m, err := r.load()
+		if err != nil {
+			return err
+		}
+		if err := repository.CheckStored(m, r.expected); err != nil {
+			return err
+		}
+		if err := fn(m); err != nil {
+			return err
+		}
+		return r.save(m)
+	})
+}
+
+// Reset removes the JSON file (used for demo cleanup).
+func (r *JSONRepository) Reset() error {
+	return filelock.With(r.lockPath(), func() error {
+		if err := os.Remove(r.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	})
+}
+
+func (r *JSONRepository) GetTasks() (tasks []items.Task, err error) {
+	err = r.view(func(m *memory.Repository) error {
+		tasks, err = m.GetTasks()
+		return err
+	})
+	return tasks, err
+}
+
+func (r *JSONRepository) CreateTask(t *items.Task) error {
+	return r.update(func(m *memory.Repository) error {
+		return m.CreateTask(t)
+	})
+}
+
+func (r *JSONRepository) UpdateTask(t items.Task) error {
+	return r.update(func(m *memory.Repository) error {
+		return m.UpdateTask(t)
+	})
+}
+
+func (r *JSONRepository) RemoveTask(id string) error {
+	return r.update(func(m *memory.Repository) error {
+		return m.RemoveTask(id)
+	})
+}
+
+func (r *JSONRepository) UpdateTaskStatus(t items.Task, s items.Status) error {
+	return r.update(func(m *memory.Repository) error {
+		return m.UpdateTaskStatus(t, s)
+	})
+}
+
+func (r *JSONRepository) SetTaskTag(t items.Task, tag items.Tag) error {
+	return r.update(func(m *memory.Repository) error {
+		return m.SetTaskTag(t, tag)
+	})
+}
+
+func (r *JSONRepository) UnsetTaskTag(t items.Task) error {
+	return r.update(func(m *memory.Repository) error {
+		return m.UnsetTaskTag(t)
+	})
+}
+
+func (r *JSONRepository) GetNotes() (notes []items.Note, err error) {
+	err = r.view(func(m *memory.Repository) error {
+		notes, err = m.GetNotes()
+		return err
+	})
+	return notes, err
+}
+
+func (r *JSONRepository) CreateNote(n *items.Note) error {
+	return r.update(func(m *memory.Repository) error {
+		return m.CreateNote(n)
+	})
+}
+
+func (r *JSONRepository) UpdateNote(n items.Note) error {
+	return r.update(func(m *memory.Repository) error {
+		return m.UpdateNote(n)
+	})
+}
+
+func (r *JSONRepository) RemoveNote(id string) error {
+	return r.update(func(m *memory.Repository) error {
+		return m.RemoveNote(id)
+	})
+}
+
+func (r *JSONRepository) SetNoteTag(n items.Note, tag items.Tag) error {
+	return r.update(func(m *memory.Repository) error {
+		return m.SetNoteTag(n, tag)
+	})
+}
+
+func (r *JSONRepository) UnsetNoteTag(n items.Note) error {
+	return r.update(func(m *memory.Repository) error {
+		return m.UnsetNoteTag(n)
+	})
+}
+
+func (r *JSONRepository) GetTag(name string) (tag *items.Tag, err error) {
+	err = r.view(func(m *memory.Repository) error {
+		tag, err = m.GetTag(name)
+		return err
+	})
+	return tag, err
+}
+
+func (r *JSONRepository) GetTags() (tags []items.Tag, err error) {
+	err = r.view(func(m *memory.Repository) error {
+		tags, err = m.GetTags()
+		return err
+	})
+	return tags, err
+}
+
+func (r *JSONRepository) CreateTag(name string) (tag *items.Tag, err error) {
+	err = r.update(func(m *memory.Repository) error {
+		tag, err = m.CreateTag(name)
+		return err
+	})
+	return tag, err
+}
+
+func (r *JSONRepository) RemoveTag(name string) error {
+	return r.update(func(m *memory.Repository) error {
+		return m.RemoveTag(name)
+	})
+}
+
+func (r *JSONRepository) GetItemsWithTag(name string) (result []items.ItemInterface, err error) {
+	err = r.view(func(m *memory.Repository) error {
+		result, err = m.GetItemsWithTag(name)
+		return err
+	})
+	return result, err
+}
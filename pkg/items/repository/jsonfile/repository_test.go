@@ -0,0 +1,16 @@
+package jsonfile_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/markelca/prioritty/pkg/items/repository"
+	"github.com/markelca/prioritty/pkg/items/repository/jsonfile"
+	"github.com/markelca/prioritty/pkg/items/repository/repositorytest"
+)
+
+func TestRepository(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repository.Repository {
+		return jsonfile.NewJSONRepository(filepath.Join(t.TempDir(), "prioritty.json"))
+	})
+}
@@ -0,0 +1,479 @@
+// Package memory implements repository.Repository in memory, for embedding prioritty
+// without any storage and for testing.
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/markelca/prioritty/pkg/items"
+	"github.com/markelca/prioritty/pkg/items/repository"
+)
+
+var (
+	_ repository.Repository = (*Repository)(nil)
+	_ repository.Expecter   = (*Repository)(nil)
+)
+
+// Repository stores tasks, notes and tags in memory. It is safe for concurrent use.
+// Items are returned as copies, so callers can't modify the stored data by accident.
+type Repository struct {
+	*store
+	expected items.ItemInterface // checked before changes, see Expecting
+}
+
+// store is the data of a Repository, shared with its Expecting views.
+type store struct {
+	mu     sync.Mutex
+	tasks  []items.Task
+	notes  []items.Note
+	tags   []items.Tag
+	lastId int
+}
+
+// NewRepository creates an empty in-memory repository.
+func NewRepository() *Repository {
+	return &Repository{store: &store{}}
+}
+
+// Expecting returns a view of the repository whose changes fail with
+// repository.ErrConflict if the item expected isn't stored as it is.
+func (r *Repository) Expecting(expected items.ItemInterface) repository.Repository {
+	return &Repository{store: r.store, expected: expected}
+}
+
+// Data holds the contents of a Repository, e.g. to persist it. Item tags refer to
+// tags by name.
+type Data struct {
+	Tasks []items.Task
+	Notes []items.Note
+	Tags  []items.Tag
+}
+
+// NewRepositoryFrom creates a repository holding a copy of data. Tags used by items
+// but missing from data.Tags are added. New items get IDs after the highest numeric ID.
+func NewRepositoryFrom(data Data) *Repository {
+	r := NewRepository()
+	for _, tag := range data.Tags {
+		r.addTag(tag)
+	}
+	for _, t := range data.Tasks {
+		t = copyTask(t)
+		t.Tag = r.resolveTag(t.Tag)
+		r.tasks = append(r.tasks, t)
+		r.reserveId(t.Id)
+	}
+	for _, n := range data.Notes {
+		n = copyNote(n)
+		n.Tag = r.resolveTag(n.Tag)
+		r.notes = append(r.notes, n)
+		r.reserveId(n.Id)
+	}
+	return r
+}
+
+// Data returns a copy of the repository contents. Items keep their insertion order
+// and tags are sorted by name.
+func (r *Repository) Data() Data {
+	tasks, _ := r.GetTasks()
+	notes, _ := r.GetNotes()
+	tags, _ := r.GetTags()
+	return Data{Tasks: tasks, Notes: notes, Tags: tags}
+}
+
+func (r *Repository) addTag(tag items.Tag) *items.Tag {
+	if tag.Id == "" {
+		tag.Id = r.nextId()
+	}
+	r.reserveId(tag.Id)
+	r.tags = append(r.tags, tag)
+	return &r.tags[len(r.tags)-1]
+}
+
+// resolveTag returns a copy of the stored tag with the same name, adding it if needed.
+func (r *Repository) resolveTag(tag *items.Tag) *items.Tag {
+	if tag == nil {
+		return nil
+	}
+	stored := r.findTag(tag.Name)
+	if stored == nil {
+		stored = r.addTag(items.Tag{Name: tag.Name})
+	}
+	return copyTag(stored)
+}
+
+// reserveId makes sure new IDs don't collide with a numeric ID loaded from storage.
+func (r *Repository) reserveId(id string) {
+	if n, err := strconv.Atoi(id); err == nil && n > r.lastId {
+		r.lastId = n
+	}
+}
+
+func (r *Repository) nextId() string {
+	r.lastId++
+	return strconv.Itoa(r.lastId)
+}
+
+// Reset removes all the stored items and tags.
+func (r *Repository) Reset() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tasks = nil
+	r.notes = nil
+	r.tags = nil
+	return nil
+}
+
+func copyTag(tag *items.Tag) *items.Tag {
+	if tag == nil {
+		return nil
+	}
+	c := *tag
+	return &c
+}
+
+func copyTask(t items.Task) items.Task {
+	t.Tag = copyTag(t.Tag)
+	t.StatusHistory = append([]items.StatusChange(nil), t.StatusHistory...)
+	t.TimeLog = append([]items.TimeEntry(nil), t.TimeLog...)
+	return t
+}
+
+func copyNote(n items.Note) items.Note {
+	n.Tag = copyTag(n.Tag)
+	return n
+}
+
+func (r *Repository) taskIndex(id string) int {
+	for i, t := range r.tasks {
+		if t.Id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (r *Repository) noteIndex(id string) int {
+	for i, n := range r.notes {
+		if n.Id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// storedTask returns the index of a task, once the item expected by an Expecting view
+// is checked. The lock must be held.
+func (r *Repository) storedTask(id string) (int, error) {
+	if err := repository.CheckExpected(r.expected, r.tasks, r.notes); err != nil {
+		return -1, err
+	}
+	i := r.taskIndex(id)
+	if i == -1 {
+		return -1, repository.ErrNotFound
+	}
+	return i, nil
+}
+
+// storedNote is storedTask for notes.
+func (r *Repository) storedNote(id string) (int, error) {
+	if err := repository.CheckExpected(r.expected, r.tasks, r.notes); err != nil {
+		return -1, err
+	}
+	i := r.noteIndex(id)
+	if i == -1 {
+		return -1, repository.ErrNotFound
+	}
+	return i, nil
+}
+
+func (r *Repository) findTag(name string) *items.Tag {
+	for i := range r.tags {
+		if r.tags[i].Name == name {
+			return &r.tags[i]
+		}
+	}
+	return nil
+}
+
+func (r *Repository) GetTasks() ([]items.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tasks := make([]items.Task, 0, len(r.tasks))
+	for _, t := range r.tasks {
+		tasks = append(tasks, copyTask(t))
+	}
+	return tasks, nil
+}
+
+func (r *Repository) CreateTask(t *items.Task) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now()
+	}
+	if t.UpdatedAt.IsZero() {
+		t.UpdatedAt = t.CreatedAt
+	}
+	if t.Status == "" {
+		t.Status = items.Todo
+	}
+	t.InitStatusHistory()
+	t.Id = r.nextId()
+	// Tags are assigned with SetTaskTag, like in the other repositories
+	stored := copyTask(*t)
+	stored.Tag = nil
+	r.tasks = append(r.tasks, stored)
+	return nil
+}
+
+// UpdateTask updates the task fields. The tag is left untouched, use SetTaskTag/UnsetTaskTag.
+func (r *Repository) UpdateTask(t items.Task) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, err := r.storedTask(t.Id)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	updated := copyTask(t).ReplacingStatusOf(r.tasks[i], now)
+	updated.Tag = r.tasks[i].Tag
+	if updated.CreatedAt.IsZero() {
+		updated.CreatedAt = r.tasks[i].CreatedAt
+	}
+	updated.UpdatedAt = now
+	r.tasks[i] = updated
+	return nil
+}
+
+func (r *Repository) RemoveTask(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, err := r.storedTask(id)
+	if err != nil {
+		return err
+	}
+	r.tasks = append(r.tasks[:i], r.tasks[i+1:]...)
+	return nil
+}
+
+func (r *Repository) UpdateTaskStatus(t items.Task, s items.Status) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, err := r.storedTask(t.Id)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	r.tasks[i].ChangeStatus(s, now)
+	r.tasks[i].UpdatedAt = now
+	return nil
+}
+
+func (r *Repository) SetTaskTag(t items.Task, tag items.Tag) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, err := r.storedTask(t.Id)
+	if err != nil {
+		return err
+	}
+	stored := r.findTag(tag.Name)
+	if stored == nil {
+		return fmt.Errorf("tag %q: %w", tag.Name, repository.ErrNotFound)
+	}
+	r.tasks[i].Tag = copyTag(stored)
+	r.tasks[i].UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *Repository) UnsetTaskTag(t items.Task) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, err := r.storedTask(t.Id)
+	if err != nil {
+		return err
+	}
+	r.tasks[i].Tag = nil
+	r.tasks[i].UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *Repository) GetNotes() ([]items.Note, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	notes := make([]items.Note, 0, len(r.notes))
+	for _, n := range r.notes {
+		notes = append(notes, copyNote(n))
+	}
+	return notes, nil
+}
+
+func (r *Repository) CreateNote(n *items.Note) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if n.CreatedAt.IsZero() {
+		n.CreatedAt = time.Now()
+	}
+	if n.UpdatedAt.IsZero() {
+		n.UpdatedAt = n.CreatedAt
+	}
+	n.Id = r.nextId()
+	stored := copyNote(*n)
+	stored.Tag = nil
+	r.notes = append(r.notes, stored)
+	return nil
+}
+
+// UpdateNote updates the note fields. The tag is left untouched, use SetNoteTag/UnsetNoteTag.
+func (r *Repository) UpdateNote(n items.Note) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, err := r.storedNote(n.Id)
+	if err != nil {
+		return err
+	}
+	updated := copyNote(n)
+	updated.Tag = r.notes[i].Tag
+	if updated.CreatedAt.IsZero() {
+		updated.CreatedAt = r.notes[i].CreatedAt
+	}
+	updated.UpdatedAt = time.Now()
+	r.notes[i] = updated
+	return nil
+}
+
+func (r *Repository) RemoveNote(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, err := r.storedNote(id)
+	if err != nil {
+		return err
+	}
+	r.notes = append(r.notes[:i], r.notes[i+1:]...)
+	return nil
+}
+
+func (r *Repository) SetNoteTag(n items.Note, tag items.Tag) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, err := r.storedNote(n.Id)
+	if err != nil {
+		return err
+	}
+	stored := r.findTag(tag.Name)
+	if stored == nil {
+		return fmt.Errorf("tag %q: %w", tag.Name, repository.ErrNotFound)
+	}
+	r.notes[i].Tag = copyTag(stored)
+	r.notes[i].UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *Repository) UnsetNoteTag(n items.Note) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, err := r.storedNote(n.Id)
+	if err != nil {
+		return err
+	}
+	r.notes[i].Tag = nil
+	r.notes[i].UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *Repository) GetTag(name string) (*items.Tag, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tag := r.findTag(name)
+	if tag == nil {
+		return nil, repository.ErrNotFound
+	}
+	return copyTag(tag), nil
+}
+
+// GetTags returns all tags sorted by name.
+func (r *Repository) GetTags() ([]items.Tag, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tags := make([]items.Tag, len(r.tags))
+	copy(tags, r.tags)
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].Name < tags[j].Name
+	})
+	return tags, nil
+}
+
+func (r *Repository) CreateTag(name string) (*items.Tag, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.findTag(name) != nil {
+		return nil, fmt.Errorf("tag %q already exists", name)
+	}
+	tag := items.Tag{Id: r.nextId(), Name: name}
+	r.tags = append(r.tags, tag)
+	return copyTag(&tag), nil
+}
+
+// RemoveTag removes the tag, items using it are left without a tag.
+func (r *Repository) RemoveTag(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, tag := range r.tags {
+		if tag.Name != name {
+			continue
+		}
+		r.tags = append(r.tags[:i], r.tags[i+1:]...)
+		for j := range r.tasks {
+			if r.tasks[j].Tag != nil && r.tasks[j].Tag.Name == name {
+				r.tasks[j].Tag = nil
+			}
+		}
+		for j := range r.notes {
+			if r.notes[j].Tag != nil && r.notes[j].Tag.Name == name {
+				r.notes[j].Tag = nil
+			}
+		}
+		return nil
+	}
+	return repository.ErrNotFound
+}
+
+func (r *Repository) GetItemsWithTag(name string) ([]items.ItemInterface, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var result []items.ItemInterface
+	for _, t := range r.tasks {
+		if t.Tag != nil && t.Tag.Name == name {
+			task := copyTask(t)
+			result = append(result, &task)
+		}
+	}
+	for _, n := range r.notes {
+		if n.Tag != nil && n.Tag.Name == name {
+			note := copyNote(n)
+			result = append(result, &note)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,27 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/markelca/prioritty/pkg/items/repository"
+	"github.com/markelca/prioritty/pkg/items/repository/memory"
+	"github.com/markelca/prioritty/pkg/items/repository/repositorytest"
+)
+
+func TestRepository(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repository.Repository {
+		return memory.NewRepository()
+	})
+}
+
+// plain hides the Expecting method of the repository, like the repositories of
+// other modules that don't implement repository.Expecter.
+type plain struct {
+	repository.Repository
+}
+
+func TestRepositoryWithoutExpecter(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repository.Repository {
+		return plain{memory.NewRepository()}
+	})
+}
@@ -0,0 +1,202 @@
+package obsidian
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/markelca/prioritty/pkg/items/repository"
+	"github.com/markelca/prioritty/pkg/items/repository/internal/atomicfile"
+	"github.com/markelca/prioritty/pkg/markdown"
+)
+
+// errInlineHistory is returned for the history of inline tasks, which share their
+// file (and its git history) with other items.
+var errInlineHistory = errors.New("history is only kept for task and note files, not inline tasks")
+
+// WithGitHistory makes every change to the vault a git commit with a message
+// describing it, e.g. "done: Fix bug in authentication". The vault must be
+// inside a git repository.
+func WithGitHistory(enabled bool) Option {
+	return func(r *ObsidianRepository) {
+		r.gitHistory = enabled
+	}
+}
+
+// CheckGitRepository returns an error if the vault isn't inside a git repository.
+func (r *ObsidianRepository) CheckGitRepository() error {
+	if _, err := r.git("rev-parse", "--show-toplevel"); err != nil {
+		return fmt.Errorf("%s is not in a git repository: %w", r.vaultPath, err)
+	}
+	return nil
+}
+
+// git runs a git command in the vault and returns its output.
+func (r *ObsidianRepository) git(args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"--literal-pathspecs", "-C", r.vaultPath}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return string(out), nil
+}
+
+// commit records the changes to the given files as a git commit when git history
+// is enabled. Other changes of the repository, staged or not, are left out. It must
+// be called holding the vault lock. Failures are only logged: the change itself
+// was made, and the next commit of the file will include it.
+func (r *ObsidianRepository) commit(message string, paths ...string) {
+	if !r.gitHistory {
+		return
+	}
+	if err := r.gitCommit(message, paths); err != nil {
+		log.Printf("Error committing %q: %v", message, err)
+	}
+}
+
+func (r *ObsidianRepository) gitCommit(message string, paths []string) error {
+	var all, existing, removed []string
+	for _, p := range paths {
+		rel := filepath.ToSlash(relativeID(r.vaultPath, p))
+		all = append(all, rel)
+		if _, err := os.Stat(p); err == nil {
+			existing = append(existing, rel)
+		} else {
+			removed = append(removed, rel)
+		}
+	}
+
+	if len(existing) > 0 {
+		if _, err := r.git(append([]string{"add", "--"}, existing...)...); err != nil {
+			return err
+		}
+	}
+	if len(removed) > 0 {
+		if _, err := r.git(append([]string{"rm", "--cached", "--quiet", "--ignore-unmatch", "--"}, removed...)...); err != nil {
+			return err
+		}
+	}
+
+	// Only the files that changed, committing an unchanged or untracked path fails
+	out, err := r.git(append([]string{"diff", "--cached", "--name-only", "--no-renames", "--relative", "-z", "--"}, all...)...)
+	if err != nil {
+		return err
+	}
+	staged := strings.FieldsFunc(out, func(c rune) bool { return c == 0 })
+	if len(staged) == 0 {
+		return nil
+	}
+	_, err = r.git(append([]string{"commit", "--quiet", "-m", message, "--"}, staged...)...)
+	return err
+}
+
+// fileTitle returns the title of an item file, or its name if it can't be read.
+func fileTitle(path string) string {
+	content, err := os.ReadFile(path)
+	if err == nil {
+		var fm markdown.Frontmatter
+		if _, err := markdown.Parse(string(content), &fm); err == nil && fm.Title != "" {
+			return fm.Title
+		}
+	}
+	return strings.TrimSuffix(filepath.Base(path), ".md")
+}
+
+// historyEntry is a commit that changed an item file, with the file's path
+// (relative to the repository root) at that commit.
+type historyEntry struct {
+	repository.Revision
+	path string
+}
+
+// history lists the commits that changed the file of an item, newest first,
+// following renames.
+func (r *ObsidianRepository) history(id string) ([]historyEntry, error) {
+	if isInlineID(id) {
+		return nil, errInlineHistory
+	}
+	if _, err := os.Stat(fullPathFromID(r.vaultPath, id)); err != nil {
+		return nil, notFound(err)
+	}
+
+	out, err := r.git("-c", "core.quotePath=false", "log", "--follow", "--name-only",
+		"--format=%x1e%H%x1f%aI%x1f%s", "--", filepath.ToSlash(id))
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []historyEntry
+	for _, record := range strings.Split(out, "\x1e") {
+		lines := strings.Split(strings.TrimSpace(record), "\n")
+		fields := strings.Split(lines[0], "\x1f")
+		if len(fields) != 3 || len(lines) < 2 {
+			continue
+		}
+		t, _ := time.Parse(time.RFC3339, fields[1])
+		entries = append(entries, historyEntry{
+			Revision: repository.Revision{Id: fields[0], Time: t, Message: fields[2]},
+			path:     strings.TrimSpace(lines[len(lines)-1]),
+		})
+	}
+	return entries, nil
+}
+
+// ItemHistory lists the git commits that changed an item, newest first.
+func (r *ObsidianRepository) ItemHistory(id string) ([]repository.Revision, error) {
+	entries, err := r.history(id)
+	if err != nil {
+		return nil, err
+	}
+	revisions := make([]repository.Revision, len(entries))
+	for i, e := range entries {
+		revisions[i] = e.Revision
+	}
+	return revisions, nil
+}
+
+// RevertItem restores the file of an item to its content at a commit of its history.
+// rev can be abbreviated. The file keeps its current name.
+func (r *ObsidianRepository) RevertItem(id, rev string) error {
+	entries, err := r.history(id)
+	if err != nil {
+		return err
+	}
+
+	var found *historyEntry
+	for i, e := range entries {
+		if rev != "" && strings.HasPrefix(e.Id, rev) {
+			if found != nil {
+				return fmt.Errorf("revision %s is ambiguous", rev)
+			}
+			found = &entries[i]
+		}
+	}
+	if found == nil {
+		return fmt.Errorf("revision %s isn't in the history of %s: %w", rev, id, repository.ErrNotFound)
+	}
+
+	content, err := r.git("show", found.Id+":"+found.path)
+	if err != nil {
+		return err
+	}
+
+	return r.withLock(func() error {
+		path := fullPathFromID(r.vaultPath, id)
+		if err := atomicfile.WriteFile(path, []byte(content)); err != nil {
+			return err
+		}
+		r.commit(fmt.Sprintf("revert: %s to %s", fileTitle(path), found.Id[:7]), path)
+		return nil
+	})
+}
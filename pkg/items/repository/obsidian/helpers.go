@@ -3,62 +3,16 @@ package obsidian
 import (
 	"os"
 	"path/filepath"
-	"regexp"
 	"strconv"
 	"strings"
-	"unicode"
 
-	"golang.org/x/text/unicode/norm"
+	"github.com/markelca/prioritty/pkg/items/repository"
+	"github.com/markelca/prioritty/pkg/markdown"
 )
 
-// toKebabCase converts a title string to kebab-case for use as filename.
-// It handles unicode normalization, removes special characters, and converts spaces to hyphens.
-func toKebabCase(title string) string {
-	// Normalize unicode (NFD) and remove diacritics
-	normalized := norm.NFD.String(title)
-	var builder strings.Builder
-	for _, r := range normalized {
-		if unicode.Is(unicode.Mn, r) {
-			// Skip combining marks (diacritics)
-			continue
-		}
-		builder.WriteRune(r)
-	}
-	result := builder.String()
-
-	// Convert to lowercase
-	result = strings.ToLower(result)
-
-	// Replace spaces and underscores with hyphens
-	result = strings.ReplaceAll(result, " ", "-")
-	result = strings.ReplaceAll(result, "_", "-")
-
-	// Remove invalid filesystem characters: / \ : * ? " < > |
-	invalidChars := regexp.MustCompile(`[/\\:*?"<>|]`)
-	result = invalidChars.ReplaceAllString(result, "")
-
-	// Remove any character that's not alphanumeric or hyphen
-	validChars := regexp.MustCompile(`[^a-z0-9-]`)
-	result = validChars.ReplaceAllString(result, "")
-
-	// Collapse multiple hyphens into one
-	multiHyphen := regexp.MustCompile(`-+`)
-	result = multiHyphen.ReplaceAllString(result, "-")
-
-	// Trim leading/trailing hyphens
-	result = strings.Trim(result, "-")
-
-	// If result is empty, use a default
-	if result == "" {
-		result = "untitled"
-	}
-
-	return result
-}
-
 // filenameFromTitle generates a .md filename from a title.
 func filenameFromTitle(title string) string {
-	return toKebabCase(title) + ".md"
+	return markdown.Slug(title) + ".md"
 }
 
 // FilenameFromTitle is the exported version of filenameFromTitle.
@@ -87,18 +41,27 @@ func scanMarkdownFiles(vaultPath string) ([]string, error) {
 	return files, nil
 }
 
-// uniqueFilename generates a unique filename by appending a counter if needed.
-// Returns the full path to the file.
-func uniqueFilename(vaultPath, title string) string {
-	base := toKebabCase(title)
+// createUniqueFile creates a new file named after the title, appending a counter
+// if the name is already taken. The file is created with O_EXCL so two writers can
+// never claim the same filename. Returns the full path to the file.
+func createUniqueFile(vaultPath, title string, content []byte) (string, error) {
+	base := markdown.Slug(title)
 	filename := base + ".md"
 	fullPath := filepath.Join(vaultPath, filename)
 
-	// Check if file exists, if so append counter
 	counter := 2
 	for {
-		if _, err := os.Stat(fullPath); os.IsNotExist(err) {
-			return fullPath
+		f, err := os.OpenFile(fullPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			if _, err := f.Write(content); err != nil {
+				f.Close()
+				os.Remove(fullPath)
+				return "", err
+			}
+			return fullPath, f.Close()
+		}
+		if !os.IsExist(err) {
+			return "", err
 		}
 		filename = base + "-" + strconv.Itoa(counter) + ".md"
 		fullPath = filepath.Join(vaultPath, filename)
@@ -120,3 +83,11 @@ func relativeID(vaultPath, fullPath string) string {
 func fullPathFromID(vaultPath, id string) string {
 	return filepath.Join(vaultPath, id)
 }
+
+// notFound reports a missing item file as repository.ErrNotFound.
+func notFound(err error) error {
+	if os.IsNotExist(err) {
+		return repository.ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,359 @@
+package obsidian
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/markelca/prioritty/pkg/items"
+	"github.com/markelca/prioritty/pkg/items/repository"
+	"github.com/markelca/prioritty/pkg/items/repository/internal/atomicfile"
+	"github.com/markelca/prioritty/pkg/markdown"
+)
+
+// inlineAnchor separates the file path from the line number in inline task IDs,
+// e.g. "meetings/weekly.md#L12".
+const inlineAnchor = "#L"
+
+// inlineTagPattern matches Obsidian "#tag" tokens inside a checklist line.
+var inlineTagPattern = regexp.MustCompile(`(^|\s)#([\p{L}\p{N}_/-]+)`)
+
+// checkboxByStatus maps task statuses to the checkbox character written back to the file.
+var checkboxByStatus = map[items.Status]rune{
+	items.Todo:       ' ',
+	items.InProgress: '/',
+	items.Done:       'x',
+	items.Cancelled:  '-',
+}
+
+func statusFromCheckbox(c rune) items.Status {
+	switch c {
+	case 'x', 'X':
+		return items.Done
+	case '/':
+		return items.InProgress
+	case '-':
+		return items.Cancelled
+	default:
+		return items.Todo
+	}
+}
+
+func checkboxFromStatus(s items.Status) rune {
+	if c, ok := checkboxByStatus[s]; ok {
+		return c
+	}
+	return ' '
+}
+
+// IsInline reports whether id is the ID of an inline task, a checklist line of a note.
+func (r *ObsidianRepository) IsInline(id string) bool {
+	return isInlineID(id)
+}
+
+// isInlineID reports whether the ID refers to a checklist line inside a file.
+func isInlineID(id string) bool {
+	return strings.Contains(id, inlineAnchor)
+}
+
+func inlineID(relPath string, line int) string {
+	return relPath + inlineAnchor + strconv.Itoa(line)
+}
+
+// parseInlineID splits an inline task ID into its file path and 1-based line number.
+func parseInlineID(id string) (string, int, error) {
+	idx := strings.LastIndex(id, inlineAnchor)
+	if idx == -1 {
+		return "", 0, fmt.Errorf("not an inline task id: %s", id)
+	}
+	line, err := strconv.Atoi(id[idx+len(inlineAnchor):])
+	if err != nil || line < 1 {
+		return "", 0, fmt.Errorf("invalid line in inline task id: %s", id)
+	}
+	return id[:idx], line, nil
+}
+
+// splitInlineText separates the first "#tag" of a checklist text from the title.
+func splitInlineText(text string) (title string, tag string) {
+	m := inlineTagPattern.FindStringSubmatchIndex(text)
+	if m == nil {
+		return strings.TrimSpace(text), ""
+	}
+	tag = text[m[4]:m[5]]
+	title = text[:m[0]] + text[m[1]:]
+	return strings.Join(strings.Fields(title), " "), tag
+}
+
+// joinInlineText builds the checklist text back from a title and an optional tag.
+func joinInlineText(title string, tag string) string {
+	if tag == "" {
+		return title
+	}
+	return title + " #" + tag
+}
+
+// scanVaultMarkdownFiles returns all .md files in the vault, recursively.
+// Hidden directories such as .obsidian and .git are skipped.
+func scanVaultMarkdownFiles(vaultPath string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(vaultPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != vaultPath && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(strings.ToLower(d.Name()), ".md") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// getInlineTasks returns the checklist items of every markdown file in the vault.
+func (r *ObsidianRepository) getInlineTasks() ([]items.Task, error) {
+	if !r.inlineTasks {
+		return nil, nil
+	}
+
+	files, err := scanVaultMarkdownFiles(r.vaultPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []items.Task
+	for _, filePath := range files {
+		info, err := os.Stat(filePath)
+		if err != nil {
+			log.Printf("Warning: failed to stat file %s: %v", filePath, err)
+			continue
+		}
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			log.Printf("Warning: failed to read file %s: %v", filePath, err)
+			continue
+		}
+
+		relPath := relativeID(r.vaultPath, filePath)
+		for _, cl := range markdown.FindChecklistItems(string(content)) {
+			task := taskFromChecklist(cl.Item, inlineID(relPath, cl.Line))
+			task.UpdatedAt = info.ModTime()
+			// Lines without a ➕ created date use the file's modification time to keep ordering stable
+			if task.CreatedAt.IsZero() {
+				task.CreatedAt = info.ModTime()
+			}
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
+// taskFromChecklist creates a Task from a checklist line in the Obsidian Tasks plugin format.
+func taskFromChecklist(c markdown.ChecklistItem, id string) items.Task {
+	line := markdown.ParseTaskLine(c.Text)
+	title, tagName := splitInlineText(line.Description)
+	var tag *items.Tag
+	if tagName != "" {
+		tag = &items.Tag{Id: tagName, Name: tagName}
+	}
+	return items.Task{
+		Item: items.Item{
+			Id:        id,
+			UID:       line.ID,
+			Title:     title,
+			Tag:       tag,
+			CreatedAt: line.Created,
+		},
+		Status:        statusFromCheckbox(c.Checkbox),
+		Priority:      line.Priority,
+		DueDate:       line.Due,
+		ScheduledDate: line.Scheduled,
+		Recurrence:    line.Recurrence,
+		CompletedAt:   line.Done,
+	}
+}
+
+// updateInlineTask rewrites the checklist line of the inline task t in place. The line
+// referenced by its ID must have its 🆔 or, without one, its title; if the line moved (e.g.
+// lines were inserted above it) it's looked up by them instead, and repository.ErrConflict
+// is returned if that fails. A task without ID nor title takes the line as it is. The task
+// expected by an Expecting view is used instead of t, and checked on the line found.
+// fn returns the lines replacing the current one: none removes it, more than one inserts lines.
+// action describes the change in the git history.
+func (r *ObsidianRepository) updateInlineTask(t items.Task, action string, fn func(markdown.ChecklistItem) []markdown.ChecklistItem) error {
+	id := t.Id
+	relPath, line, err := parseInlineID(id)
+	if err != nil {
+		return err
+	}
+	others := r.expected
+	var expected items.ItemInterface
+	if others != nil && others.GetId() == id {
+		expected, others = others, nil
+	}
+	title, uid := t.Title, t.UID
+	if expected != nil {
+		title, uid = expected.GetTitle(), expected.GetUID()
+	}
+
+	return r.withLockExpecting(others, func() error {
+		filePath := fullPathFromID(r.vaultPath, relPath)
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return repository.ErrNotFound
+			}
+			return err
+		}
+
+		lines := strings.Split(string(content), "\n")
+		idx, err := locateChecklistLine(string(content), line, title, uid)
+		if err != nil {
+			return err
+		}
+
+		current, _ := markdown.ParseChecklistItem(strings.TrimRight(lines[idx], "\r"))
+		if stored := taskFromChecklist(current, id); expected != nil && !items.SameContent(expected, &stored) {
+			return repository.ErrConflict
+		}
+		var replacement []string
+		for _, c := range fn(current) {
+			replacement = append(replacement, c.String())
+		}
+		lines = append(lines[:idx], append(replacement, lines[idx+1:]...)...)
+
+		if err := atomicfile.WriteFile(filePath, []byte(strings.Join(lines, "\n"))); err != nil {
+			return err
+		}
+		title, _ := splitInlineText(markdown.ParseTaskLine(current.Text).Description)
+		r.commit(action+": "+title, filePath)
+		return nil
+	})
+}
+
+// locateChecklistLine returns the 0-based index of the checklist line for an inline task,
+// with the 🆔 uid if not empty, or the title otherwise.
+func locateChecklistLine(content string, line int, title, uid string) (int, error) {
+	checklist := markdown.FindChecklistItems(content)
+
+	matches := func(text string) bool {
+		task := markdown.ParseTaskLine(text)
+		if uid != "" && task.ID == uid {
+			return true
+		}
+		t, _ := splitInlineText(task.Description)
+		return title == "" && uid == "" || t == title && (uid == "" || task.ID == "")
+	}
+	var found []int
+	for _, cl := range checklist {
+		if !matches(cl.Item.Text) {
+			continue
+		}
+		if cl.Line == line {
+			return cl.Line - 1, nil
+		}
+		found = append(found, cl.Line-1)
+	}
+
+	if len(found) == 1 && (title != "" || uid != "") {
+		return found[0], nil
+	}
+	return 0, repository.ErrConflict
+}
+
+// applyStatus sets the checkbox and the done/cancelled dates the way the Obsidian Tasks plugin
+// does. Completing a recurring task inserts its next occurrence above it.
+func applyStatus(c markdown.ChecklistItem, status items.Status, now time.Time) []markdown.ChecklistItem {
+	if statusFromCheckbox(c.Checkbox) == status {
+		return []markdown.ChecklistItem{c}
+	}
+
+	line := markdown.ParseTaskLine(c.Text)
+	wasDone := statusFromCheckbox(c.Checkbox) == items.Done
+	c.Checkbox = checkboxFromStatus(status)
+
+	line.Done = time.Time{}
+	line.Cancelled = time.Time{}
+	switch status {
+	case items.Done:
+		line.Done = now
+	case items.Cancelled:
+		line.Cancelled = now
+	}
+	c.Text = line.String()
+
+	if status == items.Done && !wasDone {
+		if next, ok := line.NextOccurrence(now); ok {
+			n := c
+			n.Checkbox = checkboxFromStatus(items.Todo)
+			n.Text = next.String()
+			return []markdown.ChecklistItem{n, c}
+		}
+	}
+	return []markdown.ChecklistItem{c}
+}
+
+func (r *ObsidianRepository) updateInlineTaskStatus(t items.Task, status items.Status) error {
+	return r.updateInlineTask(t, string(status), func(c markdown.ChecklistItem) []markdown.ChecklistItem {
+		return applyStatus(c, status, time.Now())
+	})
+}
+
+// updateInlineTaskContent rewrites the checklist line of an inline task. Lines have no
+// room for time entries, so tasks with some fail with repository.ErrNoTimeLog. The line
+// is found by the title expected by an Expecting view, since t has the new one, or by
+// its 🆔.
+func (r *ObsidianRepository) updateInlineTaskContent(t items.Task) error {
+	if len(t.TimeLog) > 0 {
+		return fmt.Errorf("inline task %q: %w", t.Title, repository.ErrNoTimeLog)
+	}
+	return r.updateInlineTask(t, "edit", func(c markdown.ChecklistItem) []markdown.ChecklistItem {
+		line := markdown.ParseTaskLine(c.Text)
+		_, tag := splitInlineText(line.Description)
+		if t.Tag != nil {
+			tag = t.Tag.Name
+		}
+		line.Description = joinInlineText(t.Title, tag)
+		line.Priority = t.Priority
+		line.Due = t.DueDate
+		line.Scheduled = t.ScheduledDate
+		line.Recurrence = t.Recurrence
+		line.ID = t.UID
+		c.Text = line.String()
+
+		// Keep custom checkbox characters unless the status actually changed
+		return applyStatus(c, t.Status, time.Now())
+	})
+}
+
+func (r *ObsidianRepository) setInlineTaskTag(t items.Task, tagName string) error {
+	action := "untag"
+	if tagName != "" {
+		action = "tag #" + tagName
+	}
+	return r.updateInlineTask(t, action, func(c markdown.ChecklistItem) []markdown.ChecklistItem {
+		line := markdown.ParseTaskLine(c.Text)
+		title, _ := splitInlineText(line.Description)
+		line.Description = joinInlineText(title, tagName)
+		c.Text = line.String()
+		return []markdown.ChecklistItem{c}
+	})
+}
+
+// removeInlineTask removes the checklist line of an inline task, found by the title
+// expected by an Expecting view if it moved. Without one, it's the line referenced by id.
+func (r *ObsidianRepository) removeInlineTask(id string) error {
+	return r.updateInlineTask(items.Task{Item: items.Item{Id: id}}, "remove", func(c markdown.ChecklistItem) []markdown.ChecklistItem {
+		return nil
+	})
+}
@@ -1,6 +1,9 @@
 package obsidian
 
 import (
+	"log"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/markelca/prioritty/pkg/items"
@@ -9,28 +12,143 @@ import (
 
 const timeFormat = time.RFC3339
 
-// parseCreatedAt parses the created_at string to time.Time.
-func parseCreatedAt(s string) time.Time {
-	if s == "" {
-		return time.Now()
-	}
-	t, err := time.Parse(timeFormat, s)
+// parseCreatedAt parses the created_at string to time.Time. Missing or invalid values
+// fall back to the file's modification time, so the item keeps its place in the list
+// between runs. Times without a zone, like the ones set in Obsidian, are local.
+func parseCreatedAt(s string, fallback time.Time) time.Time {
+	t, err := items.ParseTimestamp(s, time.Local)
 	if err != nil {
-		return time.Now()
+		log.Printf("Warning: invalid created_at %q: %v", s, err)
+	}
+	if t.IsZero() {
+		return fallback
 	}
 	return t
 }
 
-// formatCreatedAt formats time.Time to the frontmatter string format.
+// formatCreatedAt formats time.Time to the frontmatter string format, in UTC.
 func formatCreatedAt(t time.Time) string {
 	if t.IsZero() {
-		return time.Now().Format(timeFormat)
+		t = time.Now()
 	}
-	return t.Format(timeFormat)
+	return t.UTC().Format(timeFormat)
+}
+
+// parseCompletedAt parses the completed_at field, ignoring invalid values.
+func parseCompletedAt(s string) time.Time {
+	t, err := items.ParseTimestamp(s, time.Local)
+	if err != nil {
+		log.Printf("Warning: invalid completed_at %q: %v", s, err)
+	}
+	return t
+}
+
+// parseStatusHistory parses the "<status> <time>" entries of the status_history field.
+func parseStatusHistory(entries []string) []items.StatusChange {
+	var history []items.StatusChange
+	for _, e := range entries {
+		status, at, _ := strings.Cut(strings.TrimSpace(e), " ")
+		t, err := items.ParseTimestamp(at, time.Local)
+		if err != nil || t.IsZero() {
+			log.Printf("Warning: invalid status_history entry %q", e)
+			continue
+		}
+		history = append(history, items.StatusChange{Status: items.ParseStatus(status), At: t})
+	}
+	return history
+}
+
+func formatStatusHistory(history []items.StatusChange) []string {
+	var entries []string
+	for _, c := range history {
+		entries = append(entries, string(c.Status)+" "+items.FormatTimestamp(c.At))
+	}
+	return entries
+}
+
+// pomodoroMark ends the time_log entries of pomodoros.
+const pomodoroMark = "pomodoro"
+
+// parseTimeLog parses the "<start> <end>" entries of the time_log field, running
+// entries having only a start and pomodoros ending with pomodoroMark.
+func parseTimeLog(entries []string) []items.TimeEntry {
+	var timeLog []items.TimeEntry
+	for _, e := range entries {
+		fields := strings.Fields(e)
+		pomodoro := len(fields) == 3 && fields[2] == pomodoroMark
+		if pomodoro {
+			fields = fields[:2]
+		}
+		if len(fields) == 0 || len(fields) > 2 {
+			log.Printf("Warning: invalid time_log entry %q", e)
+			continue
+		}
+		s, err := items.ParseTimestamp(fields[0], time.Local)
+		if err != nil || s.IsZero() {
+			log.Printf("Warning: invalid time_log entry %q", e)
+			continue
+		}
+		var t time.Time
+		if len(fields) == 2 {
+			if t, err = items.ParseTimestamp(fields[1], time.Local); err != nil {
+				log.Printf("Warning: invalid time_log entry %q", e)
+				continue
+			}
+		}
+		timeLog = append(timeLog, items.TimeEntry{Start: s, End: t, Pomodoro: pomodoro})
+	}
+	return timeLog
+}
+
+func formatTimeLog(timeLog []items.TimeEntry) []string {
+	var entries []string
+	for _, e := range timeLog {
+		entry := strings.TrimSpace(items.FormatTimestamp(e.Start) + " " + items.FormatTimestamp(e.End))
+		if e.Pomodoro {
+			entry += " " + pomodoroMark
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// modTime returns the modification time of a file, which is the time its item was
+// last updated. It returns the zero time if the file can't be read.
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// parseDate parses a date-only frontmatter field, ignoring invalid values.
+func parseDate(s string) time.Time {
+	d, err := items.ParseDate(s)
+	if err != nil {
+		log.Printf("Warning: invalid date %q: %v", s, err)
+		return time.Time{}
+	}
+	return d
+}
+
+func parseEstimate(s string) items.Estimate {
+	e, err := items.ParseEstimate(s)
+	if err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	return e
+}
+
+// trimBody drops the trailing newline added when the file was serialized,
+// so bodies read back are the same as the ones stored.
+func trimBody(body string) string {
+	return strings.TrimSuffix(body, "\n")
 }
 
-// taskFromFrontmatter creates a Task from frontmatter data.
-func taskFromFrontmatter(fm markdown.Frontmatter, body, id string) items.Task {
+// taskFromFrontmatter creates a Task from frontmatter data, modified being the
+// modification time of its file.
+func taskFromFrontmatter(fm markdown.Frontmatter, body, id string, modified time.Time) items.Task {
 	var tag *items.Tag
 	if fm.Tag != "" {
 		tag = &items.Tag{
@@ -42,17 +160,28 @@ func taskFromFrontmatter(fm markdown.Frontmatter, body, id string) items.Task {
 	return items.Task{
 		Item: items.Item{
 			Id:        id,
+			UID:       fm.UID,
 			Title:     fm.Title,
-			Body:      body,
-			CreatedAt: parseCreatedAt(fm.CreatedAt),
+			Body:      trimBody(body),
+			CreatedAt: parseCreatedAt(fm.CreatedAt, modified),
+			UpdatedAt: modified,
 			Tag:       tag,
 		},
-		Status: items.ParseStatus(fm.Status),
+		Status:        items.ParseStatus(fm.Status),
+		Priority:      items.ParsePriority(fm.Priority),
+		DueDate:       parseDate(fm.Due),
+		ScheduledDate: parseDate(fm.Scheduled),
+		Recurrence:    fm.Recurrence,
+		Estimate:      parseEstimate(fm.Estimate),
+		CompletedAt:   parseCompletedAt(fm.CompletedAt),
+		StatusHistory: parseStatusHistory(fm.StatusHistory),
+		TimeLog:       parseTimeLog(fm.TimeLog),
 	}
 }
 
-// noteFromFrontmatter creates a Note from frontmatter data.
-func noteFromFrontmatter(fm markdown.Frontmatter, body, id string) items.Note {
+// noteFromFrontmatter creates a Note from frontmatter data, modified being the
+// modification time of its file.
+func noteFromFrontmatter(fm markdown.Frontmatter, body, id string, modified time.Time) items.Note {
 	var tag *items.Tag
 	if fm.Tag != "" {
 		tag = &items.Tag{
@@ -64,9 +193,11 @@ func noteFromFrontmatter(fm markdown.Frontmatter, body, id string) items.Note {
 	return items.Note{
 		Item: items.Item{
 			Id:        id,
+			UID:       fm.UID,
 			Title:     fm.Title,
-			Body:      body,
-			CreatedAt: parseCreatedAt(fm.CreatedAt),
+			Body:      trimBody(body),
+			CreatedAt: parseCreatedAt(fm.CreatedAt, modified),
+			UpdatedAt: modified,
 			Tag:       tag,
 		},
 	}
@@ -75,11 +206,20 @@ func noteFromFrontmatter(fm markdown.Frontmatter, body, id string) items.Note {
 // itemInputFromTask creates an ItemInput from a Task.
 func itemInputFromTask(t items.Task) markdown.ItemInput {
 	input := markdown.ItemInput{
-		ItemType:  items.ItemTypeTask,
-		Title:     t.Title,
-		Body:      t.Body,
-		Status:    string(t.Status),
-		CreatedAt: formatCreatedAt(t.CreatedAt),
+		ItemType:      items.ItemTypeTask,
+		Title:         t.Title,
+		Body:          t.Body,
+		Status:        string(t.Status),
+		Priority:      string(t.Priority),
+		Due:           items.FormatDate(t.DueDate),
+		Scheduled:     items.FormatDate(t.ScheduledDate),
+		Recurrence:    t.Recurrence,
+		Estimate:      t.Estimate.String(),
+		CreatedAt:     formatCreatedAt(t.CreatedAt),
+		UID:           t.UID,
+		CompletedAt:   items.FormatTimestamp(t.CompletedAt),
+		StatusHistory: formatStatusHistory(t.StatusHistory),
+		TimeLog:       formatTimeLog(t.TimeLog),
 	}
 	if t.Tag != nil {
 		input.Tag = t.Tag.Name
@@ -94,6 +234,7 @@ func itemInputFromNote(n items.Note) markdown.ItemInput {
 		Title:     n.Title,
 		Body:      n.Body,
 		CreatedAt: formatCreatedAt(n.CreatedAt),
+		UID:       n.UID,
 	}
 	if n.Tag != nil {
 		input.Tag = n.Tag.Name
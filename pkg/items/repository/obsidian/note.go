@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/markelca/prioritty/pkg/items"
+	"github.com/markelca/prioritty/pkg/items/repository/internal/atomicfile"
 	"github.com/markelca/prioritty/pkg/markdown"
 )
 
@@ -36,7 +37,7 @@ func (r *ObsidianRepository) GetNotes() ([]items.Note, error) {
 		}
 
 		id := relativeID(r.vaultPath, filePath)
-		note := noteFromFrontmatter(fm, body, id)
+		note := noteFromFrontmatter(fm, body, id, modTime(filePath))
 		notes = append(notes, note)
 	}
 
@@ -50,130 +51,102 @@ func (r *ObsidianRepository) CreateNote(n *items.Note) error {
 		n.CreatedAt = time.Now()
 	}
 
-	// Generate unique filename
-	filePath := uniqueFilename(r.vaultPath, n.Title)
-
 	// Serialize to markdown
 	content, err := markdown.Serialize(itemInputFromNote(*n))
 	if err != nil {
 		return err
 	}
 
-	// Write file
-	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
-		return err
-	}
+	return r.withLock(func() error {
+		// Write file under a unique filename
+		filePath, err := createUniqueFile(r.vaultPath, n.Title, []byte(content))
+		if err != nil {
+			return err
+		}
 
-	// Set the ID to the relative path
-	n.Id = relativeID(r.vaultPath, filePath)
-	return nil
+		// Set the ID to the relative path
+		n.Id = relativeID(r.vaultPath, filePath)
+		r.commit("add note: "+n.Title, filePath)
+		return nil
+	})
 }
 
 // UpdateNote updates an existing note file.
 func (r *ObsidianRepository) UpdateNote(n items.Note) error {
-	oldPath := fullPathFromID(r.vaultPath, n.Id)
-
-	// Read existing file to preserve created_at if not set
-	existingContent, err := os.ReadFile(oldPath)
-	if err != nil {
-		return err
-	}
-
-	var existingFm markdown.Frontmatter
-	if _, err := markdown.Parse(string(existingContent), &existingFm); err != nil {
-		return err
-	}
-
-	// Preserve created_at from existing file if not set on update
-	if n.CreatedAt.IsZero() {
-		n.CreatedAt = parseCreatedAt(existingFm.CreatedAt)
-	}
+	return r.withLock(func() error {
+		oldPath := fullPathFromID(r.vaultPath, n.Id)
 
-	// Serialize to markdown
-	content, err := markdown.Serialize(itemInputFromNote(n))
-	if err != nil {
-		return err
-	}
+		// Read existing file to preserve created_at if not set
+		existingContent, err := os.ReadFile(oldPath)
+		if err != nil {
+			return notFound(err)
+		}
 
-	// Check if title changed (requires rename)
-	newFilename := filenameFromTitle(n.Title)
-	oldFilename := relativeID(r.vaultPath, oldPath)
+		var existingFm markdown.Frontmatter
+		if _, err := markdown.Parse(string(existingContent), &existingFm); err != nil {
+			return err
+		}
 
-	if newFilename != oldFilename {
-		// Generate unique filename for new title
-		newPath := uniqueFilename(r.vaultPath, n.Title)
+		// Preserve created_at from existing file if not set on update
+		if n.CreatedAt.IsZero() {
+			n.CreatedAt = parseCreatedAt(existingFm.CreatedAt, modTime(oldPath))
+		}
 
-		// Write new file
-		if err := os.WriteFile(newPath, []byte(content), 0644); err != nil {
+		// Serialize to markdown
+		content, err := markdown.Serialize(itemInputFromNote(n))
+		if err != nil {
 			return err
 		}
 
-		// Remove old file
-		return os.Remove(oldPath)
-	}
+		// Rename only when the title changed, so files with a numeric suffix
+		// or named by hand keep their path (and ID)
+		if n.Title != existingFm.Title {
+			// Write new file under a unique filename for the new title
+			newPath, err := createUniqueFile(r.vaultPath, n.Title, []byte(content))
+			if err != nil {
+				return err
+			}
+
+			// Remove old file
+			if err := os.Remove(oldPath); err != nil {
+				return err
+			}
+			r.commit("edit: "+n.Title, newPath, oldPath)
+			return nil
+		}
 
-	// Title unchanged, write in place
-	return os.WriteFile(oldPath, []byte(content), 0644)
+		// Title unchanged, write in place
+		if err := atomicfile.WriteFile(oldPath, []byte(content)); err != nil {
+			return err
+		}
+		r.commit("edit: "+n.Title, oldPath)
+		return nil
+	})
 }
 
 // RemoveNote removes a note file from the vault.
 func (r *ObsidianRepository) RemoveNote(id string) error {
-	filePath := fullPathFromID(r.vaultPath, id)
-	return os.Remove(filePath)
+	return r.withLock(func() error {
+		path := fullPathFromID(r.vaultPath, id)
+		title := fileTitle(path)
+		if err := os.Remove(path); err != nil {
+			return notFound(err)
+		}
+		r.commit("remove: "+title, path)
+		return nil
+	})
 }
 
 // SetNoteTag sets the tag on a note.
 func (r *ObsidianRepository) SetNoteTag(n items.Note, tag items.Tag) error {
-	filePath := fullPathFromID(r.vaultPath, n.Id)
-
-	// Read existing file
-	content, err := os.ReadFile(filePath)
-	if err != nil {
-		return err
-	}
-
-	var fm markdown.Frontmatter
-	body, err := markdown.Parse(string(content), &fm)
-	if err != nil {
-		return err
-	}
-
-	// Update tag
-	fm.Tag = tag.Name
-
-	// Serialize and write back
-	newContent, err := fm.Serialize(body)
-	if err != nil {
-		return err
-	}
-
-	return os.WriteFile(filePath, newContent, 0644)
+	return r.updateFrontmatter(n.Id, "tag #"+tag.Name, func(fm *markdown.Frontmatter) {
+		fm.Tag = tag.Name
+	})
 }
 
 // UnsetNoteTag removes the tag from a note.
 func (r *ObsidianRepository) UnsetNoteTag(n items.Note) error {
-	filePath := fullPathFromID(r.vaultPath, n.Id)
-
-	// Read existing file
-	content, err := os.ReadFile(filePath)
-	if err != nil {
-		return err
-	}
-
-	var fm markdown.Frontmatter
-	body, err := markdown.Parse(string(content), &fm)
-	if err != nil {
-		return err
-	}
-
-	// Remove tag
-	fm.Tag = ""
-
-	// Serialize and write back
-	newContent, err := fm.Serialize(body)
-	if err != nil {
-		return err
-	}
-
-	return os.WriteFile(filePath, newContent, 0644)
+	return r.updateFrontmatter(n.Id, "untag", func(fm *markdown.Frontmatter) {
+		fm.Tag = ""
+	})
 }
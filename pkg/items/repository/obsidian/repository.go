@@ -3,18 +3,43 @@ package obsidian
 import (
 	"os"
 	"path/filepath"
+
+	"github.com/markelca/prioritty/pkg/items"
+	"github.com/markelca/prioritty/pkg/items/repository"
+	"github.com/markelca/prioritty/pkg/items/repository/internal/filelock"
 )
 
+// lockFilename is the advisory lock file used to serialize mutations across processes.
+const lockFilename = ".prioritty.lock"
+
 // ObsidianRepository implements repository.Repository using Obsidian markdown files.
 type ObsidianRepository struct {
-	vaultPath string
+	vaultPath   string
+	inlineTasks bool
+	gitHistory  bool
+	expected    items.ItemInterface // checked before changes, see Expecting
+}
+
+// Option configures optional behavior of an ObsidianRepository.
+type Option func(*ObsidianRepository)
+
+// WithInlineTasks enables discovering "- [ ] task" checklist lines in any markdown
+// file of the vault and exposing them as tasks, besides the standalone task files.
+func WithInlineTasks(enabled bool) Option {
+	return func(r *ObsidianRepository) {
+		r.inlineTasks = enabled
+	}
 }
 
 // NewObsidianRepository creates a new ObsidianRepository for the given vault path.
-func NewObsidianRepository(vaultPath string) *ObsidianRepository {
-	return &ObsidianRepository{
+func NewObsidianRepository(vaultPath string, opts ...Option) *ObsidianRepository {
+	r := &ObsidianRepository{
 		vaultPath: vaultPath,
 	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
 
 // VaultPath returns the path to the Obsidian vault.
@@ -22,9 +47,39 @@ func (r *ObsidianRepository) VaultPath() string {
 	return r.vaultPath
 }
 
+// Expecting returns a view of the repository whose changes fail with
+// repository.ErrConflict if the item expected isn't stored as it is.
+func (r *ObsidianRepository) Expecting(expected items.ItemInterface) repository.Repository {
+	v := *r
+	v.expected = expected
+	return &v
+}
+
+// withLock runs fn while holding the vault lock, so concurrent pt processes
+// (e.g. a cron job and an open TUI) don't interleave their writes. The item
+// expected by an Expecting view is checked first.
+func (r *ObsidianRepository) withLock(fn func() error) error {
+	return r.withLockExpecting(r.expected, fn)
+}
+
+// withLockExpecting is withLock checking expected, if not nil, instead of the item
+// expected by the view.
+func (r *ObsidianRepository) withLockExpecting(expected items.ItemInterface, fn func() error) error {
+	return filelock.With(filepath.Join(r.vaultPath, lockFilename), func() error {
+		if err := repository.CheckStored(r, expected); err != nil {
+			return err
+		}
+		return fn()
+	})
+}
+
 // Reset removes all markdown files from the vault (used for demo cleanup).
 // It preserves the .obsidian folder.
 func (r *ObsidianRepository) Reset() error {
+	return r.withLock(r.reset)
+}
+
+func (r *ObsidianRepository) reset() error {
 	entries, err := os.ReadDir(r.vaultPath)
 	if err != nil {
 		return err
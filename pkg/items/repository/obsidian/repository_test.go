@@ -0,0 +1,108 @@
+package obsidian_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/markelca/prioritty/pkg/items"
+	"github.com/markelca/prioritty/pkg/items/repository"
+	"github.com/markelca/prioritty/pkg/items/repository/obsidian"
+	"github.com/markelca/prioritty/pkg/items/repository/repositorytest"
+)
+
+func TestRepository(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repository.Repository {
+		return obsidian.NewObsidianRepository(t.TempDir())
+	})
+}
+
+func TestInlineTaskTimeLog(t *testing.T) {
+	vault := t.TempDir()
+	content := "# Weekly\n\n- [ ] Send the minutes\n"
+	if err := os.WriteFile(filepath.Join(vault, "weekly.md"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r := obsidian.NewObsidianRepository(vault, obsidian.WithInlineTasks(true))
+	tasks, err := r.GetTasks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 1 || tasks[0].Title != "Send the minutes" {
+		t.Fatalf("tasks = %+v, want the inline task", tasks)
+	}
+	repositorytest.CheckNoTimeLog(t, r, tasks[0])
+}
+
+func TestInlineTaskMovedLine(t *testing.T) {
+	moved := "# Weekly\n- [ ] Buy milk\n\n- [ ] Book the room\n- [ ] Send the minutes\n"
+	tests := []struct {
+		name    string
+		outside string // the file changed outside, e.g. in the Obsidian app
+		change  func(r repository.Repository, task items.Task) error
+		want    string
+	}{
+		{
+			name:    "edit",
+			outside: moved,
+			change: func(r repository.Repository, task items.Task) error {
+				edited := task
+				edited.Title = "Send the notes"
+				return repository.Expect(r, &task).UpdateTask(edited)
+			},
+			want: "# Weekly\n- [ ] Buy milk\n\n- [ ] Book the room\n- [ ] Send the notes\n",
+		},
+		{
+			name:    "remove",
+			outside: moved,
+			change: func(r repository.Repository, task items.Task) error {
+				return repository.Expect(r, &task).RemoveTask(task.Id)
+			},
+			want: "# Weekly\n- [ ] Buy milk\n\n- [ ] Book the room\n",
+		},
+		{
+			name:    "moved and completed",
+			outside: "# Weekly\n- [ ] Buy milk\n\n- [ ] Book the room\n- [x] Send the minutes\n",
+			change: func(r repository.Repository, task items.Task) error {
+				if err := repository.Expect(r, &task).RemoveTask(task.Id); !errors.Is(err, repository.ErrConflict) {
+					return fmt.Errorf("error = %v, want ErrConflict", err)
+				}
+				return nil
+			},
+			want: "# Weekly\n- [ ] Buy milk\n\n- [ ] Book the room\n- [x] Send the minutes\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vault := t.TempDir()
+			path := filepath.Join(vault, "weekly.md")
+			if err := os.WriteFile(path, []byte("# Weekly\n\n- [ ] Book the room\n- [ ] Send the minutes\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			r := obsidian.NewObsidianRepository(vault, obsidian.WithInlineTasks(true))
+			tasks, err := r.GetTasks()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(tasks) != 2 || tasks[1].Title != "Send the minutes" {
+				t.Fatalf("tasks = %+v, want the inline tasks", tasks)
+			}
+
+			if err := os.WriteFile(path, []byte(tt.outside), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.change(r, tasks[1]); err != nil {
+				t.Fatal(err)
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(content) != tt.want {
+				t.Errorf("file = %q, want %q", content, tt.want)
+			}
+		})
+	}
+}
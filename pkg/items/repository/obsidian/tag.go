@@ -37,6 +37,19 @@ func (r *ObsidianRepository) GetTag(name string) (*items.Tag, error) {
 		}
 	}
 
+	inline, err := r.getInlineTasks()
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range inline {
+		if t.Tag != nil && t.Tag.Name == name {
+			return &items.Tag{
+				Id:   name,
+				Name: name,
+			}, nil
+		}
+	}
+
 	return nil, repository.ErrNotFound
 }
 
@@ -67,6 +80,16 @@ func (r *ObsidianRepository) GetTags() ([]items.Tag, error) {
 		}
 	}
 
+	inline, err := r.getInlineTasks()
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range inline {
+		if t.Tag != nil {
+			tagSet[t.Tag.Name] = struct{}{}
+		}
+	}
+
 	// Convert to slice and sort by name
 	var tags []items.Tag
 	for name := range tagSet {
@@ -137,13 +160,23 @@ func (r *ObsidianRepository) GetItemsWithTag(tagName string) ([]items.ItemInterf
 
 		switch fm.Type {
 		case string(items.ItemTypeTask):
-			task := taskFromFrontmatter(fm, body, id)
+			task := taskFromFrontmatter(fm, body, id, modTime(filePath))
 			result = append(result, &task)
 		case string(items.ItemTypeNote):
-			note := noteFromFrontmatter(fm, body, id)
+			note := noteFromFrontmatter(fm, body, id, modTime(filePath))
 			result = append(result, &note)
 		}
 	}
 
+	inline, err := r.getInlineTasks()
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range inline {
+		if t.Tag != nil && t.Tag.Name == tagName {
+			result = append(result, &t)
+		}
+	}
+
 	return result, nil
 }
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/markelca/prioritty/pkg/items"
+	"github.com/markelca/prioritty/pkg/items/repository/internal/atomicfile"
 	"github.com/markelca/prioritty/pkg/markdown"
 )
 
@@ -36,11 +37,16 @@ func (r *ObsidianRepository) GetTasks() ([]items.Task, error) {
 		}
 
 		id := relativeID(r.vaultPath, filePath)
-		task := taskFromFrontmatter(fm, body, id)
+		task := taskFromFrontmatter(fm, body, id, modTime(filePath))
 		tasks = append(tasks, task)
 	}
 
-	return tasks, nil
+	inline, err := r.getInlineTasks()
+	if err != nil {
+		return nil, err
+	}
+
+	return append(tasks, inline...), nil
 }
 
 // CreateTask creates a new task file in the vault.
@@ -49,9 +55,7 @@ func (r *ObsidianRepository) CreateTask(t *items.Task) error {
 	if t.CreatedAt.IsZero() {
 		t.CreatedAt = time.Now()
 	}
-
-	// Generate unique filename
-	filePath := uniqueFilename(r.vaultPath, t.Title)
+	t.InitStatusHistory()
 
 	// Serialize to markdown
 	content, err := markdown.Serialize(itemInputFromTask(*t))
@@ -59,149 +63,157 @@ func (r *ObsidianRepository) CreateTask(t *items.Task) error {
 		return err
 	}
 
-	// Write file
-	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
-		return err
-	}
+	return r.withLock(func() error {
+		// Write file under a unique filename
+		filePath, err := createUniqueFile(r.vaultPath, t.Title, []byte(content))
+		if err != nil {
+			return err
+		}
 
-	// Set the ID to the relative path
-	t.Id = relativeID(r.vaultPath, filePath)
-	return nil
+		// Set the ID to the relative path
+		t.Id = relativeID(r.vaultPath, filePath)
+		r.commit("add task: "+t.Title, filePath)
+		return nil
+	})
 }
 
 // UpdateTask updates an existing task file.
 func (r *ObsidianRepository) UpdateTask(t items.Task) error {
-	oldPath := fullPathFromID(r.vaultPath, t.Id)
-
-	// Read existing file to preserve created_at if not set
-	existingContent, err := os.ReadFile(oldPath)
-	if err != nil {
-		return err
-	}
-
-	var existingFm markdown.Frontmatter
-	if _, err := markdown.Parse(string(existingContent), &existingFm); err != nil {
-		return err
+	if isInlineID(t.Id) {
+		return r.updateInlineTaskContent(t)
 	}
+	return r.withLock(func() error {
+		oldPath := fullPathFromID(r.vaultPath, t.Id)
 
-	// Preserve created_at from existing file if not set on update
-	if t.CreatedAt.IsZero() {
-		t.CreatedAt = parseCreatedAt(existingFm.CreatedAt)
-	}
-
-	// Serialize to markdown
-	content, err := markdown.Serialize(itemInputFromTask(t))
-	if err != nil {
-		return err
-	}
+		// Read existing file to preserve created_at if not set
+		existingContent, err := os.ReadFile(oldPath)
+		if err != nil {
+			return notFound(err)
+		}
 
-	// Check if title changed (requires rename)
-	newFilename := filenameFromTitle(t.Title)
-	oldFilename := relativeID(r.vaultPath, oldPath)
+		var existingFm markdown.Frontmatter
+		if _, err := markdown.Parse(string(existingContent), &existingFm); err != nil {
+			return err
+		}
 
-	if newFilename != oldFilename {
-		// Generate unique filename for new title
-		newPath := uniqueFilename(r.vaultPath, t.Title)
+		// Preserve created_at from existing file if not set on update
+		if t.CreatedAt.IsZero() {
+			t.CreatedAt = parseCreatedAt(existingFm.CreatedAt, modTime(oldPath))
+		}
+		t = t.ReplacingStatusOf(taskFromFrontmatter(existingFm, "", t.Id, time.Time{}), time.Now())
 
-		// Write new file
-		if err := os.WriteFile(newPath, []byte(content), 0644); err != nil {
+		// Serialize to markdown
+		content, err := markdown.Serialize(itemInputFromTask(t))
+		if err != nil {
 			return err
 		}
 
-		// Remove old file
-		return os.Remove(oldPath)
-	}
+		// Rename only when the title changed, so files with a numeric suffix
+		// or named by hand keep their path (and ID)
+		if t.Title != existingFm.Title {
+			// Write new file under a unique filename for the new title
+			newPath, err := createUniqueFile(r.vaultPath, t.Title, []byte(content))
+			if err != nil {
+				return err
+			}
+
+			// Remove old file
+			if err := os.Remove(oldPath); err != nil {
+				return err
+			}
+			r.commit("edit: "+t.Title, newPath, oldPath)
+			return nil
+		}
 
-	// Title unchanged, write in place
-	return os.WriteFile(oldPath, []byte(content), 0644)
+		// Title unchanged, write in place
+		if err := atomicfile.WriteFile(oldPath, []byte(content)); err != nil {
+			return err
+		}
+		r.commit("edit: "+t.Title, oldPath)
+		return nil
+	})
 }
 
 // RemoveTask removes a task file from the vault.
 func (r *ObsidianRepository) RemoveTask(id string) error {
-	filePath := fullPathFromID(r.vaultPath, id)
-	return os.Remove(filePath)
+	if isInlineID(id) {
+		return r.removeInlineTask(id)
+	}
+	return r.withLock(func() error {
+		path := fullPathFromID(r.vaultPath, id)
+		title := fileTitle(path)
+		if err := os.Remove(path); err != nil {
+			return notFound(err)
+		}
+		r.commit("remove: "+title, path)
+		return nil
+	})
 }
 
 // UpdateTaskStatus updates only the status of a task.
 func (r *ObsidianRepository) UpdateTaskStatus(t items.Task, status items.Status) error {
-	filePath := fullPathFromID(r.vaultPath, t.Id)
-
-	// Read existing file
-	content, err := os.ReadFile(filePath)
-	if err != nil {
-		return err
-	}
-
-	var fm markdown.Frontmatter
-	body, err := markdown.Parse(string(content), &fm)
-	if err != nil {
-		return err
-	}
-
-	// Update status
-	fm.Status = string(status)
-
-	// Serialize and write back
-	newContent, err := fm.Serialize(body)
-	if err != nil {
-		return err
-	}
-
-	return os.WriteFile(filePath, newContent, 0644)
+	if isInlineID(t.Id) {
+		return r.updateInlineTaskStatus(t, status)
+	}
+	return r.updateFrontmatter(t.Id, string(status), func(fm *markdown.Frontmatter) {
+		stored := taskFromFrontmatter(*fm, "", t.Id, time.Time{})
+		stored.ChangeStatus(status, time.Now())
+		fm.Status = string(stored.Status)
+		fm.CompletedAt = items.FormatTimestamp(stored.CompletedAt)
+		fm.StatusHistory = formatStatusHistory(stored.StatusHistory)
+	})
 }
 
 // SetTaskTag sets the tag on a task.
 func (r *ObsidianRepository) SetTaskTag(t items.Task, tag items.Tag) error {
-	filePath := fullPathFromID(r.vaultPath, t.Id)
-
-	// Read existing file
-	content, err := os.ReadFile(filePath)
-	if err != nil {
-		return err
-	}
-
-	var fm markdown.Frontmatter
-	body, err := markdown.Parse(string(content), &fm)
-	if err != nil {
-		return err
-	}
-
-	// Update tag
-	fm.Tag = tag.Name
-
-	// Serialize and write back
-	newContent, err := fm.Serialize(body)
-	if err != nil {
-		return err
+	if isInlineID(t.Id) {
+		return r.setInlineTaskTag(t, tag.Name)
 	}
-
-	return os.WriteFile(filePath, newContent, 0644)
+	return r.updateFrontmatter(t.Id, "tag #"+tag.Name, func(fm *markdown.Frontmatter) {
+		fm.Tag = tag.Name
+	})
 }
 
 // UnsetTaskTag removes the tag from a task.
 func (r *ObsidianRepository) UnsetTaskTag(t items.Task) error {
-	filePath := fullPathFromID(r.vaultPath, t.Id)
-
-	// Read existing file
-	content, err := os.ReadFile(filePath)
-	if err != nil {
-		return err
+	if isInlineID(t.Id) {
+		return r.setInlineTaskTag(t, "")
 	}
+	return r.updateFrontmatter(t.Id, "untag", func(fm *markdown.Frontmatter) {
+		fm.Tag = ""
+	})
+}
 
-	var fm markdown.Frontmatter
-	body, err := markdown.Parse(string(content), &fm)
-	if err != nil {
-		return err
-	}
+// updateFrontmatter reads the item file, applies fn to its frontmatter and writes it back
+// while holding the vault lock. action describes the change in the git history.
+func (r *ObsidianRepository) updateFrontmatter(id, action string, fn func(*markdown.Frontmatter)) error {
+	return r.withLock(func() error {
+		filePath := fullPathFromID(r.vaultPath, id)
 
-	// Remove tag
-	fm.Tag = ""
+		// Read existing file
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return notFound(err)
+		}
 
-	// Serialize and write back
-	newContent, err := fm.Serialize(body)
-	if err != nil {
-		return err
-	}
+		var fm markdown.Frontmatter
+		body, err := markdown.Parse(string(content), &fm)
+		if err != nil {
+			return err
+		}
+
+		fn(&fm)
+
+		// Serialize and write back
+		newContent, err := fm.Serialize(body)
+		if err != nil {
+			return err
+		}
 
-	return os.WriteFile(filePath, newContent, 0644)
+		if err := atomicfile.WriteFile(filePath, newContent); err != nil {
+			return err
+		}
+		r.commit(action+": "+fm.Title, filePath)
+		return nil
+	})
 }
@@ -0,0 +1,310 @@
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/rpc"
+	"strings"
+	"time"
+
+	"github.com/markelca/prioritty/pkg/items"
+	"github.com/markelca/prioritty/pkg/items/repository"
+)
+
+// ErrOtherRepository is returned by Open when the server serves another repository.
+var ErrOtherRepository = errors.New("the daemon serves another repository")
+
+// dialTimeout bounds the connection to the socket, a live server accepts right away.
+const dialTimeout = time.Second
+
+// Client is a repository served by a Server.
+type Client struct {
+	rpc *rpc.Client
+}
+
+// historyClient is a Client of a repository that keeps the history of items.
+type historyClient struct {
+	*Client
+}
+
+// expectingClient is a Client whose changes are made through an Expecting view of
+// the served repository.
+type expectingClient struct {
+	*Client
+	expected items.ItemInterface
+}
+
+var (
+	_ repository.Repository = (*Client)(nil)
+	_ repository.History    = historyClient{}
+	_ repository.Repository = expectingClient{}
+	_ repository.Inline     = (*Client)(nil)
+	_ repository.Indexed    = (*Client)(nil)
+	_ repository.Expecter   = (*Client)(nil)
+)
+
+// Dial connects to the server listening on a Unix socket.
+func Dial(socketPath string) (*Client, error) {
+	conn, err := net.DialTimeout("unix", socketPath, dialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{rpc: rpc.NewClient(conn)}, nil
+}
+
+// Open connects to the server listening on a Unix socket, if it serves the repository
+// of the given type and path. The client implements repository.History when the
+// served repository does.
+func Open(socketPath, repoType, dbPath string) (repository.Repository, error) {
+	c, err := Dial(socketPath)
+	if err != nil {
+		return nil, err
+	}
+	info, err := c.Info()
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	if info.RepositoryType != repoType || info.DatabasePath != dbPath {
+		c.Close()
+		return nil, fmt.Errorf("%w: %s %s", ErrOtherRepository, info.RepositoryType, info.DatabasePath)
+	}
+	if info.History {
+		return historyClient{c}, nil
+	}
+	return c, nil
+}
+
+// Close closes the connection.
+func (c *Client) Close() error {
+	return c.rpc.Close()
+}
+
+// Info describes the repository served.
+func (c *Client) Info() (Info, error) {
+	var info Info
+	err := c.call("Info", None{}, &info)
+	return info, err
+}
+
+// call calls a method of the Endpoint, restoring the repository errors it returned.
+func (c *Client) call(method string, args, reply any) error {
+	if reply == nil {
+		reply = &None{}
+	}
+	return remoteError(c.rpc.Call(serviceName+"."+method, args, reply))
+}
+
+// repositoryErrors are the errors of the repository package that callers check.
+var repositoryErrors = []error{repository.ErrNotFound, repository.ErrConflict, repository.ErrNoTimeLog}
+
+// remoteError restores the errors of the server that callers check with errors.Is,
+// which only get their message across.
+func remoteError(err error) error {
+	var serverErr rpc.ServerError
+	if !errors.As(err, &serverErr) {
+		return err
+	}
+	msg := string(serverErr)
+	for _, target := range repositoryErrors {
+		if strings.HasSuffix(msg, target.Error()) {
+			return &wrappedError{msg: msg, target: target}
+		}
+	}
+	return errors.New(msg)
+}
+
+type wrappedError struct {
+	msg    string
+	target error
+}
+
+func (e *wrappedError) Error() string { return e.msg }
+func (e *wrappedError) Unwrap() error { return e.target }
+
+func (c *Client) GetTasks() ([]items.Task, error) {
+	var tasks []items.Task
+	err := c.call("GetTasks", None{}, &tasks)
+	return tasks, err
+}
+
+func (c *Client) CreateTask(t *items.Task) error {
+	var created items.Task
+	if err := c.call("CreateTask", *t, &created); err != nil {
+		return err
+	}
+	*t = created
+	return nil
+}
+
+func (c *Client) UpdateTask(t items.Task) error {
+	return c.call("UpdateTask", t, nil)
+}
+
+func (c *Client) RemoveTask(id string) error {
+	return c.call("RemoveTask", id, nil)
+}
+
+func (c *Client) UpdateTaskStatus(t items.Task, status items.Status) error {
+	return c.call("UpdateTaskStatus", StatusArgs{Task: t, Status: status}, nil)
+}
+
+func (c *Client) SetTaskTag(t items.Task, tag items.Tag) error {
+	return c.call("SetTaskTag", TaskTagArgs{Task: t, Tag: tag}, nil)
+}
+
+func (c *Client) UnsetTaskTag(t items.Task) error {
+	return c.call("UnsetTaskTag", t, nil)
+}
+
+func (c *Client) GetNotes() ([]items.Note, error) {
+	var notes []items.Note
+	err := c.call("GetNotes", None{}, &notes)
+	return notes, err
+}
+
+func (c *Client) CreateNote(n *items.Note) error {
+	var created items.Note
+	if err := c.call("CreateNote", *n, &created); err != nil {
+		return err
+	}
+	*n = created
+	return nil
+}
+
+func (c *Client) UpdateNote(n items.Note) error {
+	return c.call("UpdateNote", n, nil)
+}
+
+func (c *Client) RemoveNote(id string) error {
+	return c.call("RemoveNote", id, nil)
+}
+
+func (c *Client) SetNoteTag(n items.Note, tag items.Tag) error {
+	return c.call("SetNoteTag", NoteTagArgs{Note: n, Tag: tag}, nil)
+}
+
+func (c *Client) UnsetNoteTag(n items.Note) error {
+	return c.call("UnsetNoteTag", n, nil)
+}
+
+func (c *Client) GetTag(name string) (*items.Tag, error) {
+	var tag items.Tag
+	if err := c.call("GetTag", name, &tag); err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (c *Client) GetTags() ([]items.Tag, error) {
+	var tags []items.Tag
+	err := c.call("GetTags", None{}, &tags)
+	return tags, err
+}
+
+func (c *Client) CreateTag(name string) (*items.Tag, error) {
+	var tag items.Tag
+	if err := c.call("CreateTag", name, &tag); err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (c *Client) RemoveTag(name string) error {
+	return c.call("RemoveTag", name, nil)
+}
+
+func (c *Client) GetItemsWithTag(name string) ([]items.ItemInterface, error) {
+	var list []items.ItemInterface
+	err := c.call("GetItemsWithTag", name, &list)
+	return list, err
+}
+
+func (c *Client) Reset() error {
+	return c.call("Reset", None{}, nil)
+}
+
+// IsInline reports whether id is the ID of an inline task of the served repository.
+// It's true when the server can't tell, so the item is left alone.
+func (c *Client) IsInline(id string) bool {
+	var inline bool
+	if err := c.call("IsInline", id, &inline); err != nil {
+		return true
+	}
+	return inline
+}
+
+// ItemAt returns the item at an index of items.List, found by the server.
+func (c *Client) ItemAt(index int) (items.ItemInterface, error) {
+	var i items.ItemInterface
+	if err := c.call("ItemAt", index, &i); err != nil {
+		return nil, err
+	}
+	return i, nil
+}
+
+// Expecting returns a client whose changes fail with repository.ErrConflict if the
+// item expected isn't stored as it is, checked by the server.
+func (c *Client) Expecting(expected items.ItemInterface) repository.Repository {
+	// Only pointers are registered with gob
+	switch v := expected.(type) {
+	case items.Task:
+		expected = &v
+	case items.Note:
+		expected = &v
+	}
+	return expectingClient{Client: c, expected: expected}
+}
+
+// change makes the change described by args through the Expecting view.
+func (c expectingClient) change(args ExpectingArgs) error {
+	args.Expected = c.expected
+	return c.call("Expecting", args, nil)
+}
+
+func (c expectingClient) UpdateTask(t items.Task) error {
+	return c.change(ExpectingArgs{Method: "UpdateTask", Task: t})
+}
+
+func (c expectingClient) RemoveTask(id string) error {
+	return c.change(ExpectingArgs{Method: "RemoveTask", Id: id})
+}
+
+func (c expectingClient) UpdateTaskStatus(t items.Task, status items.Status) error {
+	return c.change(ExpectingArgs{Method: "UpdateTaskStatus", Task: t, Status: status})
+}
+
+func (c expectingClient) SetTaskTag(t items.Task, tag items.Tag) error {
+	return c.change(ExpectingArgs{Method: "SetTaskTag", Task: t, Tag: tag})
+}
+
+func (c expectingClient) UnsetTaskTag(t items.Task) error {
+	return c.change(ExpectingArgs{Method: "UnsetTaskTag", Task: t})
+}
+
+func (c expectingClient) UpdateNote(n items.Note) error {
+	return c.change(ExpectingArgs{Method: "UpdateNote", Note: n})
+}
+
+func (c expectingClient) RemoveNote(id string) error {
+	return c.change(ExpectingArgs{Method: "RemoveNote", Id: id})
+}
+
+func (c expectingClient) SetNoteTag(n items.Note, tag items.Tag) error {
+	return c.change(ExpectingArgs{Method: "SetNoteTag", Note: n, Tag: tag})
+}
+
+func (c expectingClient) UnsetNoteTag(n items.Note) error {
+	return c.change(ExpectingArgs{Method: "UnsetNoteTag", Note: n})
+}
+
+func (c historyClient) ItemHistory(id string) ([]repository.Revision, error) {
+	var revisions []repository.Revision
+	err := c.call("ItemHistory", id, &revisions)
+	return revisions, err
+}
+
+func (c historyClient) RevertItem(id, rev string) error {
+	return c.call("RevertItem", RevertArgs{Id: id, Rev: rev}, nil)
+}
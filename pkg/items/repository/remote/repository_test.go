@@ -0,0 +1,102 @@
+package remote_test
+
+import (
+	"errors"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/markelca/prioritty/pkg/items"
+	"github.com/markelca/prioritty/pkg/items/repository"
+	"github.com/markelca/prioritty/pkg/items/repository/memory"
+	"github.com/markelca/prioritty/pkg/items/repository/remote"
+	"github.com/markelca/prioritty/pkg/items/repository/repositorytest"
+	"github.com/markelca/prioritty/pkg/items/repository/todotxt"
+)
+
+func serve(t *testing.T) repository.Repository {
+	t.Helper()
+	return serveRepository(t, memory.NewRepository())
+}
+
+// serveRepository serves repo and returns a client of it.
+func serveRepository(t *testing.T, repo repository.Repository) repository.Repository {
+	t.Helper()
+	socketPath := filepath.Join(t.TempDir(), "pt.sock")
+	l, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	info := remote.Info{RepositoryType: "memory", DatabasePath: "test"}
+	go remote.NewServer(repo, info).Serve(l)
+
+	client, err := remote.Open(socketPath, info.RepositoryType, info.DatabasePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestRepository(t *testing.T) {
+	repositorytest.Run(t, serve)
+}
+
+func TestRepositoryRefusingTimeLog(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repository.Repository {
+		return serveRepository(t, todotxt.NewTodoTxtRepository(filepath.Join(t.TempDir(), "todo.txt")))
+	}, repositorytest.WithDateOnlyCreatedAt(), repositorytest.WithoutStatusHistory(),
+		repositorytest.RefusingTimeLog())
+}
+
+func TestItemAt(t *testing.T) {
+	repo := serve(t)
+	tag, err := repo.CreateTag("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, title := range []string{"First", "Second", "Third"} {
+		task := items.Task{Item: items.Item{Title: title}}
+		if err := repo.CreateTask(&task); err != nil {
+			t.Fatal(err)
+		}
+		if title == "Second" {
+			if err := repo.SetTaskTag(task, *tag); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := repo.CreateNote(&items.Note{Item: items.Item{Title: "Note"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	tasks, err := repo.GetTasks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	notes, err := repo.GetNotes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := items.List(tasks, notes)
+
+	indexed, ok := repo.(repository.Indexed)
+	if !ok {
+		t.Fatal("the client doesn't implement repository.Indexed")
+	}
+	for k, w := range want {
+		got, err := indexed.ItemAt(k)
+		if err != nil {
+			t.Fatalf("ItemAt(%d): %v", k, err)
+		}
+		if got.GetId() != w.GetId() || got.GetTitle() != w.GetTitle() {
+			t.Errorf("ItemAt(%d) = %q, want %q", k, got.GetTitle(), w.GetTitle())
+		}
+	}
+	for _, k := range []int{-1, len(want)} {
+		if _, err := indexed.ItemAt(k); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("ItemAt(%d) error = %v, want ErrNotFound", k, err)
+		}
+	}
+}
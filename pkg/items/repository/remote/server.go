@@ -0,0 +1,354 @@
+// Package remote serves a repository over a Unix domain socket, and provides the
+// client using it as a repository.Repository. The server keeps the tasks and notes
+// in memory between requests, so a long-running process (pt daemon) answers the
+// CLI without opening, migrating and reading the repository each time.
+package remote
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"net"
+	"net/rpc"
+	"sync"
+
+	"github.com/markelca/prioritty/pkg/items"
+	"github.com/markelca/prioritty/pkg/items/repository"
+)
+
+func init() {
+	// Concrete types of the items returned by GetItemsWithTag
+	gob.Register(&items.Task{})
+	gob.Register(&items.Note{})
+}
+
+// Info describes the repository served, so clients only use a server of the
+// repository they were configured with.
+type Info struct {
+	RepositoryType string
+	DatabasePath   string
+	History        bool // the repository implements repository.History
+}
+
+// Server serves a repository. Requests are handled one at a time.
+type Server struct {
+	repo repository.Repository
+	info Info
+
+	mu    sync.Mutex
+	tasks []items.Task // cached, nil when not loaded
+	notes []items.Note // cached, nil when not loaded
+}
+
+// NewServer returns a server for the repository described by info.
+func NewServer(repo repository.Repository, info Info) *Server {
+	_, info.History = repo.(repository.History)
+	return &Server{repo: repo, info: info}
+}
+
+// Serve accepts connections on the listener until it's closed.
+func (s *Server) Serve(l net.Listener) error {
+	srv := rpc.NewServer()
+	if err := srv.RegisterName(serviceName, &Endpoint{s: s}); err != nil {
+		return err
+	}
+	for {
+		conn, err := l.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		go srv.ServeConn(conn)
+	}
+}
+
+// Invalidate drops the cached items, e.g. when the repository was changed by
+// another program. They're read again on the next request.
+func (s *Server) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tasks, s.notes = nil, nil
+}
+
+// serviceName is the name of the RPC service of the Endpoint methods.
+const serviceName = "Repository"
+
+// None is the argument or reply of the calls without one.
+type None struct{}
+
+// StatusArgs are the arguments of UpdateTaskStatus.
+type StatusArgs struct {
+	Task   items.Task
+	Status items.Status
+}
+
+// TaskTagArgs are the arguments of SetTaskTag.
+type TaskTagArgs struct {
+	Task items.Task
+	Tag  items.Tag
+}
+
+// NoteTagArgs are the arguments of SetNoteTag.
+type NoteTagArgs struct {
+	Note items.Note
+	Tag  items.Tag
+}
+
+// ExpectingArgs are the arguments of Expecting: the change of an existing item made
+// by calling Method with the fields it takes as arguments.
+type ExpectingArgs struct {
+	Expected items.ItemInterface
+	Method   string
+	Task     items.Task
+	Note     items.Note
+	Tag      items.Tag
+	Status   items.Status
+	Id       string
+}
+
+// RevertArgs are the arguments of RevertItem.
+type RevertArgs struct {
+	Id  string
+	Rev string
+}
+
+// Endpoint exposes the repository of a server over RPC. Its methods are called by
+// the Client and not meant to be used directly.
+type Endpoint struct {
+	s *Server
+}
+
+// read runs fn holding the server lock.
+func (e *Endpoint) read(fn func() error) error {
+	e.s.mu.Lock()
+	defer e.s.mu.Unlock()
+	return fn()
+}
+
+// write runs fn holding the server lock, then drops the cached items.
+func (e *Endpoint) write(fn func() error) error {
+	e.s.mu.Lock()
+	defer e.s.mu.Unlock()
+	defer func() { e.s.tasks, e.s.notes = nil, nil }()
+	return fn()
+}
+
+func (e *Endpoint) Info(_ None, reply *Info) error {
+	*reply = e.s.info
+	return nil
+}
+
+func (e *Endpoint) GetTasks(_ None, reply *[]items.Task) error {
+	return e.read(func() error {
+		if e.s.tasks == nil {
+			tasks, err := e.s.repo.GetTasks()
+			if err != nil {
+				return err
+			}
+			e.s.tasks = append([]items.Task{}, tasks...)
+		}
+		*reply = e.s.tasks
+		return nil
+	})
+}
+
+func (e *Endpoint) GetNotes(_ None, reply *[]items.Note) error {
+	return e.read(func() error {
+		if e.s.notes == nil {
+			notes, err := e.s.repo.GetNotes()
+			if err != nil {
+				return err
+			}
+			e.s.notes = append([]items.Note{}, notes...)
+		}
+		*reply = e.s.notes
+		return nil
+	})
+}
+
+func (e *Endpoint) CreateTask(t items.Task, reply *items.Task) error {
+	return e.write(func() error {
+		err := e.s.repo.CreateTask(&t)
+		*reply = t
+		return err
+	})
+}
+
+func (e *Endpoint) UpdateTask(t items.Task, _ *None) error {
+	return e.write(func() error { return e.s.repo.UpdateTask(t) })
+}
+
+func (e *Endpoint) RemoveTask(id string, _ *None) error {
+	return e.write(func() error { return e.s.repo.RemoveTask(id) })
+}
+
+func (e *Endpoint) UpdateTaskStatus(args StatusArgs, _ *None) error {
+	return e.write(func() error { return e.s.repo.UpdateTaskStatus(args.Task, args.Status) })
+}
+
+func (e *Endpoint) SetTaskTag(args TaskTagArgs, _ *None) error {
+	return e.write(func() error { return e.s.repo.SetTaskTag(args.Task, args.Tag) })
+}
+
+func (e *Endpoint) UnsetTaskTag(t items.Task, _ *None) error {
+	return e.write(func() error { return e.s.repo.UnsetTaskTag(t) })
+}
+
+func (e *Endpoint) CreateNote(n items.Note, reply *items.Note) error {
+	return e.write(func() error {
+		err := e.s.repo.CreateNote(&n)
+		*reply = n
+		return err
+	})
+}
+
+func (e *Endpoint) UpdateNote(n items.Note, _ *None) error {
+	return e.write(func() error { return e.s.repo.UpdateNote(n) })
+}
+
+func (e *Endpoint) RemoveNote(id string, _ *None) error {
+	return e.write(func() error { return e.s.repo.RemoveNote(id) })
+}
+
+func (e *Endpoint) SetNoteTag(args NoteTagArgs, _ *None) error {
+	return e.write(func() error { return e.s.repo.SetNoteTag(args.Note, args.Tag) })
+}
+
+func (e *Endpoint) UnsetNoteTag(n items.Note, _ *None) error {
+	return e.write(func() error { return e.s.repo.UnsetNoteTag(n) })
+}
+
+func (e *Endpoint) IsInline(id string, reply *bool) error {
+	if r, ok := e.s.repo.(repository.Inline); ok {
+		*reply = r.IsInline(id)
+	}
+	return nil
+}
+
+// ItemAt finds the item at an index of items.List in the cached items.
+func (e *Endpoint) ItemAt(index int, reply *items.ItemInterface) error {
+	var tasks []items.Task
+	if err := e.GetTasks(None{}, &tasks); err != nil {
+		return err
+	}
+	var notes []items.Note
+	if err := e.GetNotes(None{}, &notes); err != nil {
+		return err
+	}
+	list := items.List(tasks, notes)
+	if index < 0 || index >= len(list) {
+		return fmt.Errorf("item %d: %w", index+1, repository.ErrNotFound)
+	}
+	*reply = list[index]
+	return nil
+}
+
+// Expecting makes a change through repository.Expect(repo, args.Expected).
+func (e *Endpoint) Expecting(args ExpectingArgs, _ *None) error {
+	return e.write(func() error {
+		r := repository.Expect(e.s.repo, args.Expected)
+		switch args.Method {
+		case "UpdateTask":
+			return r.UpdateTask(args.Task)
+		case "RemoveTask":
+			return r.RemoveTask(args.Id)
+		case "UpdateTaskStatus":
+			return r.UpdateTaskStatus(args.Task, args.Status)
+		case "SetTaskTag":
+			return r.SetTaskTag(args.Task, args.Tag)
+		case "UnsetTaskTag":
+			return r.UnsetTaskTag(args.Task)
+		case "UpdateNote":
+			return r.UpdateNote(args.Note)
+		case "RemoveNote":
+			return r.RemoveNote(args.Id)
+		case "SetNoteTag":
+			return r.SetNoteTag(args.Note, args.Tag)
+		case "UnsetNoteTag":
+			return r.UnsetNoteTag(args.Note)
+		}
+		return fmt.Errorf("no change %q", args.Method)
+	})
+}
+
+func (e *Endpoint) GetTag(name string, reply *items.Tag) error {
+	return e.read(func() error {
+		tag, err := e.s.repo.GetTag(name)
+		if err == nil {
+			*reply = *tag
+		}
+		return err
+	})
+}
+
+func (e *Endpoint) GetTags(_ None, reply *[]items.Tag) error {
+	return e.read(func() error {
+		tags, err := e.s.repo.GetTags()
+		*reply = tags
+		return err
+	})
+}
+
+func (e *Endpoint) CreateTag(name string, reply *items.Tag) error {
+	return e.write(func() error {
+		tag, err := e.s.repo.CreateTag(name)
+		if err == nil {
+			*reply = *tag
+		}
+		return err
+	})
+}
+
+func (e *Endpoint) RemoveTag(name string, _ *None) error {
+	return e.write(func() error { return e.s.repo.RemoveTag(name) })
+}
+
+func (e *Endpoint) GetItemsWithTag(name string, reply *[]items.ItemInterface) error {
+	return e.read(func() error {
+		list, err := e.s.repo.GetItemsWithTag(name)
+		if err != nil {
+			return err
+		}
+		// Only pointers are registered with gob
+		for i, item := range list {
+			switch v := item.(type) {
+			case items.Task:
+				list[i] = &v
+			case items.Note:
+				list[i] = &v
+			}
+		}
+		*reply = list
+		return nil
+	})
+}
+
+func (e *Endpoint) Reset(_ None, _ *None) error {
+	return e.write(e.s.repo.Reset)
+}
+
+func (e *Endpoint) ItemHistory(id string, reply *[]repository.Revision) error {
+	return e.read(func() error {
+		h, ok := e.s.repo.(repository.History)
+		if !ok {
+			return errNoHistory
+		}
+		revisions, err := h.ItemHistory(id)
+		*reply = revisions
+		return err
+	})
+}
+
+func (e *Endpoint) RevertItem(args RevertArgs, _ *None) error {
+	return e.write(func() error {
+		h, ok := e.s.repo.(repository.History)
+		if !ok {
+			return errNoHistory
+		}
+		return h.RevertItem(args.Id, args.Rev)
+	})
+}
+
+var errNoHistory = errors.New("the repository doesn't keep the history of items")
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/markelca/prioritty/internal/config"
 	"github.com/markelca/prioritty/pkg/items"
@@ -14,9 +15,19 @@ import (
 
 var ErrNotFound = errors.New("not found")
 
+// ErrConflict is returned when an item changed in storage since it was loaded,
+// e.g. because another pt process modified or removed it.
+var ErrConflict = errors.New("item was modified elsewhere")
+
+// ErrNoTimeLog is returned when storing time entries on a task whose storage can't
+// keep them, e.g. an inline task of an Obsidian note, instead of dropping them.
+var ErrNoTimeLog = errors.New("the time log of this task can't be stored")
+
 const (
 	RepoTypeObsidian = "obsidian"
 	RepoTypeSQLite   = "sqlite"
+	RepoTypeJSON     = "json"
+	RepoTypeTodoTxt  = "todotxt"
 )
 
 type TaskRepository interface {
@@ -49,7 +60,46 @@ type Repository interface {
 	Reset() error
 }
 
-func expandTilde(p string) string {
+// Expecter is implemented by repositories that can check an item is unchanged in the
+// same step as changing it. Use Expect, which falls back to checking it first for the
+// other repositories.
+type Expecter interface {
+	// Expecting returns a view of the repository whose changes of existing items first
+	// check that the item with the ID of expected is stored with its content (see
+	// items.SameContent), failing with ErrConflict otherwise. The check is made holding the lock or in the
+	// transaction of the change, so no other process can change the item in between.
+	Expecting(expected items.ItemInterface) Repository
+}
+
+// History is implemented by repositories that keep the past versions of items,
+// e.g. an Obsidian vault committed to git.
+type History interface {
+	ItemHistory(id string) ([]Revision, error)
+	RevertItem(id, rev string) error
+}
+
+// Inline is implemented by repositories whose tasks may be lines of other documents,
+// e.g. the checklists of Obsidian notes. Removing all items must leave those alone.
+type Inline interface {
+	IsInline(id string) bool
+}
+
+// Indexed is implemented by repositories that find the items by their position in
+// items.List themselves, e.g. the client of the daemon, sparing the load of all items.
+type Indexed interface {
+	// ItemAt returns the item at index, 0-based, or ErrNotFound.
+	ItemAt(index int) (items.ItemInterface, error)
+}
+
+// Revision is a past version of an item.
+type Revision struct {
+	Id      string // e.g. a git commit hash
+	Time    time.Time
+	Message string
+}
+
+// ExpandTilde replaces a leading "~/" with the home directory.
+func ExpandTilde(p string) string {
 	if strings.HasPrefix(p, "~/") {
 		home, err := os.UserHomeDir()
 		if err != nil {
@@ -66,13 +116,25 @@ func GetDatabasePath(repoType string, isDemo bool) (dbPath string, err error) {
 		if isDemo {
 			dbPath = path.Join(os.TempDir(), "prioritty_demo_vault")
 		} else {
-			dbPath = expandTilde(viper.GetString(config.CONF_DATABASE_PATH))
+			dbPath = ExpandTilde(viper.GetString(config.CONF_DATABASE_PATH))
 		}
 	case RepoTypeSQLite:
 		if isDemo {
 			dbPath = path.Join(os.TempDir(), "prioritty_demo.db")
 		} else {
-			dbPath = expandTilde(viper.GetString(config.CONF_DATABASE_PATH))
+			dbPath = ExpandTilde(viper.GetString(config.CONF_DATABASE_PATH))
+		}
+	case RepoTypeJSON:
+		if isDemo {
+			dbPath = path.Join(os.TempDir(), "prioritty_demo.json")
+		} else {
+			dbPath = ExpandTilde(viper.GetString(config.CONF_DATABASE_PATH))
+		}
+	case RepoTypeTodoTxt:
+		if isDemo {
+			dbPath = path.Join(os.TempDir(), "prioritty_demo_todo.txt")
+		} else {
+			dbPath = ExpandTilde(viper.GetString(config.CONF_DATABASE_PATH))
 		}
 	default:
 		return "", fmt.Errorf("repository type not supported (%s)", repoType)
@@ -0,0 +1,939 @@
+// Package repositorytest provides a conformance suite for repository.Repository
+// implementations. Every backend runs it from its own tests, so they all behave
+// the same for the service layer:
+//
+//	func TestRepository(t *testing.T) {
+//		repositorytest.Run(t, func(t *testing.T) repository.Repository {
+//			return memory.NewRepository()
+//		})
+//	}
+//
+// Backends may change an item's ID when its title changes (e.g. Obsidian uses the
+// filename), so the suite looks items up by title after renaming them.
+package repositorytest
+
+import (
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/markelca/prioritty/pkg/items"
+	"github.com/markelca/prioritty/pkg/items/repository"
+)
+
+// Factory returns a new, empty repository. It's called once per subtest.
+type Factory func(t *testing.T) repository.Repository
+
+// Option adapts the suite to backends whose storage format can't keep everything.
+type Option func(*options)
+
+type options struct {
+	dateOnlyCreatedAt bool
+	noStatusHistory   bool
+	refuseTimeLog     bool
+}
+
+// WithDateOnlyCreatedAt compares task creation times by date, for formats that
+// only store the creation date (e.g. todo.txt).
+func WithDateOnlyCreatedAt() Option {
+	return func(o *options) {
+		o.dateOnlyCreatedAt = true
+	}
+}
+
+// WithoutStatusHistory skips the status history checks and compares completion
+// times by date, for formats that only store the completion date (e.g. todo.txt).
+func WithoutStatusHistory() Option {
+	return func(o *options) {
+		o.noStatusHistory = true
+	}
+}
+
+// RefusingTimeLog checks that time entries are refused with repository.ErrNoTimeLog
+// (see CheckNoTimeLog), for formats that can't keep them (e.g. todo.txt).
+func RefusingTimeLog() Option {
+	return func(o *options) {
+		o.refuseTimeLog = true
+	}
+}
+
+// Run runs the conformance suite against the repositories returned by newRepo.
+func Run(t *testing.T, newRepo Factory, opts ...Option) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	tests := []struct {
+		name string
+		fn   func(*testing.T, repository.Repository, options)
+	}{
+		{"TaskRoundTrip", testTaskRoundTrip},
+		{"NoteRoundTrip", testNoteRoundTrip},
+		{"UpdateTask", testUpdateTask},
+		{"UpdateNote", testUpdateNote},
+		{"StatusTransitions", testStatusTransitions},
+		{"StatusHistory", testStatusHistory},
+		{"TimeLog", testTimeLog},
+		{"DuplicateTitles", testDuplicateTitles},
+		{"Remove", testRemove},
+		{"MissingItems", testMissingItems},
+		{"Expecting", testExpecting},
+		{"Tags", testTags},
+		{"RemoveTag", testRemoveTag},
+		{"Conversions", testConversions},
+		{"Ordering", testOrdering},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newRepo(t), o)
+		})
+	}
+}
+
+// baseTime is the creation time of the test items. Backends are only required
+// to keep creation times with second precision (or by date, see WithDateOnlyCreatedAt).
+var baseTime = time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+
+func mustDate(t *testing.T, s string) time.Time {
+	t.Helper()
+	d, err := items.ParseDate(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func createTask(t *testing.T, r repository.Repository, task items.Task) items.Task {
+	t.Helper()
+	if err := r.CreateTask(&task); err != nil {
+		t.Fatalf("CreateTask(%q): %v", task.Title, err)
+	}
+	if task.Id == "" {
+		t.Fatalf("CreateTask(%q) didn't set the ID", task.Title)
+	}
+	return task
+}
+
+func createNote(t *testing.T, r repository.Repository, note items.Note) items.Note {
+	t.Helper()
+	if err := r.CreateNote(&note); err != nil {
+		t.Fatalf("CreateNote(%q): %v", note.Title, err)
+	}
+	if note.Id == "" {
+		t.Fatalf("CreateNote(%q) didn't set the ID", note.Title)
+	}
+	return note
+}
+
+func createTag(t *testing.T, r repository.Repository, name string) items.Tag {
+	t.Helper()
+	tag, err := r.CreateTag(name)
+	if err != nil {
+		t.Fatalf("CreateTag(%q): %v", name, err)
+	}
+	return *tag
+}
+
+func getTasks(t *testing.T, r repository.Repository) []items.Task {
+	t.Helper()
+	tasks, err := r.GetTasks()
+	if err != nil {
+		t.Fatalf("GetTasks: %v", err)
+	}
+	return tasks
+}
+
+func getNotes(t *testing.T, r repository.Repository) []items.Note {
+	t.Helper()
+	notes, err := r.GetNotes()
+	if err != nil {
+		t.Fatalf("GetNotes: %v", err)
+	}
+	return notes
+}
+
+// findTask returns the stored task with the given ID, failing if it doesn't exist.
+func findTask(t *testing.T, r repository.Repository, id string) items.Task {
+	t.Helper()
+	for _, task := range getTasks(t, r) {
+		if task.Id == id {
+			return task
+		}
+	}
+	t.Fatalf("task %q not found", id)
+	return items.Task{}
+}
+
+// findNote returns the stored note with the given ID, failing if it doesn't exist.
+func findNote(t *testing.T, r repository.Repository, id string) items.Note {
+	t.Helper()
+	for _, note := range getNotes(t, r) {
+		if note.Id == id {
+			return note
+		}
+	}
+	t.Fatalf("note %q not found", id)
+	return items.Note{}
+}
+
+func tagName(tag *items.Tag) string {
+	if tag == nil {
+		return ""
+	}
+	return tag.Name
+}
+
+func titles(list []items.ItemInterface) []string {
+	var result []string
+	for _, i := range list {
+		result = append(result, i.GetTitle())
+	}
+	return result
+}
+
+func sortedTitles(list []items.ItemInterface) []string {
+	result := titles(list)
+	sort.Strings(result)
+	return result
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func checkTask(t *testing.T, o options, got, want items.Task) {
+	t.Helper()
+	if got.UID != want.UID {
+		t.Errorf("uid = %q, want %q", got.UID, want.UID)
+	}
+	if got.Title != want.Title {
+		t.Errorf("title = %q, want %q", got.Title, want.Title)
+	}
+	if got.Body != want.Body {
+		t.Errorf("body = %q, want %q", got.Body, want.Body)
+	}
+	if got.Status != want.Status {
+		t.Errorf("status = %q, want %q", got.Status, want.Status)
+	}
+	if got.Priority != want.Priority {
+		t.Errorf("priority = %q, want %q", got.Priority, want.Priority)
+	}
+	if items.FormatDate(got.DueDate) != items.FormatDate(want.DueDate) {
+		t.Errorf("due = %q, want %q", items.FormatDate(got.DueDate), items.FormatDate(want.DueDate))
+	}
+	if items.FormatDate(got.ScheduledDate) != items.FormatDate(want.ScheduledDate) {
+		t.Errorf("scheduled = %q, want %q", items.FormatDate(got.ScheduledDate), items.FormatDate(want.ScheduledDate))
+	}
+	if got.Recurrence != want.Recurrence {
+		t.Errorf("recurrence = %q, want %q", got.Recurrence, want.Recurrence)
+	}
+	if got.Estimate != want.Estimate {
+		t.Errorf("estimate = %q, want %q", got.Estimate, want.Estimate)
+	}
+	if o.dateOnlyCreatedAt {
+		if !want.CreatedAt.IsZero() && items.FormatDate(got.CreatedAt) != items.FormatDate(want.CreatedAt) {
+			t.Errorf("created on = %v, want %v", items.FormatDate(got.CreatedAt), items.FormatDate(want.CreatedAt))
+		}
+	} else if !want.CreatedAt.IsZero() && !got.CreatedAt.Equal(want.CreatedAt) {
+		t.Errorf("created at = %v, want %v", got.CreatedAt, want.CreatedAt)
+	}
+	if tagName(got.Tag) != tagName(want.Tag) {
+		t.Errorf("tag = %q, want %q", tagName(got.Tag), tagName(want.Tag))
+	}
+}
+
+func checkNote(t *testing.T, got, want items.Note) {
+	t.Helper()
+	if got.UID != want.UID {
+		t.Errorf("uid = %q, want %q", got.UID, want.UID)
+	}
+	if got.Title != want.Title {
+		t.Errorf("title = %q, want %q", got.Title, want.Title)
+	}
+	if got.Body != want.Body {
+		t.Errorf("body = %q, want %q", got.Body, want.Body)
+	}
+	if !want.CreatedAt.IsZero() && !got.CreatedAt.Equal(want.CreatedAt) {
+		t.Errorf("created at = %v, want %v", got.CreatedAt, want.CreatedAt)
+	}
+	if tagName(got.Tag) != tagName(want.Tag) {
+		t.Errorf("tag = %q, want %q", tagName(got.Tag), tagName(want.Tag))
+	}
+}
+
+func testTaskRoundTrip(t *testing.T, r repository.Repository, o options) {
+	if tasks := getTasks(t, r); len(tasks) != 0 {
+		t.Fatalf("new repository has %d tasks", len(tasks))
+	}
+
+	want := items.Task{
+		Item: items.Item{
+			UID:       "6f1c2a4e-8a43-4c3a-9d2e-1b5f0c7d9e21",
+			Title:     "Write the release notes",
+			Body:      "Mention the new backends.\n\n- sqlite\n- obsidian",
+			CreatedAt: baseTime,
+		},
+		Status:        items.InProgress,
+		Priority:      items.PriorityHigh,
+		DueDate:       mustDate(t, "2024-03-10"),
+		ScheduledDate: mustDate(t, "2024-03-08"),
+		Recurrence:    "every week",
+		Estimate:      items.Estimate{Duration: 90 * time.Minute},
+	}
+	created := createTask(t, r, want)
+
+	tasks := getTasks(t, r)
+	if len(tasks) != 1 {
+		t.Fatalf("got %d tasks, want 1", len(tasks))
+	}
+	if tasks[0].Id != created.Id {
+		t.Errorf("id = %q, want %q", tasks[0].Id, created.Id)
+	}
+	checkTask(t, o, tasks[0], want)
+
+	if notes := getNotes(t, r); len(notes) != 0 {
+		t.Errorf("tasks must not be listed as notes, got %d notes", len(notes))
+	}
+}
+
+func testNoteRoundTrip(t *testing.T, r repository.Repository, o options) {
+	want := items.Note{
+		Item: items.Item{
+			UID:       "0b9d7e3c-5f2a-4e61-8c4d-2a7b9e1f3c58",
+			Title:     "Meeting notes",
+			Body:      "Discussed the roadmap.",
+			CreatedAt: baseTime,
+		},
+	}
+	created := createNote(t, r, want)
+
+	notes := getNotes(t, r)
+	if len(notes) != 1 {
+		t.Fatalf("got %d notes, want 1", len(notes))
+	}
+	if notes[0].Id != created.Id {
+		t.Errorf("id = %q, want %q", notes[0].Id, created.Id)
+	}
+	checkNote(t, notes[0], want)
+
+	if tasks := getTasks(t, r); len(tasks) != 0 {
+		t.Errorf("notes must not be listed as tasks, got %d tasks", len(tasks))
+	}
+}
+
+func testUpdateTask(t *testing.T, r repository.Repository, o options) {
+	task := createTask(t, r, items.Task{
+		Item:   items.Item{Title: "Draft", Body: "first version", CreatedAt: baseTime},
+		Status: items.Todo,
+	})
+
+	task.Body = "second version"
+	task.UID = "external-42"
+	task.Status = items.Done
+	task.Priority = items.PriorityLow
+	task.DueDate = mustDate(t, "2024-04-01")
+	task.Estimate = items.Estimate{Points: 2.5}
+	if err := r.UpdateTask(task); err != nil {
+		t.Fatalf("UpdateTask: %v", err)
+	}
+	got := findTask(t, r, task.Id)
+	checkTask(t, o, got, task)
+	// Backends may only know when the file holding the item changed, but never before the update
+	if got.UpdatedAt.Before(baseTime) {
+		t.Errorf("updated at = %v, want after the update", got.UpdatedAt)
+	}
+
+	// Unsetting the planning fields
+	task.Priority = items.PriorityNone
+	task.DueDate = time.Time{}
+	task.Estimate = items.Estimate{}
+	if err := r.UpdateTask(task); err != nil {
+		t.Fatalf("UpdateTask: %v", err)
+	}
+	checkTask(t, o, findTask(t, r, task.Id), task)
+
+	// Renaming, the ID may change
+	task.Title = "Final"
+	if err := r.UpdateTask(task); err != nil {
+		t.Fatalf("UpdateTask: %v", err)
+	}
+	tasks := getTasks(t, r)
+	if len(tasks) != 1 {
+		t.Fatalf("got %d tasks after renaming, want 1", len(tasks))
+	}
+	checkTask(t, o, tasks[0], task)
+}
+
+func testUpdateNote(t *testing.T, r repository.Repository, o options) {
+	note := createNote(t, r, items.Note{
+		Item: items.Item{Title: "Ideas", Body: "one", CreatedAt: baseTime},
+	})
+
+	note.Body = "one\ntwo"
+	note.UID = "external-43"
+	if err := r.UpdateNote(note); err != nil {
+		t.Fatalf("UpdateNote: %v", err)
+	}
+	checkNote(t, findNote(t, r, note.Id), note)
+
+	note.Title = "More ideas"
+	if err := r.UpdateNote(note); err != nil {
+		t.Fatalf("UpdateNote: %v", err)
+	}
+	notes := getNotes(t, r)
+	if len(notes) != 1 {
+		t.Fatalf("got %d notes after renaming, want 1", len(notes))
+	}
+	checkNote(t, notes[0], note)
+}
+
+func testStatusTransitions(t *testing.T, r repository.Repository, o options) {
+	task := createTask(t, r, items.Task{
+		Item:   items.Item{Title: "Toggle me", CreatedAt: baseTime},
+		Status: items.Todo,
+	})
+
+	for _, status := range []items.Status{items.InProgress, items.Done, items.Cancelled, items.Todo, items.Done, items.Todo} {
+		if err := r.UpdateTaskStatus(task, status); err != nil {
+			t.Fatalf("UpdateTaskStatus(%q): %v", status, err)
+		}
+		got := findTask(t, r, task.Id)
+		if got.Status != status {
+			t.Fatalf("status = %q, want %q", got.Status, status)
+		}
+		// Only the status changes
+		task.Status = status
+		checkTask(t, o, got, task)
+	}
+}
+
+func testStatusHistory(t *testing.T, r repository.Repository, o options) {
+	task := createTask(t, r, items.Task{
+		Item:   items.Item{Title: "Track me", CreatedAt: baseTime},
+		Status: items.Todo,
+	})
+	before := time.Now().Truncate(time.Second)
+
+	if err := r.UpdateTaskStatus(task, items.InProgress); err != nil {
+		t.Fatalf("UpdateTaskStatus: %v", err)
+	}
+	// Status changes made with a full update are recorded too
+	task = findTask(t, r, task.Id)
+	task.Status = items.Done
+	if err := r.UpdateTask(task); err != nil {
+		t.Fatalf("UpdateTask: %v", err)
+	}
+
+	got := findTask(t, r, task.Id)
+	after := time.Now()
+	if o.noStatusHistory {
+		if items.FormatDate(got.CompletedAt) != items.FormatDate(after) {
+			t.Errorf("completed at %v, want today", got.CompletedAt)
+		}
+	} else {
+		if got.CompletedAt.Before(before) || got.CompletedAt.After(after) {
+			t.Errorf("completed at %v, want between %v and %v", got.CompletedAt, before, after)
+		}
+		var statuses []string
+		for _, c := range got.StatusHistory {
+			statuses = append(statuses, string(c.Status))
+		}
+		want := []string{string(items.Todo), string(items.InProgress), string(items.Done)}
+		if !equalStrings(statuses, want) {
+			t.Fatalf("status history = %v, want %v", statuses, want)
+		}
+		if !got.StatusHistory[0].At.Equal(baseTime) {
+			t.Errorf("entered %s at %v, want the creation time %v", items.Todo, got.StatusHistory[0].At, baseTime)
+		}
+		if _, ok := got.CycleTime(); !ok {
+			t.Errorf("no cycle time for a started and completed task")
+		}
+	}
+
+	if err := r.UpdateTaskStatus(got, items.Todo); err != nil {
+		t.Fatalf("UpdateTaskStatus: %v", err)
+	}
+	got = findTask(t, r, task.Id)
+	if !got.CompletedAt.IsZero() {
+		t.Errorf("completed at %v after reopening, want zero", got.CompletedAt)
+	}
+	if !o.noStatusHistory && len(got.StatusHistory) != 4 {
+		t.Errorf("status history has %d entries after reopening, want 4", len(got.StatusHistory))
+	}
+}
+
+func testTimeLog(t *testing.T, r repository.Repository, o options) {
+	worked := items.TimeEntry{Start: baseTime.Add(time.Hour), End: baseTime.Add(150 * time.Minute)}
+	pomodoro := items.TimeEntry{Start: baseTime.Add(3 * time.Hour), End: baseTime.Add(205 * time.Minute), Pomodoro: true}
+	if o.refuseTimeLog {
+		tracked := items.Task{Item: items.Item{Title: "Billable", CreatedAt: baseTime}, Status: items.Todo, TimeLog: []items.TimeEntry{worked}}
+		if err := r.CreateTask(&tracked); !errors.Is(err, repository.ErrNoTimeLog) {
+			t.Fatalf("CreateTask with a time log: got %v, want ErrNoTimeLog", err)
+		}
+		CheckNoTimeLog(t, r, createTask(t, r, items.Task{Item: items.Item{Title: "Billable", CreatedAt: baseTime}, Status: items.Todo}))
+		return
+	}
+	task := createTask(t, r, items.Task{
+		Item:    items.Item{Title: "Billable", CreatedAt: baseTime},
+		Status:  items.Todo,
+		TimeLog: []items.TimeEntry{worked, pomodoro},
+	})
+	checkTimeLog(t, findTask(t, r, task.Id).TimeLog, []items.TimeEntry{worked, pomodoro})
+
+	// A running timer, kept by status changes
+	running := items.TimeEntry{Start: baseTime.Add(24 * time.Hour)}
+	task = findTask(t, r, task.Id)
+	task.TimeLog = append(task.TimeLog, running)
+	if err := r.UpdateTask(task); err != nil {
+		t.Fatalf("UpdateTask: %v", err)
+	}
+	if err := r.UpdateTaskStatus(task, items.InProgress); err != nil {
+		t.Fatalf("UpdateTaskStatus: %v", err)
+	}
+	got := findTask(t, r, task.Id)
+	checkTimeLog(t, got.TimeLog, []items.TimeEntry{worked, pomodoro, running})
+	if !got.TimerRunning() {
+		t.Errorf("timer not running")
+	}
+
+	got.TimeLog = nil
+	if err := r.UpdateTask(got); err != nil {
+		t.Fatalf("UpdateTask: %v", err)
+	}
+	checkTimeLog(t, findTask(t, r, task.Id).TimeLog, nil)
+}
+
+// CheckNoTimeLog checks that storing time entries on task, stored in r where they
+// can't be kept (e.g. an Obsidian inline task), fails with repository.ErrNoTimeLog
+// and leaves the task as it was.
+func CheckNoTimeLog(t *testing.T, r repository.Repository, task items.Task) {
+	t.Helper()
+	tracked := task
+	tracked.Body = "Tracked"
+	tracked.TimeLog = []items.TimeEntry{{Start: baseTime, End: baseTime.Add(time.Hour)}}
+	if err := r.UpdateTask(tracked); !errors.Is(err, repository.ErrNoTimeLog) {
+		t.Fatalf("UpdateTask with a time log: got %v, want ErrNoTimeLog", err)
+	}
+	got := findTask(t, r, task.Id)
+	if got.Body != task.Body || len(got.TimeLog) != 0 {
+		t.Errorf("task changed by a refused update: %+v", got)
+	}
+}
+
+func checkTimeLog(t *testing.T, got, want []items.TimeEntry) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("time log has %d entries, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if !got[i].Start.Equal(want[i].Start) || !got[i].End.Equal(want[i].End) {
+			t.Errorf("time entry %d = %v → %v, want %v → %v", i, got[i].Start, got[i].End, want[i].Start, want[i].End)
+		}
+		if got[i].Pomodoro != want[i].Pomodoro {
+			t.Errorf("time entry %d pomodoro = %v, want %v", i, got[i].Pomodoro, want[i].Pomodoro)
+		}
+	}
+}
+
+func testDuplicateTitles(t *testing.T, r repository.Repository, o options) {
+	first := createTask(t, r, items.Task{
+		Item:   items.Item{Title: "Same title", Body: "first", CreatedAt: baseTime},
+		Status: items.Todo,
+	})
+	second := createTask(t, r, items.Task{
+		Item:   items.Item{Title: "Same title", Body: "second", CreatedAt: baseTime.Add(time.Minute)},
+		Status: items.Todo,
+	})
+	if first.Id == second.Id {
+		t.Fatalf("tasks with the same title share the ID %q", first.Id)
+	}
+
+	// Updating an item without renaming it must keep its ID
+	second.Body = "second, edited"
+	if err := r.UpdateTask(second); err != nil {
+		t.Fatalf("UpdateTask: %v", err)
+	}
+	checkTask(t, o, findTask(t, r, first.Id), first)
+	checkTask(t, o, findTask(t, r, second.Id), second)
+
+	if err := r.UpdateTaskStatus(second, items.Done); err != nil {
+		t.Fatalf("UpdateTaskStatus: %v", err)
+	}
+	if got := findTask(t, r, first.Id); got.Status != items.Todo {
+		t.Errorf("updating a task changed another one with the same title")
+	}
+}
+
+func testRemove(t *testing.T, r repository.Repository, o options) {
+	keep := createTask(t, r, items.Task{Item: items.Item{Title: "Keep", CreatedAt: baseTime}, Status: items.Todo})
+	drop := createTask(t, r, items.Task{Item: items.Item{Title: "Drop", CreatedAt: baseTime}, Status: items.Todo})
+	note := createNote(t, r, items.Note{Item: items.Item{Title: "Drop note", CreatedAt: baseTime}})
+
+	if err := r.RemoveTask(drop.Id); err != nil {
+		t.Fatalf("RemoveTask: %v", err)
+	}
+	tasks := getTasks(t, r)
+	if len(tasks) != 1 || tasks[0].Id != keep.Id {
+		t.Fatalf("got %d tasks after removing one, want only %q", len(tasks), keep.Title)
+	}
+
+	if err := r.RemoveNote(note.Id); err != nil {
+		t.Fatalf("RemoveNote: %v", err)
+	}
+	if notes := getNotes(t, r); len(notes) != 0 {
+		t.Fatalf("got %d notes after removing the only one", len(notes))
+	}
+
+	if err := r.RemoveTask(drop.Id); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("removing a task twice: got %v, want ErrNotFound", err)
+	}
+	if err := r.RemoveNote(note.Id); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("removing a note twice: got %v, want ErrNotFound", err)
+	}
+}
+
+func testMissingItems(t *testing.T, r repository.Repository, o options) {
+	task := items.Task{Item: items.Item{Id: "missing", Title: "Missing"}, Status: items.Todo}
+	note := items.Note{Item: items.Item{Id: "missing", Title: "Missing"}}
+	tag := createTag(t, r, "work")
+
+	checks := map[string]error{
+		"UpdateTask":       r.UpdateTask(task),
+		"UpdateTaskStatus": r.UpdateTaskStatus(task, items.Done),
+		"RemoveTask":       r.RemoveTask(task.Id),
+		"SetTaskTag":       r.SetTaskTag(task, tag),
+		"UnsetTaskTag":     r.UnsetTaskTag(task),
+		"UpdateNote":       r.UpdateNote(note),
+		"RemoveNote":       r.RemoveNote(note.Id),
+		"SetNoteTag":       r.SetNoteTag(note, tag),
+		"UnsetNoteTag":     r.UnsetNoteTag(note),
+	}
+	for name, err := range checks {
+		if !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("%s on a missing item: got %v, want ErrNotFound", name, err)
+		}
+	}
+
+	if tasks := getTasks(t, r); len(tasks) != 0 {
+		t.Errorf("operations on missing items created %d tasks", len(tasks))
+	}
+	if notes := getNotes(t, r); len(notes) != 0 {
+		t.Errorf("operations on missing items created %d notes", len(notes))
+	}
+}
+
+func testExpecting(t *testing.T, r repository.Repository, o options) {
+	tag := createTag(t, r, "work")
+	created := createTask(t, r, items.Task{Item: items.Item{Title: "Guarded", CreatedAt: baseTime}, Status: items.Todo, Priority: items.PriorityMedium})
+	createdNote := createNote(t, r, items.Note{Item: items.Item{Title: "Guarded note", Body: "Before", CreatedAt: baseTime}})
+	task := findTask(t, r, created.Id)
+	note := findNote(t, r, createdNote.Id)
+
+	// Changed by another process after they were loaded
+	changedTask := task
+	changedTask.Priority = items.PriorityHigh
+	if err := r.UpdateTask(changedTask); err != nil {
+		t.Fatalf("UpdateTask: %v", err)
+	}
+	changedNote := note
+	changedNote.Body = "After"
+	if err := r.UpdateNote(changedNote); err != nil {
+		t.Fatalf("UpdateNote: %v", err)
+	}
+
+	edited := task
+	edited.Body = "Edited"
+	staleTask, staleNote := repository.Expect(r, &task), repository.Expect(r, &note)
+	checks := map[string]error{
+		"UpdateTask":       staleTask.UpdateTask(edited),
+		"UpdateTaskStatus": staleTask.UpdateTaskStatus(task, items.Done),
+		"SetTaskTag":       staleTask.SetTaskTag(task, tag),
+		"UnsetTaskTag":     staleTask.UnsetTaskTag(task),
+		"RemoveTask":       staleTask.RemoveTask(task.Id),
+		"UpdateNote":       staleNote.UpdateNote(note),
+		"SetNoteTag":       staleNote.SetNoteTag(note, tag),
+		"UnsetNoteTag":     staleNote.UnsetNoteTag(note),
+		"RemoveNote":       staleNote.RemoveNote(note.Id),
+	}
+	for name, err := range checks {
+		if !errors.Is(err, repository.ErrConflict) {
+			t.Errorf("%s of a stale item: got %v, want ErrConflict", name, err)
+		}
+	}
+	got := findTask(t, r, task.Id)
+	if got.Priority != items.PriorityHigh || got.Status != items.Todo || got.Body != "" || got.Tag != nil {
+		t.Errorf("changes of a stale task were stored: %+v", got)
+	}
+	if got := findNote(t, r, note.Id); got.Body != "After" || got.Tag != nil {
+		t.Errorf("changes of a stale note were stored: %+v", got)
+	}
+
+	// Up to date, the changes go through
+	if err := repository.Expect(r, &got).UpdateTaskStatus(got, items.Done); err != nil {
+		t.Fatalf("UpdateTaskStatus of an unchanged task: %v", err)
+	}
+	if got := findTask(t, r, task.Id); got.Status != items.Done {
+		t.Errorf("status = %s, want %s", got.Status, items.Done)
+	}
+	current := findNote(t, r, note.Id)
+	if err := repository.Expect(r, &current).RemoveNote(current.Id); err != nil {
+		t.Fatalf("RemoveNote of an unchanged note: %v", err)
+	}
+
+	// Removed since loaded
+	if err := repository.Expect(r, &current).UpdateNote(current); !errors.Is(err, repository.ErrConflict) {
+		t.Errorf("UpdateNote of a removed note: got %v, want ErrConflict", err)
+	}
+}
+
+func testTags(t *testing.T, r repository.Repository, o options) {
+	if _, err := r.GetTag("work"); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("GetTag of an unknown tag: got %v, want ErrNotFound", err)
+	}
+
+	task := createTask(t, r, items.Task{Item: items.Item{Title: "Report", CreatedAt: baseTime}, Status: items.Todo})
+	note := createNote(t, r, items.Note{Item: items.Item{Title: "Agenda", CreatedAt: baseTime}})
+	other := createTask(t, r, items.Task{Item: items.Item{Title: "Groceries", CreatedAt: baseTime}, Status: items.Todo})
+
+	work := createTag(t, r, "work")
+	home := createTag(t, r, "home")
+	if work.Name != "work" {
+		t.Errorf("created tag name = %q, want %q", work.Name, "work")
+	}
+
+	if err := r.SetTaskTag(task, work); err != nil {
+		t.Fatalf("SetTaskTag: %v", err)
+	}
+	if err := r.SetNoteTag(note, work); err != nil {
+		t.Fatalf("SetNoteTag: %v", err)
+	}
+	if err := r.SetTaskTag(other, home); err != nil {
+		t.Fatalf("SetTaskTag: %v", err)
+	}
+
+	tag, err := r.GetTag("work")
+	if err != nil {
+		t.Fatalf("GetTag: %v", err)
+	}
+	if tag.Name != "work" {
+		t.Errorf("GetTag name = %q, want %q", tag.Name, "work")
+	}
+
+	if got := tagName(findTask(t, r, task.Id).Tag); got != "work" {
+		t.Errorf("task tag = %q, want %q", got, "work")
+	}
+	if got := tagName(findNote(t, r, note.Id).Tag); got != "work" {
+		t.Errorf("note tag = %q, want %q", got, "work")
+	}
+
+	tags, err := r.GetTags()
+	if err != nil {
+		t.Fatalf("GetTags: %v", err)
+	}
+	var names []string
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	if want := []string{"home", "work"}; !equalStrings(names, want) {
+		t.Errorf("GetTags = %v, want %v sorted by name", names, want)
+	}
+
+	tagged, err := r.GetItemsWithTag("work")
+	if err != nil {
+		t.Fatalf("GetItemsWithTag: %v", err)
+	}
+	if got, want := sortedTitles(tagged), []string{"Agenda", "Report"}; !equalStrings(got, want) {
+		t.Errorf("GetItemsWithTag = %v, want %v", got, want)
+	}
+	for _, i := range tagged {
+		if tagName(i.GetTag()) != "work" {
+			t.Errorf("item %q returned for tag work has tag %q", i.GetTitle(), tagName(i.GetTag()))
+		}
+	}
+
+	// Moving a task to another tag
+	if err := r.SetTaskTag(task, home); err != nil {
+		t.Fatalf("SetTaskTag: %v", err)
+	}
+	if got := tagName(findTask(t, r, task.Id).Tag); got != "home" {
+		t.Errorf("task tag = %q, want %q", got, "home")
+	}
+
+	if err := r.UnsetTaskTag(task); err != nil {
+		t.Fatalf("UnsetTaskTag: %v", err)
+	}
+	if err := r.UnsetNoteTag(note); err != nil {
+		t.Fatalf("UnsetNoteTag: %v", err)
+	}
+	if got := findTask(t, r, task.Id).Tag; got != nil {
+		t.Errorf("task tag = %q after unsetting it", got.Name)
+	}
+	if got := findNote(t, r, note.Id).Tag; got != nil {
+		t.Errorf("note tag = %q after unsetting it", got.Name)
+	}
+
+	tagged, err = r.GetItemsWithTag("work")
+	if err != nil {
+		t.Fatalf("GetItemsWithTag: %v", err)
+	}
+	if len(tagged) != 0 {
+		t.Errorf("GetItemsWithTag(work) = %v after unsetting every use", titles(tagged))
+	}
+	tagged, err = r.GetItemsWithTag("home")
+	if err != nil {
+		t.Fatalf("GetItemsWithTag: %v", err)
+	}
+	if got, want := titles(tagged), []string{"Groceries"}; !equalStrings(got, want) {
+		t.Errorf("GetItemsWithTag(home) = %v, want %v", got, want)
+	}
+}
+
+func testRemoveTag(t *testing.T, r repository.Repository, o options) {
+	if err := r.RemoveTag("unknown"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("RemoveTag of an unknown tag: got %v, want ErrNotFound", err)
+	}
+
+	task := createTask(t, r, items.Task{Item: items.Item{Title: "Tagged", CreatedAt: baseTime}, Status: items.Todo})
+	tag := createTag(t, r, "errands")
+	if err := r.SetTaskTag(task, tag); err != nil {
+		t.Fatalf("SetTaskTag: %v", err)
+	}
+	if err := r.UnsetTaskTag(task); err != nil {
+		t.Fatalf("UnsetTaskTag: %v", err)
+	}
+
+	// Backends that only know tags through their items (Obsidian) report an
+	// unused tag as not found, which is fine as long as it's gone afterwards.
+	if err := r.RemoveTag("errands"); err != nil && !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("RemoveTag: %v", err)
+	}
+	if _, err := r.GetTag("errands"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("GetTag after RemoveTag: got %v, want ErrNotFound", err)
+	}
+	tags, err := r.GetTags()
+	if err != nil {
+		t.Fatalf("GetTags: %v", err)
+	}
+	if len(tags) != 0 {
+		t.Errorf("GetTags = %v after removing the only tag", tags)
+	}
+}
+
+// testConversions converts items between tasks and notes the way the service does,
+// by removing the item and creating one of the other type with the same content.
+func testConversions(t *testing.T, r repository.Repository, o options) {
+	tag := createTag(t, r, "work")
+	task := createTask(t, r, items.Task{
+		Item:   items.Item{Title: "Plan the offsite", Body: "Venue and dates", CreatedAt: baseTime},
+		Status: items.InProgress,
+	})
+	if err := r.SetTaskTag(task, tag); err != nil {
+		t.Fatalf("SetTaskTag: %v", err)
+	}
+
+	// Task to note
+	if err := r.RemoveTask(task.Id); err != nil {
+		t.Fatalf("RemoveTask: %v", err)
+	}
+	note := createNote(t, r, items.Note{Item: items.Item{Title: task.Title, Body: task.Body, CreatedAt: task.CreatedAt}})
+	if err := r.SetNoteTag(note, tag); err != nil {
+		t.Fatalf("SetNoteTag: %v", err)
+	}
+
+	if tasks := getTasks(t, r); len(tasks) != 0 {
+		t.Fatalf("got %d tasks after converting the only task to a note", len(tasks))
+	}
+	notes := getNotes(t, r)
+	if len(notes) != 1 {
+		t.Fatalf("got %d notes after the conversion, want 1", len(notes))
+	}
+	checkNote(t, notes[0], items.Note{Item: items.Item{Title: task.Title, Body: task.Body, CreatedAt: task.CreatedAt, Tag: &tag}})
+
+	// And back to a task
+	if err := r.RemoveNote(note.Id); err != nil {
+		t.Fatalf("RemoveNote: %v", err)
+	}
+	back := createTask(t, r, items.Task{Item: items.Item{Title: note.Title, Body: note.Body, CreatedAt: note.CreatedAt}, Status: items.Todo})
+	if err := r.SetTaskTag(back, tag); err != nil {
+		t.Fatalf("SetTaskTag: %v", err)
+	}
+
+	if notes := getNotes(t, r); len(notes) != 0 {
+		t.Fatalf("got %d notes after converting the only note to a task", len(notes))
+	}
+	back.Tag = &tag
+	checkTask(t, o, findTask(t, r, back.Id), back)
+
+	tagged, err := r.GetItemsWithTag("work")
+	if err != nil {
+		t.Fatalf("GetItemsWithTag: %v", err)
+	}
+	if len(tagged) != 1 {
+		t.Fatalf("GetItemsWithTag = %v, want only the converted task", titles(tagged))
+	}
+	if _, ok := tagged[0].(*items.Task); !ok {
+		t.Errorf("GetItemsWithTag returned a %T, want *items.Task", tagged[0])
+	}
+}
+
+// testOrdering checks that backends keep everything the item list is sorted by,
+// so the same items are listed in the same order (and under the same index) everywhere.
+func testOrdering(t *testing.T, r repository.Repository, o options) {
+	work := createTag(t, r, "work")
+
+	var want []items.ItemInterface
+	for i, title := range []string{"Oldest", "Tagged old", "Middle", "Tagged new", "Newest"} {
+		createdAt := baseTime.AddDate(0, 0, i)
+		var tag *items.Tag
+		if title == "Tagged old" || title == "Tagged new" {
+			tag = &work
+		}
+
+		if i%2 == 0 {
+			task := createTask(t, r, items.Task{Item: items.Item{Title: title, CreatedAt: createdAt}, Status: items.Todo})
+			if tag != nil {
+				if err := r.SetTaskTag(task, *tag); err != nil {
+					t.Fatalf("SetTaskTag: %v", err)
+				}
+			}
+			task.Tag = tag
+			want = append(want, &task)
+		} else {
+			note := createNote(t, r, items.Note{Item: items.Item{Title: title, CreatedAt: createdAt}})
+			if tag != nil {
+				if err := r.SetNoteTag(note, *tag); err != nil {
+					t.Fatalf("SetNoteTag: %v", err)
+				}
+			}
+			note.Tag = tag
+			want = append(want, &note)
+		}
+	}
+
+	var got []items.ItemInterface
+	for _, note := range getNotes(t, r) {
+		got = append(got, &note)
+	}
+	for _, task := range getTasks(t, r) {
+		got = append(got, &task)
+	}
+
+	for _, list := range [][]items.ItemInterface{want, got} {
+		sort.SliceStable(list, func(i, j int) bool {
+			return list[i].After(list[j])
+		})
+	}
+	if !equalStrings(titles(got), titles(want)) {
+		t.Errorf("sorted items = %v, want %v", titles(got), titles(want))
+	}
+}
@@ -0,0 +1,181 @@
+package reposync
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/markelca/prioritty/pkg/items"
+	"github.com/markelca/prioritty/pkg/items/repository"
+	"github.com/markelca/prioritty/pkg/items/repository/internal/atomicfile"
+	"github.com/markelca/prioritty/pkg/items/repository/internal/filelock"
+)
+
+// stateVersion is the version of the state file format, bumped on incompatible changes.
+// Version 2 records the pair of repositories synced.
+const stateVersion = 2
+
+// tombstoneTTL is how long deletions are remembered. Past it, an item coming back
+// with the same UID (e.g. from an old backup) is synced as a new item.
+const tombstoneTTL = 90 * 24 * time.Hour
+
+// ErrStateMismatch is returned when syncing with the state of another pair of
+// repositories, whose entries would be taken for changes.
+var ErrStateMismatch = errors.New("the sync state belongs to other repositories")
+
+// State is the sync state table: one entry per item synced between the two
+// repositories, with what each side looked like after the last sync.
+type State struct {
+	Version int      `json:"version"`
+	Pair    Pair     `json:"pair"` // empty until the first sync, and in version 1 states
+	Entries []*Entry `json:"entries"`
+}
+
+// Location is where one of the synced repositories is stored.
+type Location struct {
+	Type string `json:"type"`
+	Path string `json:"path"`
+}
+
+// Pair identifies the two repositories a state belongs to.
+type Pair struct {
+	Local  Location `json:"local"`
+	Remote Location `json:"remote"`
+}
+
+// Key returns a name for the state file of the pair, e.g. "sqlite-obsidian-5f1c0e9a3b2d".
+func (p Pair) Key() string {
+	sum := sha1.Sum([]byte(strings.Join([]string{p.Local.Type, p.Local.Path, p.Remote.Type, p.Remote.Path}, "\x00")))
+	return p.Local.Type + "-" + p.Remote.Type + "-" + hex.EncodeToString(sum[:6])
+}
+
+func (p Pair) String() string {
+	return fmt.Sprintf("%s %s with %s %s", p.Local.Type, p.Local.Path, p.Remote.Type, p.Remote.Path)
+}
+
+// DefaultStatePath returns the path of the state file of the pair in dir.
+func DefaultStatePath(dir string, p Pair) string {
+	return filepath.Join(dir, p.Key()+".json")
+}
+
+// Entry is the sync state of one item, identified by its UID.
+type Entry struct {
+	UID       string         `json:"uid"`
+	Type      items.ItemType `json:"type"`
+	Local     Side           `json:"local"`
+	Remote    Side           `json:"remote"`
+	SyncedAt  time.Time      `json:"synced_at"`
+	DeletedAt *time.Time     `json:"deleted_at,omitempty"` // set for tombstones
+}
+
+// Side is an item as it was in one repository after the last sync.
+type Side struct {
+	Id         string    `json:"id"`
+	Hash       string    `json:"hash"`
+	ModifiedAt time.Time `json:"modified_at"`
+}
+
+// Deleted reports whether the entry is a tombstone: the item was removed from both sides.
+func (e *Entry) Deleted() bool {
+	return e.DeletedAt != nil
+}
+
+// LoadState reads the state file at path. A missing file is an empty state,
+// as in the first sync.
+func LoadState(path string) (*State, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &State{Version: stateVersion}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var state State
+	if err := json.Unmarshal(content, &state); err != nil {
+		return nil, fmt.Errorf("invalid sync state %s: %w", path, err)
+	}
+	if state.Version > stateVersion {
+		return nil, fmt.Errorf("unsupported sync state version %d", state.Version)
+	}
+	return &state, nil
+}
+
+// Bind makes the state the one of the pair, failing with ErrStateMismatch if it
+// belongs to another. States without a pair (new, or from version 1) take it.
+func (s *State) Bind(p Pair) error {
+	if s.Pair == (Pair{}) {
+		s.Pair = p
+		return nil
+	}
+	if s.Pair != p {
+		return fmt.Errorf("%w: it syncs %s, not %s", ErrStateMismatch, s.Pair, p)
+	}
+	return nil
+}
+
+// Save writes the state to path atomically.
+func (s *State) Save(path string) error {
+	s.Version = stateVersion
+	content, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(path, append(content, '\n'))
+}
+
+func (s *State) entry(uid string) *Entry {
+	for _, e := range s.Entries {
+		if e.UID == uid {
+			return e
+		}
+	}
+	return nil
+}
+
+// prune drops the tombstones older than tombstoneTTL.
+func (s *State) prune(now time.Time) {
+	var entries []*Entry
+	for _, e := range s.Entries {
+		if e.Deleted() && now.Sub(*e.DeletedAt) > tombstoneTTL {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	s.Entries = entries
+}
+
+// SyncWithState runs Sync with the state of pair stored at statePath, holding a lock
+// on it so two syncs can't run at the same time. It fails with ErrStateMismatch if
+// the state is another pair's. The state is saved unless opts.DryRun is set.
+func SyncWithState(statePath string, pair Pair, local, remote repository.Repository, opts Options) (Result, error) {
+	var result Result
+	if err := os.MkdirAll(filepath.Dir(statePath), 0755); err != nil {
+		return result, err
+	}
+	err := filelock.With(statePath+".lock", func() error {
+		state, err := LoadState(statePath)
+		if err != nil {
+			return err
+		}
+		if err := state.Bind(pair); err != nil {
+			return err
+		}
+		var syncErr error
+		result, syncErr = Sync(local, remote, state, opts)
+		if opts.DryRun {
+			return syncErr
+		}
+		if err := state.Save(statePath); err != nil {
+			return errors.Join(syncErr, fmt.Errorf("failed to save the sync state: %w", err))
+		}
+		return syncErr
+	})
+	return result, err
+}
@@ -0,0 +1,587 @@
+// Package reposync keeps two repositories in sync, e.g. a SQLite database used by
+// the CLI and an Obsidian vault read on mobile.
+//
+// Items are paired by UID. Items without one get a new UID on their first sync,
+// unless the other side has an item with the same type, title and creation day,
+// which is then considered the same item. The State records what each item looked
+// like on both sides after the last sync, so changes are detected by comparing
+// content hashes: an item changed on one side is copied to the other, an item
+// changed on both is a conflict resolved with a Policy, and an item removed from
+// one side is removed from the other and remembered as a tombstone.
+package reposync
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/markelca/prioritty/pkg/items"
+	"github.com/markelca/prioritty/pkg/items/repository"
+)
+
+// Policy decides which version of an item changed on both sides is kept.
+type Policy string
+
+const (
+	PolicyNewest Policy = "newest" // the most recently modified version
+	PolicyLocal  Policy = "local"  // the local version
+	PolicyRemote Policy = "remote" // the remote version
+	PolicySkip   Policy = "skip"   // none, the conflict is reported on every sync until both sides agree
+)
+
+// ParsePolicy converts a string to Policy. An empty string is PolicyNewest.
+func ParsePolicy(s string) (Policy, error) {
+	switch p := Policy(strings.ToLower(strings.TrimSpace(s))); p {
+	case "":
+		return PolicyNewest, nil
+	case PolicyNewest, PolicyLocal, PolicyRemote, PolicySkip:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown sync policy %q (newest, local, remote, skip)", s)
+	}
+}
+
+// Options configure a Sync.
+type Options struct {
+	Policy Policy
+	DryRun bool // report the changes without making them or updating the state
+}
+
+// Change is a modification made to one of the repositories.
+type Change struct {
+	Side   string // "local" or "remote"
+	Action string // "created", "updated" or "deleted"
+	Type   items.ItemType
+	Title  string
+}
+
+// Conflict is an item whose versions couldn't be reconciled automatically.
+type Conflict struct {
+	Type       items.ItemType
+	Title      string
+	Resolution string
+}
+
+// Result lists what a Sync did.
+type Result struct {
+	Changes   []Change
+	Conflicts []Conflict
+}
+
+// side is one of the synced repositories, with its items by UID.
+type side struct {
+	name  string
+	repo  repository.Repository
+	items map[string]items.ItemInterface
+	order []string // UIDs in the repository's order, for a stable output
+}
+
+func newSide(name string, repo repository.Repository) *side {
+	return &side{name: name, repo: repo}
+}
+
+// load reads the items of the repository, returning the ones without UID separately.
+func (s *side) load() ([]items.ItemInterface, error) {
+	s.items = make(map[string]items.ItemInterface)
+	s.order = nil
+
+	tasks, err := s.repo.GetTasks()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", s.name, err)
+	}
+	notes, err := s.repo.GetNotes()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", s.name, err)
+	}
+
+	var all []items.ItemInterface
+	for i := range tasks {
+		all = append(all, &tasks[i])
+	}
+	for i := range notes {
+		all = append(all, &notes[i])
+	}
+
+	var withoutUID []items.ItemInterface
+	for _, i := range all {
+		if i.GetUID() == "" {
+			withoutUID = append(withoutUID, i)
+			continue
+		}
+		s.add(i)
+	}
+	return withoutUID, nil
+}
+
+func (s *side) add(i items.ItemInterface) {
+	if _, ok := s.items[i.GetUID()]; !ok {
+		s.order = append(s.order, i.GetUID())
+	}
+	s.items[i.GetUID()] = i
+}
+
+// entrySide returns the part of the entry describing this side.
+func (s *side) entrySide(e *Entry) *Side {
+	if s.name == "local" {
+		return &e.Local
+	}
+	return &e.Remote
+}
+
+// setUID stores a new UID for an item that didn't have one.
+func (s *side) setUID(i items.ItemInterface, uid string, dryRun bool) error {
+	switch v := i.(type) {
+	case *items.Task:
+		v.UID = uid
+		if !dryRun {
+			if err := s.repo.UpdateTask(*v); err != nil {
+				return fmt.Errorf("%s: failed to set the UID of task %q: %w", s.name, v.Title, err)
+			}
+		}
+	case *items.Note:
+		v.UID = uid
+		if !dryRun {
+			if err := s.repo.UpdateNote(*v); err != nil {
+				return fmt.Errorf("%s: failed to set the UID of note %q: %w", s.name, v.Title, err)
+			}
+		}
+	}
+	s.add(i)
+	return nil
+}
+
+func (s *side) create(i items.ItemInterface) error {
+	var err error
+	switch v := i.(type) {
+	case *items.Task:
+		_, err = repository.CopyTask(s.repo, *v)
+	case *items.Note:
+		_, err = repository.CopyNote(s.repo, *v)
+	}
+	return err
+}
+
+// update overwrites current with the contents of from, keeping its ID.
+func (s *side) update(current, from items.ItemInterface) error {
+	if typeOf(current) != typeOf(from) {
+		if err := s.remove(current); err != nil {
+			return err
+		}
+		return s.create(from)
+	}
+
+	tag, err := s.tagOf(from)
+	if err != nil {
+		return err
+	}
+	// Tags first, Obsidian changes the ID of renamed items
+	switch v := current.(type) {
+	case *items.Task:
+		if tagName(current) != tagName(from) {
+			if tag == nil {
+				err = s.repo.UnsetTaskTag(*v)
+			} else {
+				err = s.repo.SetTaskTag(*v, *tag)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		t := *from.(*items.Task)
+		t.Id = v.Id
+		t.Tag = tag
+		// Sides without time tracking (e.g. todo.txt) keep the time logged here
+		if len(t.TimeLog) == 0 {
+			t.TimeLog = v.TimeLog
+		}
+		err = s.repo.UpdateTask(t)
+		if errors.Is(err, repository.ErrNoTimeLog) {
+			// Kept on the side that can store it
+			t.TimeLog = nil
+			err = s.repo.UpdateTask(t)
+		}
+		return err
+	case *items.Note:
+		if tagName(current) != tagName(from) {
+			if tag == nil {
+				err = s.repo.UnsetNoteTag(*v)
+			} else {
+				err = s.repo.SetNoteTag(*v, *tag)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		n := *from.(*items.Note)
+		n.Id = v.Id
+		n.Tag = tag
+		return s.repo.UpdateNote(n)
+	}
+	return nil
+}
+
+// tagOf returns this side's tag with the name of the item's tag, creating it if needed.
+func (s *side) tagOf(i items.ItemInterface) (*items.Tag, error) {
+	if i.GetTag() == nil {
+		return nil, nil
+	}
+	return repository.GetOrCreateTag(s.repo, i.GetTag().Name)
+}
+
+func (s *side) remove(i items.ItemInterface) error {
+	switch v := i.(type) {
+	case *items.Task:
+		return s.repo.RemoveTask(v.Id)
+	case *items.Note:
+		return s.repo.RemoveNote(v.Id)
+	}
+	return nil
+}
+
+type syncer struct {
+	local, remote *side
+	state         *State
+	opts          Options
+	now           time.Time
+	result        Result
+	skipped       map[string]bool // UIDs of conflicts left unresolved
+}
+
+// Sync reconciles local and remote, updating state. Unless opts.DryRun is set, the
+// caller must save the state afterwards, even if an error is returned, so the
+// changes made until then aren't seen as new edits on the next sync.
+func Sync(local, remote repository.Repository, state *State, opts Options) (Result, error) {
+	if opts.Policy == "" {
+		opts.Policy = PolicyNewest
+	}
+	s := &syncer{
+		local:   newSide("local", local),
+		remote:  newSide("remote", remote),
+		state:   state,
+		opts:    opts,
+		now:     time.Now(),
+		skipped: make(map[string]bool),
+	}
+
+	if err := s.loadAndPair(); err != nil {
+		return s.result, err
+	}
+
+	uids := s.uids()
+	for _, uid := range uids {
+		if err := s.syncItem(uid); err != nil {
+			return s.result, err
+		}
+	}
+
+	if opts.DryRun {
+		return s.result, nil
+	}
+	if err := s.record(uids); err != nil {
+		return s.result, err
+	}
+	s.state.prune(s.now)
+	return s.result, nil
+}
+
+// loadAndPair loads both sides and gives a UID to the items that don't have one.
+func (s *syncer) loadAndPair() error {
+	localNew, err := s.local.load()
+	if err != nil {
+		return err
+	}
+	remoteNew, err := s.remote.load()
+	if err != nil {
+		return err
+	}
+
+	// Remote items that could be the same as a new local item: new ones, and those
+	// whose UID isn't known locally or in the state
+	var candidates []items.ItemInterface
+	candidates = append(candidates, remoteNew...)
+	for _, uid := range s.remote.order {
+		if _, ok := s.local.items[uid]; !ok && s.state.entry(uid) == nil {
+			candidates = append(candidates, s.remote.items[uid])
+		}
+	}
+	taken := make([]bool, len(candidates))
+
+	for _, i := range localNew {
+		uid := ""
+		for k, c := range candidates {
+			if !taken[k] && typeOf(c) == typeOf(i) && c.GetTitle() == i.GetTitle() && sameDay(c.GetCreatedAt(), i.GetCreatedAt()) {
+				taken[k] = true
+				uid = c.GetUID()
+				if uid == "" {
+					uid = items.NewUID()
+					if err := s.remote.setUID(c, uid, s.opts.DryRun); err != nil {
+						return err
+					}
+				}
+				break
+			}
+		}
+		if uid == "" {
+			uid = items.NewUID()
+		}
+		if err := s.local.setUID(i, uid, s.opts.DryRun); err != nil {
+			return err
+		}
+	}
+	for k, i := range remoteNew {
+		if !taken[k] {
+			if err := s.remote.setUID(i, items.NewUID(), s.opts.DryRun); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// uids returns the UIDs of all items and state entries, each once.
+func (s *syncer) uids() []string {
+	seen := make(map[string]bool)
+	var uids []string
+	add := func(uid string) {
+		if !seen[uid] {
+			seen[uid] = true
+			uids = append(uids, uid)
+		}
+	}
+	for _, uid := range s.local.order {
+		add(uid)
+	}
+	for _, uid := range s.remote.order {
+		add(uid)
+	}
+	for _, e := range s.state.Entries {
+		add(e.UID)
+	}
+	return uids
+}
+
+func (s *syncer) syncItem(uid string) error {
+	l, r := s.local.items[uid], s.remote.items[uid]
+	e := s.state.entry(uid)
+
+	switch {
+	case l != nil && r != nil:
+		if hashItem(l) == hashItem(r) {
+			return nil
+		}
+		localChanged := e == nil || e.Deleted() || !unchangedSince(l, e.Local)
+		remoteChanged := e == nil || e.Deleted() || !unchangedSince(r, e.Remote)
+		switch {
+		case !localChanged && !remoteChanged:
+			// Still as synced, they differ by what a side can't keep (e.g. todo.txt time logs)
+			return nil
+		case localChanged && !remoteChanged:
+			return s.update(s.remote, r, l)
+		case remoteChanged && !localChanged:
+			return s.update(s.local, l, r)
+		default:
+			return s.conflict(uid, l, r)
+		}
+	case l != nil:
+		return s.syncOneSided(uid, s.local, s.remote, l, e)
+	case r != nil:
+		return s.syncOneSided(uid, s.remote, s.local, r, e)
+	}
+	return nil
+}
+
+// syncOneSided handles an item that only exists on the present side: it's either
+// new there or was removed from the other side.
+func (s *syncer) syncOneSided(uid string, present, other *side, i items.ItemInterface, e *Entry) error {
+	if e == nil {
+		return s.create(other, i)
+	}
+
+	unchanged := unchangedSince(i, *present.entrySide(e))
+	switch {
+	case unchanged:
+		// Removed on the other side (or both, and then restored here from an old copy)
+		return s.remove(present, i)
+	case e.Deleted():
+		// Restored and edited after its removal, it's a new item again
+		return s.create(other, i)
+	default:
+		// Edits win over removals: the item is recreated on the other side
+		s.result.Conflicts = append(s.result.Conflicts, Conflict{
+			Type:       typeOf(i),
+			Title:      i.GetTitle(),
+			Resolution: fmt.Sprintf("edited on the %s side after being deleted on the %s side, restored it", present.name, other.name),
+		})
+		return s.create(other, i)
+	}
+}
+
+func (s *syncer) conflict(uid string, l, r items.ItemInterface) error {
+	var winner, loser *side
+	switch s.opts.Policy {
+	case PolicyLocal:
+		winner, loser = s.local, s.remote
+	case PolicyRemote:
+		winner, loser = s.remote, s.local
+	case PolicyNewest:
+		winner, loser = s.local, s.remote
+		if r.GetUpdatedAt().After(l.GetUpdatedAt()) {
+			winner, loser = s.remote, s.local
+		}
+	default:
+		s.skipped[uid] = true
+		s.result.Conflicts = append(s.result.Conflicts, Conflict{
+			Type:       typeOf(l),
+			Title:      l.GetTitle(),
+			Resolution: "changed on both sides, skipped",
+		})
+		return nil
+	}
+
+	s.result.Conflicts = append(s.result.Conflicts, Conflict{
+		Type:       typeOf(l),
+		Title:      l.GetTitle(),
+		Resolution: fmt.Sprintf("changed on both sides, kept the %s version", winner.name),
+	})
+	return s.update(loser, loser.items[uid], winner.items[uid])
+}
+
+func (s *syncer) create(dst *side, i items.ItemInterface) error {
+	s.result.Changes = append(s.result.Changes, Change{Side: dst.name, Action: "created", Type: typeOf(i), Title: i.GetTitle()})
+	if s.opts.DryRun {
+		return nil
+	}
+	if err := dst.create(i); err != nil {
+		return fmt.Errorf("%s: %w", dst.name, err)
+	}
+	return nil
+}
+
+func (s *syncer) update(dst *side, current, from items.ItemInterface) error {
+	s.result.Changes = append(s.result.Changes, Change{Side: dst.name, Action: "updated", Type: typeOf(from), Title: from.GetTitle()})
+	if s.opts.DryRun {
+		return nil
+	}
+	if err := dst.update(current, from); err != nil {
+		return fmt.Errorf("%s: failed to update %q: %w", dst.name, current.GetTitle(), err)
+	}
+	return nil
+}
+
+func (s *syncer) remove(dst *side, i items.ItemInterface) error {
+	s.result.Changes = append(s.result.Changes, Change{Side: dst.name, Action: "deleted", Type: typeOf(i), Title: i.GetTitle()})
+	if s.opts.DryRun {
+		return nil
+	}
+	if err := dst.remove(i); err != nil {
+		return fmt.Errorf("%s: failed to delete %q: %w", dst.name, i.GetTitle(), err)
+	}
+	return nil
+}
+
+// record reloads both sides and stores the synced state of the items.
+func (s *syncer) record(uids []string) error {
+	if _, err := s.local.load(); err != nil {
+		return err
+	}
+	if _, err := s.remote.load(); err != nil {
+		return err
+	}
+
+	for _, uid := range uids {
+		if s.skipped[uid] {
+			continue
+		}
+		l, r := s.local.items[uid], s.remote.items[uid]
+		e := s.state.entry(uid)
+
+		switch {
+		case l != nil && r != nil:
+			if e == nil {
+				e = &Entry{UID: uid}
+				s.state.Entries = append(s.state.Entries, e)
+			}
+			e.Type = typeOf(l)
+			e.Local = s.sideOf(l)
+			e.Remote = s.sideOf(r)
+			e.SyncedAt = s.now
+			e.DeletedAt = nil
+		case l == nil && r == nil:
+			if e != nil && !e.Deleted() {
+				now := s.now
+				e.DeletedAt = &now
+			}
+		}
+	}
+	return nil
+}
+
+func (s *syncer) sideOf(i items.ItemInterface) Side {
+	modifiedAt := i.GetUpdatedAt()
+	if modifiedAt.IsZero() {
+		modifiedAt = s.now
+	}
+	return Side{Id: i.GetId(), Hash: hashItem(i), ModifiedAt: modifiedAt.UTC()}
+}
+
+// hashItem hashes the synced fields of an item. IDs, UIDs, and the creation and update
+// times are left out, as each backend keeps them differently.
+func hashItem(i items.ItemInterface) string {
+	return hashFields(i, true)
+}
+
+// unchangedSince reports whether the item is as it was on a side after the last sync.
+// The hashes recorded before the completion time, status history and time log of
+// tasks were synced are taken as well.
+func unchangedSince(i items.ItemInterface, side Side) bool {
+	return side.Hash == hashItem(i) || side.Hash == hashFields(i, false)
+}
+
+// hashFields hashes the synced fields of an item, but for the completion time, status
+// history and time log of tasks unless all is set.
+func hashFields(i items.ItemInterface, all bool) string {
+	fields := []string{string(typeOf(i)), i.GetTitle(), strings.TrimSpace(i.GetBody()), tagName(i)}
+	if t, ok := i.(*items.Task); ok {
+		fields = append(fields, string(t.Status), string(t.Priority), items.FormatDate(t.DueDate),
+			items.FormatDate(t.ScheduledDate), t.Recurrence)
+		// Only when set, so the hashes of the items synced before estimates are the same
+		if !t.Estimate.IsZero() {
+			fields = append(fields, t.Estimate.String())
+		}
+		if all {
+			if !t.CompletedAt.IsZero() {
+				fields = append(fields, "completed "+items.FormatTimestamp(t.CompletedAt))
+			}
+			for _, c := range t.StatusHistory {
+				fields = append(fields, "status "+string(c.Status)+" "+items.FormatTimestamp(c.At))
+			}
+			for _, e := range t.TimeLog {
+				fields = append(fields, "time "+items.FormatTimestamp(e.Start)+" "+items.FormatTimestamp(e.End)+" "+strconv.FormatBool(e.Pomodoro))
+			}
+		}
+	}
+	sum := sha1.Sum([]byte(strings.Join(fields, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
+
+func typeOf(i items.ItemInterface) items.ItemType {
+	if _, ok := i.(*items.Task); ok {
+		return items.ItemTypeTask
+	}
+	return items.ItemTypeNote
+}
+
+func tagName(i items.ItemInterface) string {
+	if tag := i.GetTag(); tag != nil {
+		return tag.Name
+	}
+	return ""
+}
+
+func sameDay(a, b time.Time) bool {
+	return items.FormatDate(a.Local()) == items.FormatDate(b.Local())
+}
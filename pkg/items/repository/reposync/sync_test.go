@@ -0,0 +1,393 @@
+package reposync_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/markelca/prioritty/pkg/items"
+	"github.com/markelca/prioritty/pkg/items/repository"
+	"github.com/markelca/prioritty/pkg/items/repository/memory"
+	"github.com/markelca/prioritty/pkg/items/repository/reposync"
+)
+
+// syncer syncs two memory repositories with the same state.
+type syncer struct {
+	local, remote *memory.Repository
+	state         *reposync.State
+}
+
+func newSyncer() *syncer {
+	return &syncer{local: memory.NewRepository(), remote: memory.NewRepository(), state: &reposync.State{}}
+}
+
+func (s *syncer) sync(t *testing.T, policy reposync.Policy) reposync.Result {
+	t.Helper()
+	result, err := reposync.Sync(s.local, s.remote, s.state, reposync.Options{Policy: policy})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func createTask(t *testing.T, r repository.Repository, title string) items.Task {
+	t.Helper()
+	task := items.Task{Item: items.Item{Title: title}, Status: items.Todo}
+	if err := r.CreateTask(&task); err != nil {
+		t.Fatal(err)
+	}
+	return task
+}
+
+// task returns the only task of the repository with the title, failing if there's none.
+func task(t *testing.T, r repository.Repository, title string) items.Task {
+	t.Helper()
+	tasks, err := r.GetTasks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found []items.Task
+	for _, task := range tasks {
+		if task.Title == title {
+			found = append(found, task)
+		}
+	}
+	if len(found) != 1 {
+		t.Fatalf("found %d tasks %q, want 1", len(found), title)
+	}
+	return found[0]
+}
+
+func titles(t *testing.T, r repository.Repository) []string {
+	t.Helper()
+	tasks, err := r.GetTasks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	notes, err := r.GetNotes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var list []string
+	for _, task := range tasks {
+		list = append(list, task.Title)
+	}
+	for _, note := range notes {
+		list = append(list, note.Title)
+	}
+	return list
+}
+
+func checkTitles(t *testing.T, r repository.Repository, want ...string) {
+	t.Helper()
+	got := titles(t, r)
+	if len(got) != len(want) {
+		t.Fatalf("items = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("items = %q, want %q", got, want)
+		}
+	}
+}
+
+func setStatus(t *testing.T, r repository.Repository, title string, status items.Status) {
+	t.Helper()
+	if err := r.UpdateTaskStatus(task(t, r, title), status); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSyncCreates(t *testing.T) {
+	s := newSyncer()
+	createTask(t, s.local, "Write report")
+	if err := s.remote.CreateNote(&items.Note{Item: items.Item{Title: "Ideas"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	result := s.sync(t, reposync.PolicyNewest)
+	if len(result.Changes) != 2 {
+		t.Errorf("changes = %+v, want 2", result.Changes)
+	}
+	checkTitles(t, s.local, "Write report", "Ideas")
+	checkTitles(t, s.remote, "Write report", "Ideas")
+	if l, r := task(t, s.local, "Write report"), task(t, s.remote, "Write report"); l.UID == "" || l.UID != r.UID {
+		t.Errorf("UIDs = %q and %q, want the same one", l.UID, r.UID)
+	}
+
+	if result := s.sync(t, reposync.PolicyNewest); len(result.Changes) != 0 || len(result.Conflicts) != 0 {
+		t.Errorf("second sync = %+v, want nothing to do", result)
+	}
+}
+
+func TestSyncPairsItemsWithoutUID(t *testing.T) {
+	s := newSyncer()
+	createTask(t, s.local, "Call the plumber")
+	createTask(t, s.remote, "Call the plumber")
+
+	s.sync(t, reposync.PolicyNewest)
+	checkTitles(t, s.local, "Call the plumber")
+	checkTitles(t, s.remote, "Call the plumber")
+}
+
+func TestSyncPolicies(t *testing.T) {
+	tests := []struct {
+		policy     reposync.Policy
+		want       items.Status // on both sides, none if they keep their own
+		conflicted bool         // reported again on the next sync
+	}{
+		{policy: reposync.PolicyNewest, want: items.Done},
+		{policy: reposync.PolicyLocal, want: items.Cancelled},
+		{policy: reposync.PolicyRemote, want: items.Done},
+		{policy: reposync.PolicySkip, conflicted: true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			s := newSyncer()
+			createTask(t, s.local, "Pay rent")
+			s.sync(t, tt.policy)
+
+			// Edited on both sides, the remote side last
+			setStatus(t, s.local, "Pay rent", items.Cancelled)
+			time.Sleep(time.Millisecond)
+			setStatus(t, s.remote, "Pay rent", items.Done)
+
+			result := s.sync(t, tt.policy)
+			if len(result.Conflicts) != 1 {
+				t.Fatalf("conflicts = %+v, want 1", result.Conflicts)
+			}
+			local, remote := task(t, s.local, "Pay rent"), task(t, s.remote, "Pay rent")
+			if tt.want == "" {
+				if local.Status != items.Cancelled || remote.Status != items.Done {
+					t.Errorf("statuses = %s and %s, want them kept", local.Status, remote.Status)
+				}
+			} else if local.Status != tt.want || remote.Status != tt.want {
+				t.Errorf("statuses = %s and %s, want %s", local.Status, remote.Status, tt.want)
+			}
+
+			result = s.sync(t, tt.policy)
+			if got := len(result.Conflicts) > 0; got != tt.conflicted {
+				t.Errorf("conflict reported on the next sync = %v, want %v", got, tt.conflicted)
+			}
+		})
+	}
+}
+
+func TestSyncOneSidedChanges(t *testing.T) {
+	s := newSyncer()
+	createTask(t, s.local, "Book the room")
+	s.sync(t, reposync.PolicyNewest)
+
+	setStatus(t, s.remote, "Book the room", items.InProgress)
+	result := s.sync(t, reposync.PolicyNewest)
+	if len(result.Conflicts) != 0 {
+		t.Errorf("conflicts = %+v, want none", result.Conflicts)
+	}
+	if got := task(t, s.local, "Book the room").Status; got != items.InProgress {
+		t.Errorf("local status = %s, want %s", got, items.InProgress)
+	}
+}
+
+func TestSyncDeletes(t *testing.T) {
+	s := newSyncer()
+	old := createTask(t, s.local, "Old")
+	createTask(t, s.local, "Kept")
+	s.sync(t, reposync.PolicyNewest)
+
+	// Removed locally, it's removed remotely and remembered
+	if err := s.local.RemoveTask(old.Id); err != nil {
+		t.Fatal(err)
+	}
+	s.sync(t, reposync.PolicyNewest)
+	checkTitles(t, s.remote, "Kept")
+	uid := tombstoneUID(t, s.state)
+
+	// Brought back unchanged, e.g. from an old backup, it stays deleted
+	back := items.Task{Item: items.Item{Title: "Old", UID: uid, CreatedAt: old.CreatedAt}, Status: items.Todo}
+	if err := s.remote.CreateTask(&back); err != nil {
+		t.Fatal(err)
+	}
+	s.sync(t, reposync.PolicyNewest)
+	checkTitles(t, s.local, "Kept")
+	checkTitles(t, s.remote, "Kept")
+}
+
+func TestSyncEditWinsOverDelete(t *testing.T) {
+	s := newSyncer()
+	createTask(t, s.local, "Renew passport")
+	s.sync(t, reposync.PolicyNewest)
+
+	if err := s.local.RemoveTask(task(t, s.local, "Renew passport").Id); err != nil {
+		t.Fatal(err)
+	}
+	setStatus(t, s.remote, "Renew passport", items.InProgress)
+
+	result := s.sync(t, reposync.PolicyNewest)
+	if len(result.Conflicts) != 1 {
+		t.Errorf("conflicts = %+v, want 1", result.Conflicts)
+	}
+	if got := task(t, s.local, "Renew passport").Status; got != items.InProgress {
+		t.Errorf("restored status = %s, want %s", got, items.InProgress)
+	}
+}
+
+func TestSyncDryRun(t *testing.T) {
+	s := newSyncer()
+	createTask(t, s.local, "Draft")
+	result, err := reposync.Sync(s.local, s.remote, s.state, reposync.Options{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Changes) != 1 {
+		t.Errorf("changes = %+v, want 1", result.Changes)
+	}
+	checkTitles(t, s.remote)
+	if len(s.state.Entries) != 0 {
+		t.Errorf("state = %+v, want it untouched", s.state.Entries)
+	}
+}
+
+func TestSyncPrunesTombstones(t *testing.T) {
+	s := newSyncer()
+	gone := createTask(t, s.local, "Gone")
+	s.sync(t, reposync.PolicyNewest)
+	if err := s.local.RemoveTask(gone.Id); err != nil {
+		t.Fatal(err)
+	}
+	s.sync(t, reposync.PolicyNewest)
+
+	longAgo := time.Now().AddDate(-1, 0, 0)
+	s.state.Entries[0].DeletedAt = &longAgo
+	s.sync(t, reposync.PolicyNewest)
+	if len(s.state.Entries) != 0 {
+		t.Errorf("state = %+v, want the old tombstone dropped", s.state.Entries)
+	}
+}
+
+// tombstoneUID returns the UID of the only deleted item of the state.
+func tombstoneUID(t *testing.T, state *reposync.State) string {
+	t.Helper()
+	for _, e := range state.Entries {
+		if e.Deleted() {
+			return e.UID
+		}
+	}
+	t.Fatal("no tombstone")
+	return ""
+}
+
+func TestSyncWithStatePair(t *testing.T) {
+	dir := t.TempDir()
+	pair := reposync.Pair{
+		Local:  reposync.Location{Type: "sqlite", Path: "/data/prioritty.db"},
+		Remote: reposync.Location{Type: "obsidian", Path: "/data/vault"},
+	}
+	other := pair
+	other.Remote.Path = "/data/other-vault"
+	if reposync.DefaultStatePath(dir, pair) == reposync.DefaultStatePath(dir, other) {
+		t.Fatal("two pairs share the default state path")
+	}
+
+	statePath := filepath.Join(dir, "state.json")
+	local, remote := memory.NewRepository(), memory.NewRepository()
+	createTask(t, local, "Water the plants")
+	if _, err := reposync.SyncWithState(statePath, pair, local, remote, reposync.Options{}); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := os.ReadFile(statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Syncing another pair with it would take every item for a deletion or a new one
+	empty := memory.NewRepository()
+	_, err = reposync.SyncWithState(statePath, other, local, empty, reposync.Options{})
+	if !errors.Is(err, reposync.ErrStateMismatch) {
+		t.Fatalf("error = %v, want ErrStateMismatch", err)
+	}
+	checkTitles(t, local, "Water the plants")
+	checkTitles(t, empty)
+	if after, err := os.ReadFile(statePath); err != nil || string(after) != string(saved) {
+		t.Errorf("state file changed after the refused sync (%v)", err)
+	}
+
+	state, err := reposync.LoadState(statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Pair != pair {
+		t.Errorf("state pair = %+v, want %+v", state.Pair, pair)
+	}
+}
+
+// noTimeLog is a repository that can't keep time logs, like todo.txt.
+type noTimeLog struct {
+	*memory.Repository
+}
+
+func (r noTimeLog) CreateTask(t *items.Task) error {
+	if len(t.TimeLog) > 0 {
+		return repository.ErrNoTimeLog
+	}
+	return r.Repository.CreateTask(t)
+}
+
+func (r noTimeLog) UpdateTask(t items.Task) error {
+	if len(t.TimeLog) > 0 {
+		return repository.ErrNoTimeLog
+	}
+	return r.Repository.UpdateTask(t)
+}
+
+func logTime(t *testing.T, r repository.Repository, title string) {
+	t.Helper()
+	task := task(t, r, title)
+	start := time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC)
+	task.TimeLog = append(task.TimeLog, items.TimeEntry{Start: start, End: start.Add(time.Hour)})
+	if err := r.UpdateTask(task); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSyncTimeLog(t *testing.T) {
+	s := newSyncer()
+	createTask(t, s.local, "Write report")
+	s.sync(t, reposync.PolicyNewest)
+
+	logTime(t, s.local, "Write report")
+	if result := s.sync(t, reposync.PolicyNewest); len(result.Changes) != 1 {
+		t.Errorf("changes = %+v, want the remote task updated", result.Changes)
+	}
+	if got := task(t, s.remote, "Write report").TimeLog; len(got) != 1 {
+		t.Errorf("remote time log = %+v, want the entry logged locally", got)
+	}
+}
+
+func TestSyncWithoutTimeLog(t *testing.T) {
+	local, remote, state := memory.NewRepository(), noTimeLog{memory.NewRepository()}, &reposync.State{}
+	sync := func() reposync.Result {
+		t.Helper()
+		result, err := reposync.Sync(local, remote, state, reposync.Options{Policy: reposync.PolicySkip})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+	createTask(t, local, "Write report")
+	logTime(t, local, "Write report")
+	sync()
+	checkTitles(t, remote, "Write report")
+
+	// The time log the remote side can't keep isn't taken for a change
+	if result := sync(); len(result.Changes) != 0 || len(result.Conflicts) != 0 {
+		t.Errorf("second sync = %+v, want nothing to do", result)
+	}
+
+	setStatus(t, remote, "Write report", items.Done)
+	sync()
+	if got := task(t, local, "Write report"); got.Status != items.Done || len(got.TimeLog) != 1 {
+		t.Errorf("local task = %+v, want it done with its time log", got)
+	}
+}
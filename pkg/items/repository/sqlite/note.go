@@ -5,14 +5,60 @@ import (
 	"fmt"
 	"log"
 	"strconv"
-	"time"
 
 	"github.com/markelca/prioritty/pkg/items"
 )
 
+// noteColumns are the columns read by scanNote, joined with the tag table.
+const noteColumns = `n.id, n.uid, n.title, n.body, n.created_at, COALESCE(n.updated_at, n.created_at), tag.id, tag.name`
+
+// scanNote reads a note row selected with noteColumns.
+func scanNote(rows *sql.Rows) (items.Note, error) {
+	var note items.Note
+	var uid sql.NullString
+	var body *string
+	var noteId int
+	var tagId sql.NullInt64
+	var tagName sql.NullString
+	var createdAtStr string
+	var updatedAtStr string
+
+	err := rows.Scan(&noteId, &uid, &note.Title, &body, &createdAtStr, &updatedAtStr, &tagId, &tagName)
+	if err != nil {
+		return note, err
+	}
+	note.Id = strconv.Itoa(noteId)
+	note.UID = uid.String
+
+	note.CreatedAt, err = parseTimestamp(createdAtStr)
+	if err != nil {
+		return note, fmt.Errorf("error parsing created_at string: %w", err)
+	}
+	note.UpdatedAt, err = parseTimestamp(updatedAtStr)
+	if err != nil {
+		return note, fmt.Errorf("error parsing updated_at string: %w", err)
+	}
+
+	if body != nil {
+		note.Body = *body
+	}
+
+	if tagId.Valid {
+		tag := items.Tag{
+			Id:   strconv.FormatInt(tagId.Int64, 10),
+			Name: tagName.String,
+		}
+		note.Tag = &tag
+	} else {
+		note.Tag = nil
+	}
+
+	return note, nil
+}
+
 func (r *SQLiteRepository) GetNotes() ([]items.Note, error) {
 	query := `
-		SELECT n.id, n.title, n.body, n.created_at, tag.id, tag.name
+		SELECT ` + noteColumns + `
 		FROM note n
 			LEFT JOIN tag on n.tag_id = tag.id
 	`
@@ -27,40 +73,11 @@ func (r *SQLiteRepository) GetNotes() ([]items.Note, error) {
 	var notes []items.Note
 
 	for rows.Next() {
-		var note items.Note
-		var body *string
-		var noteId int
-		var tagId sql.NullInt64
-		var tagName sql.NullString
-		var createdAtStr string
-
-		err := rows.Scan(&noteId, &note.Title, &body, &createdAtStr, &tagId, &tagName)
+		note, err := scanNote(rows)
 		if err != nil {
 			log.Printf("Error scanning note: %v", err)
 			continue
 		}
-		note.Id = strconv.Itoa(noteId)
-
-		note.CreatedAt, err = time.Parse("2006-01-02 15:04:05", createdAtStr)
-		if err != nil {
-			log.Printf("Error parsing created_at string: %v", err)
-			continue
-		}
-
-		if body != nil {
-			note.Body = *body
-		}
-
-		if tagId.Valid {
-			tag := items.Tag{
-				Id:   strconv.FormatInt(tagId.Int64, 10),
-				Name: tagName.String,
-			}
-			note.Tag = &tag
-		} else {
-			note.Tag = nil
-		}
-
 		notes = append(notes, note)
 	}
 
@@ -70,19 +87,18 @@ func (r *SQLiteRepository) GetNotes() ([]items.Note, error) {
 func (r *SQLiteRepository) UpdateNote(n items.Note) error {
 	query := `
 		UPDATE note
-		SET title = ?, body = ? 
+		SET uid = ?, title = ?, body = ?, updated_at = CURRENT_TIMESTAMP
 		WHERE id = ?
 	`
-	_, err := r.db.Exec(query, n.Title, n.Body, n.Id)
-	return err
+	return r.execRow(query, nullString(n.UID), n.Title, n.Body, n.Id)
 }
 
 func (r *SQLiteRepository) CreateNote(n *items.Note) error {
 	query := `
-		INSERT INTO note (title, body)
-		VALUES (?, ?)
+		INSERT INTO note (uid, title, body, created_at)
+		VALUES (?, ?, ?, COALESCE(?, CURRENT_TIMESTAMP))
 	`
-	result, err := r.db.Exec(query, n.Title, n.Body)
+	result, err := r.db.Exec(query, nullString(n.UID), n.Title, n.Body, formatTimestamp(n.CreatedAt))
 	if err != nil {
 		return err
 	}
@@ -99,17 +115,16 @@ func (r *SQLiteRepository) RemoveNote(id string) error {
 		DELETE FROM note
 		WHERE id = ?
 	`
-	_, err := r.db.Exec(query, id)
-	return err
+	return r.execRow(query, id)
 }
 
 func (r *SQLiteRepository) SetNoteTag(n items.Note, tag items.Tag) error {
 	query := `
 		UPDATE note
-		SET tag_id = ?
+		SET tag_id = ?, updated_at = CURRENT_TIMESTAMP
 		WHERE id = ?
 	`
-	_, err := r.db.Exec(query, tag.Id, n.Id)
+	err := r.execRow(query, tag.Id, n.Id)
 	if err != nil {
 		log.Printf("Error setting tag to note: %v", err)
 		return err
@@ -120,10 +135,10 @@ func (r *SQLiteRepository) SetNoteTag(n items.Note, tag items.Tag) error {
 func (r *SQLiteRepository) UnsetNoteTag(n items.Note) error {
 	query := `
 		UPDATE note
-		SET tag_id = NULL
+		SET tag_id = NULL, updated_at = CURRENT_TIMESTAMP
 		WHERE id = ?
 	`
-	_, err := r.db.Exec(query, n.Id)
+	err := r.execRow(query, n.Id)
 	if err != nil {
 		log.Printf("Error unsetting tag from note: %v", err)
 		return err
@@ -15,13 +15,141 @@ import (
 type SQLiteRepository struct {
 	db       *sql.DB
 	filepath string
+	expected items.ItemInterface // checked before changes, see Expecting
 }
 
 func NewSQLiteRepository(db *sql.DB, filepath string) *SQLiteRepository {
 	return &SQLiteRepository{db: db, filepath: filepath}
 }
 
+// Expecting returns a view of the repository whose changes fail with
+// repository.ErrConflict if the item expected isn't stored as it is.
+func (r *SQLiteRepository) Expecting(expected items.ItemInterface) repository.Repository {
+	v := *r
+	v.expected = expected
+	return &v
+}
+
+// checkExpected returns repository.ErrConflict if the item expected by an Expecting
+// view isn't stored as it is. The item is read in tx, which takes the write lock
+// upfront, so no other process can change it before tx commits.
+func (r *SQLiteRepository) checkExpected(tx *sql.Tx) error {
+	if r.expected == nil {
+		return nil
+	}
+	var tasks []items.Task
+	var notes []items.Note
+	switch r.expected.(type) {
+	case *items.Task, items.Task:
+		rows, err := tx.Query(`SELECT `+taskColumns+` FROM task t LEFT JOIN tag ON t.tag_id = tag.id WHERE t.id = ?`, r.expected.GetId())
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			task, err := scanTask(rows)
+			if err != nil {
+				return err
+			}
+			tasks = append(tasks, task)
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+	default:
+		rows, err := tx.Query(`SELECT `+noteColumns+` FROM note n LEFT JOIN tag ON n.tag_id = tag.id WHERE n.id = ?`, r.expected.GetId())
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			note, err := scanNote(rows)
+			if err != nil {
+				return err
+			}
+			notes = append(notes, note)
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+	}
+	return repository.CheckExpected(r.expected, tasks, notes)
+}
+
+// statusIds maps task statuses to the ids of the status table.
+var statusIds = map[items.Status]int{
+	items.Todo:       0,
+	items.InProgress: 1,
+	items.Done:       2,
+	items.Cancelled:  3,
+}
+
+func statusToId(s items.Status) int {
+	return statusIds[s]
+}
+
+func statusFromId(id int) items.Status {
+	for status, statusId := range statusIds {
+		if statusId == id {
+			return status
+		}
+	}
+	return items.Todo
+}
+
+// timestampLayout is the format of the timestamp columns, as written by CURRENT_TIMESTAMP, in UTC.
+const timestampLayout = "2006-01-02 15:04:05"
+
+// formatTimestamp stores a time in UTC, or NULL for the zero time (e.g. to let
+// created_at default to the current time).
+func formatTimestamp(t time.Time) sql.NullString {
+	if t.IsZero() {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: t.UTC().Format(timestampLayout), Valid: true}
+}
+
+// parseTimestamp reads a timestamp column. Besides timestampLayout it accepts the
+// formats written by other tools or drivers (e.g. RFC 3339), times without zone being UTC.
+func parseTimestamp(s string) (time.Time, error) {
+	return items.ParseTimestamp(s, time.UTC)
+}
+
+// execRow runs a statement that targets a single item, in a transaction checking
+// the item expected by an Expecting view first. It returns repository.ErrNotFound if
+// no row was affected.
+func (r *SQLiteRepository) execRow(query string, args ...any) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := r.checkExpected(tx); err != nil {
+		return err
+	}
+	result, err := tx.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return tx.Commit()
+}
+
+// Reset closes the database and removes it along with its WAL files.
 func (r *SQLiteRepository) Reset() error {
+	r.db.Close()
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Remove(r.filepath + suffix); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
 	return os.Remove(r.filepath)
 }
 
@@ -95,7 +223,7 @@ func (r *SQLiteRepository) CreateTag(name string) (*items.Tag, error) {
 
 	id, err := result.LastInsertId()
 	if err != nil {
-		log.Println("Error getting last inserted tag id: %v", err)
+		log.Printf("Error getting last inserted tag id: %v", err)
 		return nil, err
 	}
 
@@ -130,12 +258,13 @@ func (r *SQLiteRepository) RemoveTag(name string) error {
 
 func (r *SQLiteRepository) GetItemsWithTag(tagName string) ([]items.ItemInterface, error) {
 	var allItems []items.ItemInterface
+	var tasks []*items.Task
 
 	tasksQuery := `
-		SELECT t.id, t.title, t.body, t.status_id, t.created_at, tg.id, tg.name
+		SELECT ` + taskColumns + `
 		FROM task t
-		JOIN tag tg ON t.tag_id = tg.id
-		WHERE tg.name = ?
+		JOIN tag ON t.tag_id = tag.id
+		WHERE tag.name = ?
 	`
 	rows, err := r.db.Query(tasksQuery, tagName)
 	if err != nil {
@@ -145,46 +274,29 @@ func (r *SQLiteRepository) GetItemsWithTag(tagName string) ([]items.ItemInterfac
 	defer rows.Close()
 
 	for rows.Next() {
-		var task items.Task
-		var body *string
-		var statusId int
-		var createdAtStr string
-		var taskId int
-		var tagId int
-		var tagName string
-
-		err := rows.Scan(&taskId, &task.Title, &body, &statusId, &createdAtStr, &tagId, &tagName)
+		task, err := scanTask(rows)
 		if err != nil {
 			log.Printf("Error scanning task: %v", err)
 			return nil, err
 		}
-		task.Id = strconv.Itoa(taskId)
-
-		task.CreatedAt, err = time.Parse("2006-01-02 15:04:05", createdAtStr)
-		if err != nil {
-			log.Printf("Error parsing created_at string: %v", err)
-			return nil, err
-		}
 
-		if body != nil {
-			task.Body = *body
-		}
-
-		tag := items.Tag{
-			Id:   strconv.Itoa(tagId),
-			Name: tagName,
-		}
-		task.Tag = &tag
-		task.Status = items.Status(statusId)
-
-		allItems = append(allItems, &task)
+		tasks = append(tasks, &task)
+	}
+	if err := r.loadStatusHistory(tasks); err != nil {
+		return nil, err
+	}
+	if err := r.loadTimeLog(tasks); err != nil {
+		return nil, err
+	}
+	for _, t := range tasks {
+		allItems = append(allItems, t)
 	}
 
 	notesQuery := `
-		SELECT n.id, n.title, n.body, n.created_at, tg.id, tg.name
+		SELECT ` + noteColumns + `
 		FROM note n
-		JOIN tag tg ON n.tag_id = tg.id
-		WHERE tg.name = ?
+		JOIN tag ON n.tag_id = tag.id
+		WHERE tag.name = ?
 	`
 	rows, err = r.db.Query(notesQuery, tagName)
 	if err != nil {
@@ -194,35 +306,11 @@ func (r *SQLiteRepository) GetItemsWithTag(tagName string) ([]items.ItemInterfac
 	defer rows.Close()
 
 	for rows.Next() {
-		var note items.Note
-		var body *string
-		var createdAtStr string
-		var noteId int
-		var tagId int
-		var tagName string
-
-		err := rows.Scan(&noteId, &note.Title, &body, &createdAtStr, &tagId, &tagName)
+		note, err := scanNote(rows)
 		if err != nil {
 			log.Printf("Error scanning note: %v", err)
 			return nil, err
 		}
-		note.Id = strconv.Itoa(noteId)
-
-		note.CreatedAt, err = time.Parse("2006-01-02 15:04:05", createdAtStr)
-		if err != nil {
-			log.Printf("Error parsing created_at string: %v", err)
-			return nil, err
-		}
-
-		if body != nil {
-			note.Body = *body
-		}
-
-		tag := items.Tag{
-			Id:   strconv.Itoa(tagId),
-			Name: tagName,
-		}
-		note.Tag = &tag
 
 		allItems = append(allItems, &note)
 	}
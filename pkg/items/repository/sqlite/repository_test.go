@@ -0,0 +1,20 @@
+package sqlite_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/markelca/prioritty/internal/migrations/sqlite"
+	"github.com/markelca/prioritty/pkg/items/repository"
+	"github.com/markelca/prioritty/pkg/items/repository/repositorytest"
+)
+
+func TestRepository(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repository.Repository {
+		r, err := sqlite.NewSQLiteRepository(filepath.Join(t.TempDir(), "prioritty.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	})
+}
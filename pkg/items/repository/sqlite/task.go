@@ -2,17 +2,109 @@ package sqlite
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"strconv"
 	"time"
 
 	"github.com/markelca/prioritty/pkg/items"
+	"github.com/markelca/prioritty/pkg/items/repository"
 )
 
+// taskColumns are the columns read by scanTask, joined with the tag table.
+const taskColumns = `t.id, t.uid, t.title, t.body, t.status_id, t.created_at, t.priority, t.due_date, t.scheduled_date, t.recurrence, t.estimate, COALESCE(t.updated_at, t.created_at), t.completed_at, tag.id, tag.name`
+
+// scanTask reads a task row selected with taskColumns.
+func scanTask(rows *sql.Rows) (items.Task, error) {
+	var task items.Task
+	var uid sql.NullString
+	var body *string
+	var taskId int
+	var statusId int
+	var tagId sql.NullInt64
+	var tagName sql.NullString
+	var createdAtStr string
+	var priority string
+	var dueDate sql.NullString
+	var scheduledDate sql.NullString
+	var updatedAtStr string
+	var completedAt sql.NullString
+	var estimate string
+
+	err := rows.Scan(&taskId, &uid, &task.Title, &body, &statusId, &createdAtStr, &priority, &dueDate, &scheduledDate, &task.Recurrence, &estimate, &updatedAtStr, &completedAt, &tagId, &tagName)
+	if err != nil {
+		return task, err
+	}
+	task.Id = strconv.Itoa(taskId)
+	task.UID = uid.String
+
+	task.CreatedAt, err = parseTimestamp(createdAtStr)
+	if err != nil {
+		return task, fmt.Errorf("error parsing created_at string: %w", err)
+	}
+	task.UpdatedAt, err = parseTimestamp(updatedAtStr)
+	if err != nil {
+		return task, fmt.Errorf("error parsing updated_at string: %w", err)
+	}
+	task.CompletedAt, err = parseTimestamp(completedAt.String)
+	if err != nil {
+		return task, fmt.Errorf("error parsing completed_at string: %w", err)
+	}
+
+	if body != nil {
+		task.Body = *body
+	}
+
+	if tagId.Valid {
+		tag := items.Tag{
+			Id:   strconv.FormatInt(tagId.Int64, 10),
+			Name: tagName.String,
+		}
+		task.Tag = &tag
+	} else {
+		task.Tag = nil
+	}
+
+	task.Status = statusFromId(statusId)
+	task.Priority = items.ParsePriority(priority)
+	task.DueDate = parseDate(dueDate)
+	task.ScheduledDate = parseDate(scheduledDate)
+	if task.Estimate, err = items.ParseEstimate(estimate); err != nil {
+		log.Printf("Warning: task %s: %v", task.Id, err)
+	}
+
+	return task, nil
+}
+
+// nullString stores empty optional text fields as NULL.
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// formatDate stores date-only fields as YYYY-MM-DD, or NULL if unset.
+func formatDate(t time.Time) sql.NullString {
+	if t.IsZero() {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: items.FormatDate(t), Valid: true}
+}
+
+func parseDate(s sql.NullString) time.Time {
+	if !s.Valid {
+		return time.Time{}
+	}
+	d, err := items.ParseDate(s.String)
+	if err != nil {
+		log.Printf("Error parsing date %q: %v", s.String, err)
+		return time.Time{}
+	}
+	return d
+}
+
 func (r *SQLiteRepository) GetTasks() ([]items.Task, error) {
 	query := `
-		SELECT t.id, t.title, t.body, t.status_id, t.created_at, tag.id, tag.name
+		SELECT ` + taskColumns + `
 		FROM task t
 			LEFT JOIN tag on t.tag_id = tag.id
 	`
@@ -27,75 +119,230 @@ func (r *SQLiteRepository) GetTasks() ([]items.Task, error) {
 	var tasks []items.Task
 
 	for rows.Next() {
-		var task items.Task
-		var body *string
-		var taskId int
-		var statusId int
-		var tagId sql.NullInt64
-		var tagName sql.NullString
-		var createdAtStr string
-
-		err := rows.Scan(&taskId, &task.Title, &body, &statusId, &createdAtStr, &tagId, &tagName)
+		task, err := scanTask(rows)
 		if err != nil {
 			log.Printf("Error scanning task: %v", err)
 			continue
 		}
-		task.Id = strconv.Itoa(taskId)
+		tasks = append(tasks, task)
+	}
 
-		task.CreatedAt, err = time.Parse("2006-01-02 15:04:05", createdAtStr)
+	ptrs := make([]*items.Task, len(tasks))
+	for i := range tasks {
+		ptrs[i] = &tasks[i]
+	}
+	if err := r.loadStatusHistory(ptrs); err != nil {
+		return tasks, err
+	}
+	if err := r.loadTimeLog(ptrs); err != nil {
+		return tasks, err
+	}
+	return tasks, nil
+}
+
+// loadStatusHistory sets the status history of the tasks.
+func (r *SQLiteRepository) loadStatusHistory(tasks []*items.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	rows, err := r.db.Query(`
+		SELECT task_id, status_id, entered_at
+		FROM task_status_history
+		ORDER BY task_id, entered_at, id
+	`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	history := make(map[string][]items.StatusChange)
+	for rows.Next() {
+		var taskId, statusId int
+		var enteredAt string
+		if err := rows.Scan(&taskId, &statusId, &enteredAt); err != nil {
+			return err
+		}
+		at, err := parseTimestamp(enteredAt)
 		if err != nil {
-			log.Printf("Error parsing created_at string: %v", err)
+			log.Printf("Error parsing entered_at %q: %v", enteredAt, err)
 			continue
 		}
+		id := strconv.Itoa(taskId)
+		history[id] = append(history[id], items.StatusChange{Status: statusFromId(statusId), At: at})
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
 
-		if body != nil {
-			task.Body = *body
+	for _, t := range tasks {
+		t.StatusHistory = history[t.Id]
+	}
+	return nil
+}
+
+// loadTimeLog sets the time entries of the tasks.
+func (r *SQLiteRepository) loadTimeLog(tasks []*items.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	rows, err := r.db.Query(`
+		SELECT task_id, started_at, ended_at, pomodoro
+		FROM task_time_entry
+		ORDER BY task_id, started_at, id
+	`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	entries := make(map[string][]items.TimeEntry)
+	for rows.Next() {
+		var taskId int
+		var startedAt string
+		var endedAt sql.NullString
+		var pomodoro bool
+		if err := rows.Scan(&taskId, &startedAt, &endedAt, &pomodoro); err != nil {
+			return err
+		}
+		start, err := parseTimestamp(startedAt)
+		if err != nil {
+			log.Printf("Error parsing started_at %q: %v", startedAt, err)
+			continue
 		}
+		end, err := parseTimestamp(endedAt.String)
+		if err != nil {
+			log.Printf("Error parsing ended_at %q: %v", endedAt.String, err)
+			continue
+		}
+		id := strconv.Itoa(taskId)
+		entries[id] = append(entries[id], items.TimeEntry{Start: start, End: end, Pomodoro: pomodoro})
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, t := range tasks {
+		t.TimeLog = entries[t.Id]
+	}
+	return nil
+}
 
-		if tagId.Valid {
-			tag := items.Tag{
-				Id:   strconv.FormatInt(tagId.Int64, 10),
-				Name: tagName.String,
-			}
-			task.Tag = &tag
-		} else {
-			task.Tag = nil
+// replaceTimeLog replaces the time entries of a task.
+func replaceTimeLog(tx *sql.Tx, taskId any, entries []items.TimeEntry) error {
+	if _, err := tx.Exec(`DELETE FROM task_time_entry WHERE task_id = ?`, taskId); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		_, err := tx.Exec(`
+			INSERT INTO task_time_entry (task_id, started_at, ended_at, pomodoro)
+			VALUES (?, ?, ?, ?)
+		`, taskId, formatTimestamp(e.Start), formatTimestamp(e.End), e.Pomodoro)
+		if err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
-		task.Status = items.Status(statusId)
+// insertStatusChange adds an entry to the status history of a task.
+func insertStatusChange(tx *sql.Tx, taskId any, c items.StatusChange) error {
+	_, err := tx.Exec(`
+		INSERT INTO task_status_history (task_id, status_id, entered_at)
+		VALUES (?, ?, ?)
+	`, taskId, statusToId(c.Status), formatTimestamp(c.At))
+	return err
+}
 
-		tasks = append(tasks, task)
+// updateStatus runs fn, which updates the task, in a transaction with the task's
+// current status and completion time, and records the status change if any. The
+// item expected by an Expecting view is checked first.
+func (r *SQLiteRepository) updateStatus(id string, fn func(tx *sql.Tx, stored items.Task) (items.Task, error)) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
 	}
+	defer tx.Rollback()
 
-	return tasks, nil
+	if err := r.checkExpected(tx); err != nil {
+		return err
+	}
+
+	var stored items.Task
+	var statusId int
+	var completedAt sql.NullString
+	err = tx.QueryRow(`SELECT status_id, completed_at FROM task WHERE id = ?`, id).Scan(&statusId, &completedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repository.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	stored.Status = statusFromId(statusId)
+	if stored.CompletedAt, err = parseTimestamp(completedAt.String); err != nil {
+		return fmt.Errorf("error parsing completed_at string: %w", err)
+	}
+
+	updated, err := fn(tx, stored)
+	if err != nil {
+		return err
+	}
+	if updated.Status != stored.Status {
+		if err := insertStatusChange(tx, id, updated.StatusHistory[len(updated.StatusHistory)-1]); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
 }
 
 func (r *SQLiteRepository) UpdateTask(t items.Task) error {
-	query := `
-		UPDATE task
-		SET title = ?, body = ?, status_id = ?
-		WHERE id = ?
-	`
-	_, err := r.db.Exec(query, t.Title, t.Body, t.Status, t.Id)
-	return err
+	return r.updateStatus(t.Id, func(tx *sql.Tx, stored items.Task) (items.Task, error) {
+		t = t.ReplacingStatusOf(stored, time.Now())
+		query := `
+			UPDATE task
+			SET uid = ?, title = ?, body = ?, status_id = ?, priority = ?, due_date = ?, scheduled_date = ?, recurrence = ?,
+				estimate = ?, completed_at = ?, updated_at = CURRENT_TIMESTAMP
+			WHERE id = ?
+		`
+		_, err := tx.Exec(query, nullString(t.UID), t.Title, t.Body, statusToId(t.Status), t.Priority,
+			formatDate(t.DueDate), formatDate(t.ScheduledDate), t.Recurrence, t.Estimate.String(), formatTimestamp(t.CompletedAt), t.Id)
+		if err != nil {
+			return t, err
+		}
+		return t, replaceTimeLog(tx, t.Id, t.TimeLog)
+	})
 }
 
 func (r *SQLiteRepository) UpdateTaskStatus(t items.Task, s items.Status) error {
-	query := `
-		UPDATE task
-		SET status_id = ?
-		WHERE id = ?
-	`
-	r.db.Exec(query, s, t.Id)
-	return nil
+	return r.updateStatus(t.Id, func(tx *sql.Tx, stored items.Task) (items.Task, error) {
+		stored.ChangeStatus(s, time.Now())
+		query := `
+			UPDATE task
+			SET status_id = ?, completed_at = ?, updated_at = CURRENT_TIMESTAMP
+			WHERE id = ?
+		`
+		_, err := tx.Exec(query, statusToId(stored.Status), formatTimestamp(stored.CompletedAt), t.Id)
+		return stored, err
+	})
 }
 
 func (r *SQLiteRepository) CreateTask(t *items.Task) error {
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now()
+	}
+	t.InitStatusHistory()
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `
-		INSERT INTO task (title, body, status_id)
-		VALUES (?, ?, ?)
+		INSERT INTO task (uid, title, body, status_id, priority, due_date, scheduled_date, recurrence, estimate, created_at, completed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
-	result, err := r.db.Exec(query, t.Title, t.Body, t.Status)
+	result, err := tx.Exec(query, nullString(t.UID), t.Title, t.Body, statusToId(t.Status), t.Priority,
+		formatDate(t.DueDate), formatDate(t.ScheduledDate), t.Recurrence, t.Estimate.String(), formatTimestamp(t.CreatedAt), formatTimestamp(t.CompletedAt))
 	if err != nil {
 		return err
 	}
@@ -103,26 +350,58 @@ func (r *SQLiteRepository) CreateTask(t *items.Task) error {
 	if err != nil {
 		return err
 	}
+	for _, c := range t.StatusHistory {
+		if err := insertStatusChange(tx, id, c); err != nil {
+			return err
+		}
+	}
+	if err := replaceTimeLog(tx, id, t.TimeLog); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
 	t.Id = fmt.Sprintf("%d", id)
 	return nil
 }
 
 func (r *SQLiteRepository) RemoveTask(id string) error {
-	query := `
-		DELETE FROM task
-		WHERE id = ?
-	`
-	_, err := r.db.Exec(query, id)
-	return err
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := r.checkExpected(tx); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM task_status_history WHERE task_id = ?`, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM task_time_entry WHERE task_id = ?`, id); err != nil {
+		return err
+	}
+	result, err := tx.Exec(`DELETE FROM task WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return tx.Commit()
 }
 
 func (r *SQLiteRepository) SetTaskTag(t items.Task, tag items.Tag) error {
 	query := `
 		UPDATE task
-		SET tag_id = ?
+		SET tag_id = ?, updated_at = CURRENT_TIMESTAMP
 		WHERE id = ?
 	`
-	_, err := r.db.Exec(query, tag.Id, t.Id)
+	err := r.execRow(query, tag.Id, t.Id)
 	if err != nil {
 		log.Printf("Error setting tag to task: %v", err)
 		return err
@@ -133,10 +412,10 @@ func (r *SQLiteRepository) SetTaskTag(t items.Task, tag items.Tag) error {
 func (r *SQLiteRepository) UnsetTaskTag(t items.Task) error {
 	query := `
 		UPDATE task
-		SET tag_id = NULL
+		SET tag_id = NULL, updated_at = CURRENT_TIMESTAMP
 		WHERE id = ?
 	`
-	_, err := r.db.Exec(query, t.Id)
+	err := r.execRow(query, t.Id)
 	if err != nil {
 		log.Printf("Error unsetting tag from task: %v", err)
 		return err
@@ -0,0 +1,208 @@
+package todotxt
+
+import (
+	"strings"
+	"time"
+
+	"github.com/markelca/prioritty/pkg/items"
+)
+
+// Line is a task line of a todo.txt file, e.g.
+// "x 2024-03-02 2024-03-01 Call mom +family @phone due:2024-03-05".
+// Words keeps the description as written, so unknown tags survive a rewrite.
+type Line struct {
+	Done      bool
+	Priority  byte   // 'A' to 'Z', 0 if unset
+	Completed string // completion date, only set on done tasks
+	Created   string // creation date
+	Words     []string
+}
+
+func isDate(s string) bool {
+	_, err := time.Parse(items.DateLayout, s)
+	return err == nil
+}
+
+func isPriority(s string) bool {
+	return len(s) == 3 && s[0] == '(' && s[1] >= 'A' && s[1] <= 'Z' && s[2] == ')'
+}
+
+// ParseLine parses a todo.txt task line.
+func ParseLine(s string) Line {
+	var l Line
+	words := strings.Fields(s)
+
+	if len(words) > 0 && words[0] == "x" {
+		l.Done = true
+		words = words[1:]
+	}
+	if len(words) > 0 && isPriority(words[0]) {
+		l.Priority = words[0][1]
+		words = words[1:]
+	}
+	if l.Done && len(words) > 1 && isDate(words[0]) && isDate(words[1]) {
+		l.Completed, l.Created = words[0], words[1]
+		words = words[2:]
+	} else if l.Done && len(words) > 0 && isDate(words[0]) {
+		l.Completed = words[0]
+		words = words[1:]
+	} else if len(words) > 0 && isDate(words[0]) {
+		l.Created = words[0]
+		words = words[1:]
+	}
+
+	l.Words = words
+	return l
+}
+
+// String formats the line back to todo.txt.
+func (l Line) String() string {
+	var parts []string
+	if l.Done {
+		parts = append(parts, "x")
+	}
+	if l.Priority != 0 {
+		parts = append(parts, "("+string(l.Priority)+")")
+	}
+	if l.Done && l.Completed != "" {
+		parts = append(parts, l.Completed)
+	}
+	if l.Created != "" {
+		parts = append(parts, l.Created)
+	}
+	parts = append(parts, l.Words...)
+	return strings.Join(parts, " ")
+}
+
+// splitKeyValue splits a "key:value" tag. URLs and words without a value aren't tags.
+func splitKeyValue(word string) (key, value string, ok bool) {
+	key, value, ok = strings.Cut(word, ":")
+	if !ok || key == "" || value == "" || strings.HasPrefix(value, "//") {
+		return "", "", false
+	}
+	return key, value, true
+}
+
+func isProjectOrContext(word string) bool {
+	return len(word) > 1 && (word[0] == '+' || word[0] == '@')
+}
+
+// priorityLetter returns the (A) priority, or the pri: tag of done tasks.
+func (l Line) priorityLetter() byte {
+	if l.Priority != 0 {
+		return l.Priority
+	}
+	if pri := l.Value(keyPriority); len(pri) == 1 && pri[0] >= 'A' && pri[0] <= 'Z' {
+		return pri[0]
+	}
+	return 0
+}
+
+// Value returns the value of the key:value tag, or "" if the line doesn't have it.
+func (l Line) Value(key string) string {
+	for _, w := range l.Words {
+		if k, v, ok := splitKeyValue(w); ok && k == key {
+			return v
+		}
+	}
+	return ""
+}
+
+// SetValue replaces the key:value tag in place, adds it at the end if missing
+// or removes it if value is empty.
+func (l *Line) SetValue(key, value string) {
+	for i, w := range l.Words {
+		if k, _, ok := splitKeyValue(w); ok && k == key {
+			if value == "" {
+				l.Words = append(l.Words[:i:i], l.Words[i+1:]...)
+			} else {
+				l.Words[i] = key + ":" + value
+			}
+			return
+		}
+	}
+	if value != "" {
+		l.Words = append(l.Words, key+":"+value)
+	}
+}
+
+// tagIndex returns the index of the word used as the prioritty tag: the last
+// +project, otherwise the last @context. Returns -1 if there's none.
+func (l Line) tagIndex() int {
+	context := -1
+	for i := len(l.Words) - 1; i >= 0; i-- {
+		w := l.Words[i]
+		if !isProjectOrContext(w) {
+			continue
+		}
+		if w[0] == '+' {
+			return i
+		}
+		if context == -1 {
+			context = i
+		}
+	}
+	return context
+}
+
+// Tag returns the prioritty tag of the line, without the +/@ sigil.
+func (l Line) Tag() string {
+	if i := l.tagIndex(); i != -1 {
+		return l.Words[i][1:]
+	}
+	return ""
+}
+
+// SetTag replaces the tag keeping its sigil, or adds it as a +project before the
+// trailing key:value tags. An empty name removes it.
+func (l *Line) SetTag(name string) {
+	i := l.tagIndex()
+	switch {
+	case i != -1 && name == "":
+		l.Words = append(l.Words[:i:i], l.Words[i+1:]...)
+	case i != -1:
+		l.Words[i] = l.Words[i][:1] + name
+	case name != "":
+		at := len(l.Words)
+		for at > 0 && l.isReserved(l.Words[at-1]) {
+			at--
+		}
+		words := append(l.Words[:at:at], "+"+name)
+		l.Words = append(words, l.Words[at:]...)
+	}
+}
+
+// isReserved reports if the word is a key:value tag mapped to a task field.
+func (l Line) isReserved(word string) bool {
+	k, _, ok := splitKeyValue(word)
+	return ok && reservedKeys[k]
+}
+
+// Title returns the description without the tag and the key:value tags mapped to task fields.
+func (l Line) Title() string {
+	tag := l.tagIndex()
+	var words []string
+	for i, w := range l.Words {
+		if i == tag || l.isReserved(w) {
+			continue
+		}
+		words = append(words, w)
+	}
+	return strings.Join(words, " ")
+}
+
+// SetTitle replaces the description, keeping the tag and the reserved key:value tags
+// at the end in their current order.
+func (l *Line) SetTitle(title string) {
+	if l.Title() == title {
+		return
+	}
+	tag := l.tagIndex()
+	words := strings.Fields(title)
+	for i, w := range l.Words {
+		if i == tag || l.isReserved(w) {
+			words = append(words, w)
+		}
+	}
+	l.Words = words
+}
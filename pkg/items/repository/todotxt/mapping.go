@@ -0,0 +1,262 @@
+package todotxt
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/markelca/prioritty/pkg/items"
+)
+
+// key:value tags mapped to task fields. The rest of the tags are kept in the title.
+const (
+	keyDue        = "due"
+	keyThreshold  = "t"      // threshold date, mapped to the scheduled date
+	keyRecurrence = "rec"    // e.g. "rec:+1w"
+	keyStatus     = "status" // in-progress or cancelled, todo.txt only knows todo/done
+	keyPriority   = "pri"    // priority of done tasks, which lose the (A) prefix
+	keyUID        = "uid"
+	keyEstimate   = "est" // e.g. "est:3pt" or "est:1h30m"
+)
+
+var reservedKeys = map[string]bool{
+	keyDue:        true,
+	keyThreshold:  true,
+	keyRecurrence: true,
+	keyStatus:     true,
+	keyPriority:   true,
+	keyUID:        true,
+	keyEstimate:   true,
+}
+
+// priorityLetters maps priorities to todo.txt letters. Letters after E are lowest.
+var priorityLetters = map[items.Priority]byte{
+	items.PriorityHighest: 'A',
+	items.PriorityHigh:    'B',
+	items.PriorityMedium:  'C',
+	items.PriorityLow:     'D',
+	items.PriorityLowest:  'E',
+}
+
+func priorityFromLetter(letter byte) items.Priority {
+	if letter == 0 {
+		return items.PriorityNone
+	}
+	for p, l := range priorityLetters {
+		if l == letter {
+			return p
+		}
+	}
+	return items.PriorityLowest
+}
+
+// recurrenceUnits maps recurrence units to the rec: tag suffixes.
+var recurrenceUnits = map[string]string{
+	"day":   "d",
+	"week":  "w",
+	"month": "m",
+	"year":  "y",
+}
+
+// formatRecurrence converts an "every [N] day|week|month|year [when done]" rule to the
+// rec: tag value. Without "when done" the recurrence is strict, which is written as "+N".
+func formatRecurrence(rule string) (string, error) {
+	fields := strings.Fields(strings.ToLower(rule))
+	if len(fields) < 2 || fields[0] != "every" {
+		return "", fmt.Errorf("unsupported recurrence %q", rule)
+	}
+	fields = fields[1:]
+
+	n := 1
+	if v, err := strconv.Atoi(fields[0]); err == nil && len(fields) > 1 {
+		n = v
+		fields = fields[1:]
+	}
+	unit, ok := recurrenceUnits[strings.TrimSuffix(fields[0], "s")]
+	if !ok {
+		return "", fmt.Errorf("unsupported recurrence %q", rule)
+	}
+	fields = fields[1:]
+
+	strict := "+"
+	if len(fields) == 2 && fields[0] == "when" && fields[1] == "done" {
+		strict = ""
+	} else if len(fields) > 0 {
+		return "", fmt.Errorf("unsupported recurrence %q", rule)
+	}
+	return strict + strconv.Itoa(n) + unit, nil
+}
+
+// parseRecurrence converts a rec: tag value back to a recurrence rule.
+func parseRecurrence(value string) string {
+	strict := strings.HasPrefix(value, "+")
+	value = strings.TrimPrefix(value, "+")
+	if len(value) < 1 {
+		return ""
+	}
+
+	n := 1
+	if len(value) > 1 {
+		v, err := strconv.Atoi(value[:len(value)-1])
+		if err != nil {
+			return ""
+		}
+		n = v
+	}
+	var unit string
+	for u, suffix := range recurrenceUnits {
+		if suffix == value[len(value)-1:] {
+			unit = u
+		}
+	}
+	if unit == "" {
+		return ""
+	}
+
+	rule := "every " + unit
+	if n != 1 {
+		rule = "every " + strconv.Itoa(n) + " " + unit + "s"
+	}
+	if !strict {
+		rule += " when done"
+	}
+	return rule
+}
+
+func parseDate(s string) time.Time {
+	d, err := items.ParseDate(s)
+	if err != nil {
+		log.Printf("Warning: invalid todo.txt date %q: %v", s, err)
+		return time.Time{}
+	}
+	return d
+}
+
+func parseEstimate(s string) items.Estimate {
+	e, err := items.ParseEstimate(s)
+	if err != nil {
+		log.Printf("Warning: invalid todo.txt estimate: %v", err)
+	}
+	return e
+}
+
+// taskFromLine creates a Task from a todo.txt line. Lines without a creation date
+// use createdAt instead.
+func taskFromLine(l Line, id string, createdAt time.Time) items.Task {
+	task := items.Task{
+		Item: items.Item{
+			Id:        id,
+			UID:       l.Value(keyUID),
+			Title:     l.Title(),
+			CreatedAt: createdAt,
+		},
+		Status:        items.Todo,
+		DueDate:       parseDate(l.Value(keyDue)),
+		ScheduledDate: parseDate(l.Value(keyThreshold)),
+		Recurrence:    parseRecurrence(l.Value(keyRecurrence)),
+		Estimate:      parseEstimate(l.Value(keyEstimate)),
+	}
+	if l.Created != "" {
+		task.CreatedAt = parseDate(l.Created)
+	}
+
+	if tag := l.Tag(); tag != "" {
+		task.Tag = &items.Tag{Id: tag, Name: tag}
+	}
+
+	status := items.ParseStatus(l.Value(keyStatus))
+	switch {
+	case l.Done && status == items.Cancelled:
+		task.Status = items.Cancelled
+	case l.Done:
+		task.Status = items.Done
+		task.CompletedAt = parseDate(l.Completed)
+	case status == items.InProgress:
+		task.Status = items.InProgress
+	}
+
+	task.Priority = priorityFromLetter(l.priorityLetter())
+
+	return task
+}
+
+// applyTask updates the line with the task fields, except the tag. Fields that
+// didn't change are left as written, so hand written tags survive.
+func applyTask(l *Line, t items.Task, now time.Time) {
+	current := taskFromLine(*l, t.Id, t.CreatedAt)
+
+	l.SetTitle(t.Title)
+	if current.Status != t.Status {
+		applyStatus(l, t.Status, now)
+	}
+	if current.Priority != t.Priority {
+		setPriority(l, t.Priority)
+	}
+	if !current.DueDate.Equal(t.DueDate) {
+		l.SetValue(keyDue, items.FormatDate(t.DueDate))
+	}
+	if !current.ScheduledDate.Equal(t.ScheduledDate) {
+		l.SetValue(keyThreshold, items.FormatDate(t.ScheduledDate))
+	}
+	if current.UID != t.UID {
+		l.SetValue(keyUID, t.UID)
+	}
+	if current.Estimate != t.Estimate {
+		l.SetValue(keyEstimate, t.Estimate.String())
+	}
+	if current.Recurrence != t.Recurrence {
+		rec := ""
+		if t.Recurrence != "" {
+			var err error
+			if rec, err = formatRecurrence(t.Recurrence); err != nil {
+				log.Printf("Warning: recurrence not saved to todo.txt: %v", err)
+			}
+		}
+		l.SetValue(keyRecurrence, rec)
+	}
+}
+
+// applyStatus marks the line as done (with today's completion date) for done and
+// cancelled tasks, and keeps the statuses todo.txt doesn't have in a status: tag.
+func applyStatus(l *Line, status items.Status, now time.Time) {
+	letter := l.priorityLetter()
+	wasDone := l.Done
+	l.Done = status == items.Done || status == items.Cancelled
+	switch {
+	case l.Done && !wasDone:
+		l.Completed = items.FormatDate(now)
+	case !l.Done:
+		l.Completed = ""
+	}
+
+	switch status {
+	case items.InProgress, items.Cancelled:
+		l.SetValue(keyStatus, string(status))
+	default:
+		l.SetValue(keyStatus, "")
+	}
+
+	if l.Done != wasDone && letter != 0 {
+		setLetter(l, letter)
+	}
+}
+
+// setPriority writes the priority as the (A) prefix, or as a pri: tag on done tasks.
+func setPriority(l *Line, p items.Priority) {
+	setLetter(l, priorityLetters[p])
+}
+
+// setLetter sets the priority letter, 0 removes it.
+func setLetter(l *Line, letter byte) {
+	l.Priority = 0
+	l.SetValue(keyPriority, "")
+	switch {
+	case letter == 0:
+	case l.Done:
+		l.SetValue(keyPriority, string(letter))
+	default:
+		l.Priority = letter
+	}
+}
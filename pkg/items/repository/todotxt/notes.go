@@ -0,0 +1,166 @@
+package todotxt
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The notes file keeps what todo.txt can't: notes and task bodies. It's markdown,
+// one section per item:
+//
+//	## Meeting notes
+//	<!-- note created:2024-03-01T09:30:00Z tag:work -->
+//
+//	Discussed the roadmap.
+//
+//	## Write the release notes
+//	<!-- task line:3 -->
+//
+//	Mention the new backends.
+//
+// Task sections point to their todo.txt line, and are matched by title if the line moved.
+
+const headingPrefix = "## "
+
+var (
+	metaPattern    = regexp.MustCompile(`^<!-- (note|task)((?: [a-z_]+:\S+)*) -->$`)
+	headingPattern = regexp.MustCompile(`^\\*##( |$)`)
+)
+
+// entry is a section of the notes file: a note, or the body of the task on line.
+type entry struct {
+	task      bool
+	line      int
+	title     string
+	tag       string
+	createdAt string
+	uid       string
+	body      string
+}
+
+// notesFile is the parsed notes file. Text before the first section is kept as is.
+type notesFile struct {
+	preamble string
+	entries  []entry
+}
+
+func (e entry) meta() string {
+	parts := []string{"note"}
+	if e.task {
+		parts = []string{"task", "line:" + strconv.Itoa(e.line)}
+	}
+	if e.createdAt != "" {
+		parts = append(parts, "created:"+e.createdAt)
+	}
+	if e.tag != "" {
+		parts = append(parts, "tag:"+e.tag)
+	}
+	if e.uid != "" {
+		parts = append(parts, "uid:"+e.uid)
+	}
+	return "<!-- " + strings.Join(parts, " ") + " -->"
+}
+
+func (e *entry) setMeta(kind, fields string) {
+	e.task = kind == "task"
+	for _, field := range strings.Fields(fields) {
+		key, value, _ := strings.Cut(field, ":")
+		switch key {
+		case "line":
+			e.line, _ = strconv.Atoi(value)
+		case "created":
+			e.createdAt = value
+		case "tag":
+			e.tag = value
+		case "uid":
+			e.uid = value
+		}
+	}
+}
+
+// escapeBody prefixes a backslash to body lines that would start a new section.
+// Markdown renders "\## text" as "## text", so the note looks the same.
+func escapeBody(body string) string {
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		if headingPattern.MatchString(line) {
+			lines[i] = `\` + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func unescapeBody(body string) string {
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, `\`) && headingPattern.MatchString(line) {
+			lines[i] = line[1:]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func isHeading(line string) bool {
+	return line == strings.TrimSpace(headingPrefix) || strings.HasPrefix(line, headingPrefix)
+}
+
+func parseNotes(content string) notesFile {
+	var f notesFile
+	var preamble, body []string
+	var current *entry
+
+	flush := func() {
+		if current == nil {
+			return
+		}
+		current.body = unescapeBody(strings.Trim(strings.Join(body, "\n"), "\n"))
+		f.entries = append(f.entries, *current)
+		body = nil
+	}
+
+	lines := strings.Split(content, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if !isHeading(line) {
+			if current == nil {
+				preamble = append(preamble, line)
+			} else {
+				body = append(body, line)
+			}
+			continue
+		}
+
+		flush()
+		current = &entry{title: strings.TrimSpace(strings.TrimPrefix(line, strings.TrimSpace(headingPrefix)))}
+		if i+1 < len(lines) {
+			if m := metaPattern.FindStringSubmatch(lines[i+1]); m != nil {
+				current.setMeta(m[1], m[2])
+				i++
+			}
+		}
+	}
+	flush()
+
+	f.preamble = strings.Trim(strings.Join(preamble, "\n"), "\n")
+	return f
+}
+
+func (f notesFile) String() string {
+	var sb strings.Builder
+	if f.preamble != "" {
+		sb.WriteString(f.preamble)
+		sb.WriteString("\n")
+	}
+	for i, e := range f.entries {
+		if i > 0 || f.preamble != "" {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(headingPrefix + e.title + "\n")
+		sb.WriteString(e.meta() + "\n")
+		if e.body != "" {
+			sb.WriteString("\n" + escapeBody(e.body) + "\n")
+		}
+	}
+	return sb.String()
+}
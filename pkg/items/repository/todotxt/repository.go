@@ -0,0 +1,542 @@
+// Package todotxt implements repository.Repository on a todo.txt file
+// (https://github.com/todotxt/todo.txt), so the same tasks can be used with the
+// todo.txt tools. Notes and task bodies, which todo.txt doesn't have, are kept
+// in a markdown file next to it.
+package todotxt
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/markelca/prioritty/pkg/items"
+	"github.com/markelca/prioritty/pkg/items/repository"
+	"github.com/markelca/prioritty/pkg/items/repository/internal/atomicfile"
+	"github.com/markelca/prioritty/pkg/items/repository/internal/filelock"
+)
+
+var (
+	_ repository.Repository = (*TodoTxtRepository)(nil)
+	_ repository.Expecter   = (*TodoTxtRepository)(nil)
+)
+
+// notePrefix starts note IDs, which are their position in the notes file ("n1", "n2"...).
+// Task IDs are their line number, like in todo.sh.
+const notePrefix = "n"
+
+const timeFormat = time.RFC3339
+
+// TodoTxtRepository stores tasks in a todo.txt file. Tags are the last +project
+// (or @context) of each line and, like in the Obsidian backend, only exist while used.
+type TodoTxtRepository struct {
+	path     string
+	expected items.ItemInterface // checked before changes, see Expecting
+}
+
+// NewTodoTxtRepository creates a TodoTxtRepository for the given todo.txt file.
+// The files are created on the first write.
+func NewTodoTxtRepository(path string) *TodoTxtRepository {
+	return &TodoTxtRepository{path: path}
+}
+
+// Path returns the path to the todo.txt file.
+func (r *TodoTxtRepository) Path() string {
+	return r.path
+}
+
+// NotesPath returns the path to the notes file, e.g. "todo.notes.md" for "todo.txt".
+func (r *TodoTxtRepository) NotesPath() string {
+	return strings.TrimSuffix(r.path, filepath.Ext(r.path)) + ".notes.md"
+}
+
+// Expecting returns a view of the repository whose changes fail with
+// repository.ErrConflict if the item expected isn't stored as it is.
+func (r *TodoTxtRepository) Expecting(expected items.ItemInterface) repository.Repository {
+	return &TodoTxtRepository{path: r.path, expected: expected}
+}
+
+func (r *TodoTxtRepository) lockPath() string {
+	return filepath.Join(filepath.Dir(r.path), "."+filepath.Base(r.path)+".lock")
+}
+
+// state is the contents of both files.
+type state struct {
+	lines        []string
+	notes        notesFile
+	todoModTime  time.Time // used for lines without a creation date
+	notesModTime time.Time
+}
+
+func readFile(path string) (string, time.Time, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", time.Time{}, nil
+	}
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return string(content), info.ModTime(), nil
+}
+
+func (r *TodoTxtRepository) load() (*state, error) {
+	todo, todoModTime, err := readFile(r.path)
+	if err != nil {
+		return nil, err
+	}
+	notes, notesModTime, err := readFile(r.NotesPath())
+	if err != nil {
+		return nil, err
+	}
+
+	st := &state{
+		notes:        parseNotes(notes),
+		todoModTime:  todoModTime,
+		notesModTime: notesModTime,
+	}
+	todo = strings.ReplaceAll(todo, "\r\n", "\n")
+	if todo = strings.TrimRight(todo, "\n"); todo != "" {
+		st.lines = strings.Split(todo, "\n")
+	}
+	return st, nil
+}
+
+func (r *TodoTxtRepository) save(st *state) error {
+	todo := ""
+	if len(st.lines) > 0 {
+		todo = strings.Join(st.lines, "\n") + "\n"
+	}
+	if err := atomicfile.WriteFile(r.path, []byte(todo)); err != nil {
+		return err
+	}
+
+	// Don't leave an empty notes file around
+	if len(st.notes.entries) == 0 && st.notes.preamble == "" {
+		if err := os.Remove(r.NotesPath()); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return atomicfile.WriteFile(r.NotesPath(), []byte(st.notes.String()))
+}
+
+// update runs fn on the current contents of the files and writes them back if fn
+// succeeds, while holding the lock. The item expected by an Expecting view is
+// checked first.
+func (r *TodoTxtRepository) update(fn func(*state) error) error {
+	return filelock.With(r.lockPath(), func() error {
+		st, err := r.load()
+		if err != nil {
+			return err
+		}
+		if err := repository.CheckExpected(r.expected, st.tasks(), st.notesList()); err != nil {
+			return err
+		}
+		if err := fn(st); err != nil {
+			return err
+		}
+		return r.save(st)
+	})
+}
+
+// Reset removes the todo.txt and notes files (used for demo cleanup).
+func (r *TodoTxtRepository) Reset() error {
+	return filelock.With(r.lockPath(), func() error {
+		for _, path := range []string{r.path, r.NotesPath()} {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// lineIndex returns the index of the task line with the given ID.
+func (st *state) lineIndex(id string) (int, error) {
+	n, err := strconv.Atoi(id)
+	if err != nil || n < 1 || n > len(st.lines) || strings.TrimSpace(st.lines[n-1]) == "" {
+		return 0, repository.ErrNotFound
+	}
+	return n - 1, nil
+}
+
+// bodies maps task line indexes to the notes file entries holding their body.
+// Entries are matched by line and title, or only by title if the line moved
+// (e.g. after editing todo.txt by hand). Entries matching no task are ignored.
+func (st *state) bodies() map[int]int {
+	result := make(map[int]int)
+	taken := make(map[int]bool)
+	titles := make(map[int]string)
+	for i, raw := range st.lines {
+		if strings.TrimSpace(raw) != "" {
+			titles[i] = ParseLine(raw).Title()
+		}
+	}
+
+	for e, entry := range st.notes.entries {
+		if !entry.task {
+			continue
+		}
+		if title, ok := titles[entry.line-1]; ok && title == entry.title && !taken[entry.line-1] {
+			result[entry.line-1] = e
+			taken[entry.line-1] = true
+			continue
+		}
+		match := -1
+		for i, title := range titles {
+			if title != entry.title || taken[i] {
+				continue
+			}
+			if match != -1 {
+				match = -1
+				break
+			}
+			match = i
+		}
+		if match != -1 {
+			result[match] = e
+			taken[match] = true
+		}
+	}
+	return result
+}
+
+// setBody stores the body of the task on the given line, removing its entry if empty.
+func (st *state) setBody(index int, title, body string) {
+	e, ok := st.bodies()[index]
+	switch {
+	case ok && body == "":
+		st.notes.entries = append(st.notes.entries[:e], st.notes.entries[e+1:]...)
+	case ok:
+		st.notes.entries[e].line = index + 1
+		st.notes.entries[e].title = title
+		st.notes.entries[e].body = body
+	case body != "":
+		st.notes.entries = append(st.notes.entries, entry{task: true, line: index + 1, title: title, body: body})
+	}
+}
+
+func (st *state) task(index int, bodies map[int]int) items.Task {
+	task := taskFromLine(ParseLine(st.lines[index]), strconv.Itoa(index+1), st.todoModTime)
+	// Lines have no modification time, the files' is the closest there is
+	task.UpdatedAt = st.todoModTime
+	if e, ok := bodies[index]; ok {
+		task.Body = st.notes.entries[e].body
+		if st.notesModTime.After(task.UpdatedAt) {
+			task.UpdatedAt = st.notesModTime
+		}
+	}
+	return task
+}
+
+func (st *state) tasks() []items.Task {
+	var tasks []items.Task
+	bodies := st.bodies()
+	for i, raw := range st.lines {
+		if strings.TrimSpace(raw) == "" {
+			continue
+		}
+		tasks = append(tasks, st.task(i, bodies))
+	}
+	return tasks
+}
+
+// noteIndex returns the index in the notes file of the note with the given ID.
+func (st *state) noteIndex(id string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimPrefix(id, notePrefix))
+	if err != nil || !strings.HasPrefix(id, notePrefix) {
+		return 0, repository.ErrNotFound
+	}
+	for e, entry := range st.notes.entries {
+		if entry.task {
+			continue
+		}
+		if n--; n == 0 {
+			return e, nil
+		}
+	}
+	return 0, repository.ErrNotFound
+}
+
+func (st *state) notesList() []items.Note {
+	var notes []items.Note
+	for _, entry := range st.notes.entries {
+		if entry.task {
+			continue
+		}
+		note := items.Note{
+			Item: items.Item{
+				Id:        notePrefix + strconv.Itoa(len(notes)+1),
+				UID:       entry.uid,
+				Title:     entry.title,
+				Body:      entry.body,
+				CreatedAt: st.notesModTime,
+				UpdatedAt: st.notesModTime,
+			},
+		}
+		if t, err := time.Parse(timeFormat, entry.createdAt); err == nil {
+			note.CreatedAt = t
+		}
+		if entry.tag != "" {
+			note.Tag = &items.Tag{Id: entry.tag, Name: entry.tag}
+		}
+		notes = append(notes, note)
+	}
+	return notes
+}
+
+func (r *TodoTxtRepository) GetTasks() ([]items.Task, error) {
+	st, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	return st.tasks(), nil
+}
+
+// CreateTask adds a task line. Time logs can't be kept, they fail with repository.ErrNoTimeLog.
+func (r *TodoTxtRepository) CreateTask(t *items.Task) error {
+	if len(t.TimeLog) > 0 {
+		return fmt.Errorf("task %q: %w", t.Title, repository.ErrNoTimeLog)
+	}
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now()
+	}
+	return r.update(func(st *state) error {
+		l := Line{Created: items.FormatDate(t.CreatedAt)}
+		applyTask(&l, *t, time.Now())
+		st.lines = append(st.lines, l.String())
+		index := len(st.lines) - 1
+		st.setBody(index, t.Title, t.Body)
+		t.Id = strconv.Itoa(index + 1)
+		return nil
+	})
+}
+
+// UpdateTask updates the task line and body. The tag is left untouched, use SetTaskTag/UnsetTaskTag.
+// Time logs can't be kept, they fail with repository.ErrNoTimeLog.
+func (r *TodoTxtRepository) UpdateTask(t items.Task) error {
+	if len(t.TimeLog) > 0 {
+		return fmt.Errorf("task %q: %w", t.Title, repository.ErrNoTimeLog)
+	}
+	return r.update(func(st *state) error {
+		index, err := st.lineIndex(t.Id)
+		if err != nil {
+			return err
+		}
+		// Find the body before the title changes
+		bodies := st.bodies()
+		l := ParseLine(st.lines[index])
+		applyTask(&l, t, time.Now())
+		if e, ok := bodies[index]; ok {
+			st.notes.entries[e].title = l.Title()
+		}
+		st.lines[index] = l.String()
+		st.setBody(index, l.Title(), t.Body)
+		return nil
+	})
+}
+
+// RemoveTask removes the task line, so the following tasks move up one line.
+func (r *TodoTxtRepository) RemoveTask(id string) error {
+	return r.update(func(st *state) error {
+		index, err := st.lineIndex(id)
+		if err != nil {
+			return err
+		}
+		st.setBody(index, "", "")
+
+		// Point the remaining bodies to their new lines
+		for i, e := range st.bodies() {
+			if i > index {
+				st.notes.entries[e].line = i
+			}
+		}
+		st.lines = append(st.lines[:index], st.lines[index+1:]...)
+		return nil
+	})
+}
+
+func (r *TodoTxtRepository) UpdateTaskStatus(t items.Task, s items.Status) error {
+	return r.updateLine(t.Id, func(l *Line) {
+		if taskFromLine(*l, t.Id, time.Time{}).Status != s {
+			applyStatus(l, s, time.Now())
+		}
+	})
+}
+
+func (r *TodoTxtRepository) SetTaskTag(t items.Task, tag items.Tag) error {
+	return r.updateLine(t.Id, func(l *Line) {
+		l.SetTag(tag.Name)
+	})
+}
+
+func (r *TodoTxtRepository) UnsetTaskTag(t items.Task) error {
+	return r.updateLine(t.Id, func(l *Line) {
+		l.SetTag("")
+	})
+}
+
+// updateLine applies fn to a task line. fn must not change the title.
+func (r *TodoTxtRepository) updateLine(id string, fn func(*Line)) error {
+	return r.update(func(st *state) error {
+		index, err := st.lineIndex(id)
+		if err != nil {
+			return err
+		}
+		l := ParseLine(st.lines[index])
+		fn(&l)
+		st.lines[index] = l.String()
+		return nil
+	})
+}
+
+func (r *TodoTxtRepository) GetNotes() ([]items.Note, error) {
+	st, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	return st.notesList(), nil
+}
+
+func (r *TodoTxtRepository) CreateNote(n *items.Note) error {
+	if n.CreatedAt.IsZero() {
+		n.CreatedAt = time.Now()
+	}
+	return r.update(func(st *state) error {
+		st.notes.entries = append(st.notes.entries, entry{
+			title:     n.Title,
+			createdAt: n.CreatedAt.UTC().Format(timeFormat),
+			uid:       n.UID,
+			body:      n.Body,
+		})
+		n.Id = notePrefix + strconv.Itoa(len(st.notesList()))
+		return nil
+	})
+}
+
+// UpdateNote updates the note title and body. The tag is left untouched, use SetNoteTag/UnsetNoteTag.
+func (r *TodoTxtRepository) UpdateNote(n items.Note) error {
+	return r.updateNote(n.Id, func(e *entry) {
+		e.uid = n.UID
+		e.title = n.Title
+		e.body = n.Body
+	})
+}
+
+func (r *TodoTxtRepository) RemoveNote(id string) error {
+	return r.update(func(st *state) error {
+		e, err := st.noteIndex(id)
+		if err != nil {
+			return err
+		}
+		st.notes.entries = append(st.notes.entries[:e], st.notes.entries[e+1:]...)
+		return nil
+	})
+}
+
+func (r *TodoTxtRepository) SetNoteTag(n items.Note, tag items.Tag) error {
+	return r.updateNote(n.Id, func(e *entry) {
+		e.tag = tag.Name
+	})
+}
+
+func (r *TodoTxtRepository) UnsetNoteTag(n items.Note) error {
+	return r.updateNote(n.Id, func(e *entry) {
+		e.tag = ""
+	})
+}
+
+func (r *TodoTxtRepository) updateNote(id string, fn func(*entry)) error {
+	return r.update(func(st *state) error {
+		e, err := st.noteIndex(id)
+		if err != nil {
+			return err
+		}
+		fn(&st.notes.entries[e])
+		return nil
+	})
+}
+
+// itemsWithTag returns the items using the tag, or all tagged items if name is empty.
+func (r *TodoTxtRepository) itemsWithTag(name string) ([]items.ItemInterface, error) {
+	st, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []items.ItemInterface
+	for _, t := range st.tasks() {
+		if t.Tag != nil && (name == "" || t.Tag.Name == name) {
+			result = append(result, &t)
+		}
+	}
+	for _, n := range st.notesList() {
+		if n.Tag != nil && (name == "" || n.Tag.Name == name) {
+			result = append(result, &n)
+		}
+	}
+	return result, nil
+}
+
+// GetTag returns a tag by name if it's used by any item.
+func (r *TodoTxtRepository) GetTag(name string) (*items.Tag, error) {
+	tagged, err := r.itemsWithTag(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(tagged) == 0 || name == "" {
+		return nil, repository.ErrNotFound
+	}
+	return &items.Tag{Id: name, Name: name}, nil
+}
+
+// GetTags returns the tags used by items, sorted by name.
+func (r *TodoTxtRepository) GetTags() ([]items.Tag, error) {
+	tagged, err := r.itemsWithTag("")
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var tags []items.Tag
+	for _, i := range tagged {
+		name := i.GetTag().Name
+		if !seen[name] {
+			seen[name] = true
+			tags = append(tags, items.Tag{Id: name, Name: name})
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].Name < tags[j].Name
+	})
+	return tags, nil
+}
+
+// CreateTag only validates the name, tags are created implicitly when assigned.
+func (r *TodoTxtRepository) CreateTag(name string) (*items.Tag, error) {
+	if name == "" || strings.ContainsAny(name, " \t\n") {
+		return nil, fmt.Errorf("invalid todo.txt tag %q, tags can't contain spaces", name)
+	}
+	return &items.Tag{Id: name, Name: name}, nil
+}
+
+// RemoveTag is a no-op since tags only exist while used, it returns ErrNotFound
+// for unused tags.
+func (r *TodoTxtRepository) RemoveTag(name string) error {
+	_, err := r.GetTag(name)
+	return err
+}
+
+func (r *TodoTxtRepository) GetItemsWithTag(name string) ([]items.ItemInterface, error) {
+	if name == "" {
+		return nil, nil
+	}
+	return r.itemsWithTag(name)
+}
@@ -0,0 +1,17 @@
+package todotxt_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/markelca/prioritty/pkg/items/repository"
+	"github.com/markelca/prioritty/pkg/items/repository/repositorytest"
+	"github.com/markelca/prioritty/pkg/items/repository/todotxt"
+)
+
+func TestRepository(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repository.Repository {
+		return todotxt.NewTodoTxtRepository(filepath.Join(t.TempDir(), "todo.txt"))
+	}, repositorytest.WithDateOnlyCreatedAt(), repositorytest.WithoutStatusHistory(),
+		repositorytest.RefusingTimeLog())
+}
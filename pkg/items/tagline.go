@@ -0,0 +1,40 @@
+package items
+
+import "strings"
+
+// Items have a single tag. Formats with several tags per item (Taskwarrior, taskbook
+// boards, iCalendar categories) keep the extra ones as a last body line such as
+// "#next #home", which AppendTagLine and SplitTagLine write and read.
+
+// AppendTagLine adds a "#tag" line with the given tags at the end of body.
+func AppendTagLine(body string, tags []string) string {
+	if len(tags) == 0 {
+		return body
+	}
+	line := "#" + strings.Join(tags, " #")
+	if body == "" {
+		return line
+	}
+	return body + "\n" + line
+}
+
+// SplitTagLine removes the last line of body if it's only made of "#tag" words,
+// returning the rest of the body and the tags.
+func SplitTagLine(body string) (string, []string) {
+	rest, last := "", body
+	if i := strings.LastIndex(body, "\n"); i != -1 {
+		rest, last = body[:i], body[i+1:]
+	}
+	fields := strings.Fields(last)
+	if len(fields) == 0 {
+		return body, nil
+	}
+	var tags []string
+	for _, f := range fields {
+		if len(f) < 2 || f[0] != '#' {
+			return body, nil
+		}
+		tags = append(tags, f[1:])
+	}
+	return rest, tags
+}
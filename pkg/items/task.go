@@ -1,6 +1,9 @@
 package items
 
-import "strings"
+import (
+	"strings"
+	"time"
+)
 
 type Status string
 
@@ -14,9 +17,26 @@ const (
 
 var _ ItemInterface = (*Task)(nil)
 
+// DateLayout is the format of date-only fields such as due dates.
+const DateLayout = "2006-01-02"
+
 type Task struct {
 	Item
+	Status        Status
+	Priority      Priority
+	DueDate       time.Time // zero if the task has no due date
+	ScheduledDate time.Time // zero if the task isn't scheduled
+	Recurrence    string    // e.g. "every week", as used by the Obsidian Tasks plugin
+	Estimate      Estimate  // zero if the task isn't estimated
+	CompletedAt   time.Time // zero if the task isn't done
+	StatusHistory []StatusChange
+	TimeLog       []TimeEntry // oldest first
+}
+
+// StatusChange is an entry of a task's status history: when it entered a status.
+type StatusChange struct {
 	Status Status
+	At     time.Time
 }
 
 func (t *Task) SetStatus(s Status) {
@@ -27,6 +47,73 @@ func (t *Task) SetStatus(s Status) {
 	}
 }
 
+// ChangeStatus sets the status, recording the transition in the status history and
+// keeping CompletedAt up to date. Setting the current status does nothing.
+func (t *Task) ChangeStatus(s Status, at time.Time) {
+	if t.Status == s {
+		return
+	}
+	at = at.UTC()
+	t.Status = s
+	t.StatusHistory = append(t.StatusHistory, StatusChange{Status: s, At: at})
+	if s == Done {
+		t.CompletedAt = at
+	} else {
+		t.CompletedAt = time.Time{}
+	}
+}
+
+// InitStatusHistory starts the status history of a new task with its initial status,
+// and sets the completion time of tasks created done. Tasks that already have a history
+// (e.g. copied from another repository) keep it.
+func (t *Task) InitStatusHistory() {
+	if len(t.StatusHistory) == 0 {
+		status := t.Status
+		if status == "" {
+			status = Todo
+		}
+		t.StatusHistory = []StatusChange{{Status: status, At: t.CreatedAt.UTC()}}
+	}
+	if t.Status == Done && t.CompletedAt.IsZero() {
+		t.CompletedAt = t.CreatedAt.UTC()
+	}
+}
+
+// ReplacingStatusOf returns the task about to replace stored, its current version,
+// with the status history of stored plus the status change, if any. A completion
+// time set on the task is kept, so copies from other repositories keep theirs.
+func (t Task) ReplacingStatusOf(stored Task, at time.Time) Task {
+	status, completedAt := t.Status, t.CompletedAt
+	t.Status, t.StatusHistory, t.CompletedAt = stored.Status, stored.StatusHistory, stored.CompletedAt
+	if status == Done && !completedAt.IsZero() && stored.Status != Done {
+		at = completedAt
+	}
+	t.ChangeStatus(status, at)
+	return t
+}
+
+// LeadTime is the time from the creation of a done task to its completion.
+func (t Task) LeadTime() (time.Duration, bool) {
+	if t.Status != Done || t.CompletedAt.IsZero() || t.CreatedAt.IsZero() {
+		return 0, false
+	}
+	return t.CompletedAt.Sub(t.CreatedAt), true
+}
+
+// CycleTime is the time from the first start of a done task (when it entered
+// in-progress) to its completion. It's unknown for tasks never started.
+func (t Task) CycleTime() (time.Duration, bool) {
+	if t.Status != Done || t.CompletedAt.IsZero() {
+		return 0, false
+	}
+	for _, c := range t.StatusHistory {
+		if c.Status == InProgress {
+			return t.CompletedAt.Sub(c.At), true
+		}
+	}
+	return 0, false
+}
+
 func (t Task) Render(r Renderer) string {
 	return r.Render(t)
 }
@@ -46,3 +133,21 @@ func ParseStatus(s string) Status {
 		return Todo
 	}
 }
+
+// ParseDate parses a date-only string (YYYY-MM-DD) in local time.
+// An empty string returns the zero time.
+func ParseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.ParseInLocation(DateLayout, s, time.Local)
+}
+
+// FormatDate formats a date-only field, returning an empty string for the zero time.
+func FormatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(DateLayout)
+}
@@ -0,0 +1,168 @@
+package items
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// TimeEntry is a period of work on a task. End is zero while its timer is running.
+type TimeEntry struct {
+	Start    time.Time
+	End      time.Time
+	Pomodoro bool // a work session completed in the focus mode of the TUI
+}
+
+// Running reports whether the timer of the entry is still running.
+func (e TimeEntry) Running() bool {
+	return e.End.IsZero()
+}
+
+// Duration returns the time spent in the entry, up to now while it's running.
+func (e TimeEntry) Duration(now time.Time) time.Duration {
+	if !e.Running() {
+		return e.End.Sub(e.Start)
+	}
+	return e.Between(e.Start, now, now)
+}
+
+// Between returns the time spent in the entry from since to until, up to now
+// while it's running.
+func (e TimeEntry) Between(since, until, now time.Time) time.Duration {
+	end := e.End
+	if e.Running() {
+		end = now
+	}
+	start := e.Start
+	if start.Before(since) {
+		start = since
+	}
+	if end.After(until) {
+		end = until
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+// RunningTimer returns the time entry of the task's running timer, if any.
+func (t Task) RunningTimer() (TimeEntry, bool) {
+	for _, e := range t.TimeLog {
+		if e.Running() {
+			return e, true
+		}
+	}
+	return TimeEntry{}, false
+}
+
+// TimerRunning reports whether the task has a running timer.
+func (t Task) TimerRunning() bool {
+	_, ok := t.RunningTimer()
+	return ok
+}
+
+// StartTimer starts a time entry at the given time, with second precision, unless a
+// timer is already running. It reports whether it started one.
+func (t *Task) StartTimer(at time.Time) bool {
+	if t.TimerRunning() {
+		return false
+	}
+	t.TimeLog = append(t.TimeLog, TimeEntry{Start: at.UTC().Truncate(time.Second)})
+	return true
+}
+
+// StopTimer ends the running time entry at the given time. It reports whether a timer
+// was running.
+func (t *Task) StopTimer(at time.Time) bool {
+	at = at.UTC().Truncate(time.Second)
+	for i, e := range t.TimeLog {
+		if !e.Running() {
+			continue
+		}
+		if at.Before(e.Start) {
+			at = e.Start
+		}
+		log := append([]TimeEntry(nil), t.TimeLog...)
+		log[i].End = at
+		t.TimeLog = log
+		return true
+	}
+	return false
+}
+
+// TimeSpent returns the total time of the task's entries, up to now for a running one.
+func (t Task) TimeSpent(now time.Time) time.Duration {
+	var total time.Duration
+	for _, e := range t.TimeLog {
+		total += e.Duration(now)
+	}
+	return total
+}
+
+// Pomodoros returns the number of pomodoros completed on the task.
+func (t Task) Pomodoros() int {
+	return t.PomodorosBetween(time.Time{}, time.Time{})
+}
+
+// PomodorosBetween returns the number of pomodoros completed on the task from since
+// until until. Zero times don't limit it.
+func (t Task) PomodorosBetween(since, until time.Time) int {
+	n := 0
+	for _, e := range t.TimeLog {
+		if !e.Pomodoro || e.End.Before(since) || (!until.IsZero() && !e.End.Before(until)) {
+			continue
+		}
+		n++
+	}
+	return n
+}
+
+// NormalizeTimeLog validates time entries entered by hand, returning them sorted by
+// start, in UTC and with second precision.
+func NormalizeTimeLog(log []TimeEntry) ([]TimeEntry, error) {
+	normalized := make([]TimeEntry, 0, len(log))
+	running := false
+	for _, e := range log {
+		if e.Start.IsZero() {
+			return nil, errors.New("time entries need a start")
+		}
+		e.Start = e.Start.UTC().Truncate(time.Second)
+		if e.Running() {
+			if running {
+				return nil, errors.New("only one timer can be running")
+			}
+			if e.Pomodoro {
+				return nil, errors.New("pomodoros need an end")
+			}
+			running = true
+		} else {
+			e.End = e.End.UTC().Truncate(time.Second)
+			if e.End.Before(e.Start) {
+				return nil, fmt.Errorf("time entry ends (%s) before it starts (%s)", DisplayTime(e.End), DisplayTime(e.Start))
+			}
+		}
+		normalized = append(normalized, e)
+	}
+	sort.SliceStable(normalized, func(i, j int) bool {
+		return normalized[i].Start.Before(normalized[j].Start)
+	})
+	return normalized, nil
+}
+
+// FormatWorkTime formats time spent in hours and minutes, e.g. "26h 5m" or "45m".
+// Unlike FormatDuration it doesn't count days, which hardly mean 24 hours of work.
+func FormatWorkTime(d time.Duration) string {
+	d = d.Truncate(time.Minute)
+	hours := int(d / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+	switch {
+	case hours > 0 && minutes > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	case hours > 0:
+		return fmt.Sprintf("%dh", hours)
+	default:
+		return fmt.Sprintf("%dm", minutes)
+	}
+}
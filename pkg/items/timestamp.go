@@ -0,0 +1,76 @@
+package items
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TimestampLayout is the format timestamps (creation, completion...) are stored in, in UTC.
+const TimestampLayout = time.RFC3339
+
+// timestampLayouts are the accepted timestamp formats, from the most to the least precise.
+// Those without a time zone are read in the location given to ParseTimestamp.
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00", // written by some SQLite drivers
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999", // SQLite's CURRENT_TIMESTAMP, in UTC
+	"2006-01-02T15:04",              // Obsidian datetime properties
+	"2006-01-02 15:04",
+	DateLayout,
+}
+
+// ParseTimestamp parses a timestamp written by prioritty, another tool or by hand.
+// Timestamps without a time zone are in loc. An empty string returns the zero time.
+func ParseTimestamp(s string, loc *time.Location) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range timestampLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp %q", s)
+}
+
+// FormatTimestamp formats a timestamp for storage, in UTC. The zero time is an empty string.
+func FormatTimestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(TimestampLayout)
+}
+
+// DisplayTime formats a timestamp for display, in the local time zone.
+func DisplayTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format("2006-01-02 15:04 MST")
+}
+
+// FormatDuration formats a duration roughly, with its two largest units, e.g. "2d 3h" or "45m".
+func FormatDuration(d time.Duration) string {
+	if d < 0 {
+		return "-" + FormatDuration(-d)
+	}
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+	switch {
+	case days > 0 && hours > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case days > 0:
+		return fmt.Sprintf("%dd", days)
+	case hours > 0 && minutes > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	case hours > 0:
+		return fmt.Sprintf("%dh", hours)
+	default:
+		return fmt.Sprintf("%dm", minutes)
+	}
+}
@@ -0,0 +1,39 @@
+package items
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"fmt"
+	"strconv"
+)
+
+// uidNamespace seeds the UIDs derived by StableUID.
+const uidNamespace = "prioritty"
+
+func formatUUID(b []byte, version byte) string {
+	b[6] = b[6]&0x0f | version<<4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// NewUID returns a random (version 4) UUID.
+func NewUID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return formatUUID(b, 4)
+}
+
+// StableUID returns the UID of the item or, for items without one, a UUID derived from
+// its type, ID and creation time, so exporting the same item twice gives the same UID.
+func StableUID(i ItemInterface) string {
+	if uid := i.GetUID(); uid != "" {
+		return uid
+	}
+	kind := ItemTypeNote
+	switch i.(type) {
+	case *Task, Task:
+		kind = ItemTypeTask
+	}
+	sum := sha1.Sum([]byte(uidNamespace + "/" + string(kind) + "/" + i.GetId() + "/" + strconv.FormatInt(i.GetCreatedAt().Unix(), 10)))
+	return formatUUID(sum[:16], 5)
+}
@@ -0,0 +1,75 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+)
+
+// checklistPattern matches markdown checklist lines such as "- [ ] do thing" or "  1. [x] done".
+var checklistPattern = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+\[(.)\]\s+(.*)$`)
+
+// ChecklistItem is a single "- [ ] text" line of a markdown document.
+type ChecklistItem struct {
+	Indent   string // leading whitespace, kept to write the line back untouched
+	Marker   string // list marker ("-", "*", "+", "1.")
+	Checkbox rune   // character between the brackets (' ', 'x', '/', '-', ...)
+	Text     string // everything after the checkbox
+}
+
+// ParseChecklistItem parses a checklist line. It returns false if the line isn't a checklist item.
+func ParseChecklistItem(line string) (ChecklistItem, bool) {
+	m := checklistPattern.FindStringSubmatch(line)
+	if m == nil {
+		return ChecklistItem{}, false
+	}
+	return ChecklistItem{
+		Indent:   m[1],
+		Marker:   m[2],
+		Checkbox: []rune(m[3])[0],
+		Text:     m[4],
+	}, true
+}
+
+// String formats the checklist item back to a markdown line.
+func (c ChecklistItem) String() string {
+	return c.Indent + c.Marker + " [" + string(c.Checkbox) + "] " + c.Text
+}
+
+// ChecklistLine is a checklist item found in a document along with its 1-based line number.
+type ChecklistLine struct {
+	Line int
+	Item ChecklistItem
+}
+
+// FindChecklistItems returns all checklist items of a markdown document.
+// Lines inside the frontmatter and inside fenced code blocks are ignored.
+func FindChecklistItems(content string) []ChecklistLine {
+	var result []ChecklistLine
+	lines := strings.Split(content, "\n")
+
+	start := 0
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == Delimiter {
+		for i := 1; i < len(lines); i++ {
+			if strings.TrimSpace(lines[i]) == Delimiter {
+				start = i + 1
+				break
+			}
+		}
+	}
+
+	inFence := false
+	for i := start; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if item, ok := ParseChecklistItem(strings.TrimRight(lines[i], "\r")); ok {
+			result = append(result, ChecklistLine{Line: i + 1, Item: item})
+		}
+	}
+	return result
+}
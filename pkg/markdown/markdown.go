@@ -23,33 +23,68 @@ func (s unquotedString) MarshalYAML() (any, error) {
 
 // Frontmatter represents the YAML frontmatter for items.
 type Frontmatter struct {
-	Title     string `yaml:"title"`
-	Type      string `yaml:"type,omitempty"`
-	Status    string `yaml:"status,omitempty"`
-	Tag       string `yaml:"tag,omitempty"`
-	CreatedAt string `yaml:"created_at,omitempty"`
+	Title         string   `yaml:"title"`
+	Type          string   `yaml:"type,omitempty"`
+	Status        string   `yaml:"status,omitempty"`
+	Tag           string   `yaml:"tag,omitempty"`
+	Priority      string   `yaml:"priority,omitempty"`
+	Due           string   `yaml:"due,omitempty"`
+	Scheduled     string   `yaml:"scheduled,omitempty"`
+	Recurrence    string   `yaml:"recurrence,omitempty"`
+	Estimate      string   `yaml:"estimate,omitempty"`
+	CreatedAt     string   `yaml:"created_at,omitempty"`
+	UID           string   `yaml:"uid,omitempty"`
+	CompletedAt   string   `yaml:"completed_at,omitempty"`
+	StatusHistory []string `yaml:"status_history,omitempty"` // "<status> <time>" entries, oldest first
+	TimeLog       []string `yaml:"time_log,omitempty"`       // "<start> <end>" entries, only "<start>" while running
 }
 
 // unquotedFrontmatter is used internally for serialization to produce clean YAML without quotes.
 type unquotedFrontmatter struct {
-	Title     unquotedString `yaml:"title"`
-	Type      unquotedString `yaml:"type,omitempty"`
-	Status    unquotedString `yaml:"status,omitempty"`
-	Tag       unquotedString `yaml:"tag,omitempty"`
-	CreatedAt unquotedString `yaml:"created_at,omitempty"`
+	Title         unquotedString   `yaml:"title"`
+	Type          unquotedString   `yaml:"type,omitempty"`
+	Status        unquotedString   `yaml:"status,omitempty"`
+	Tag           unquotedString   `yaml:"tag,omitempty"`
+	Priority      unquotedString   `yaml:"priority,omitempty"`
+	Due           unquotedString   `yaml:"due,omitempty"`
+	Scheduled     unquotedString   `yaml:"scheduled,omitempty"`
+	Recurrence    unquotedString   `yaml:"recurrence,omitempty"`
+	Estimate      unquotedString   `yaml:"estimate,omitempty"`
+	CreatedAt     unquotedString   `yaml:"created_at,omitempty"`
+	UID           unquotedString   `yaml:"uid,omitempty"`
+	CompletedAt   unquotedString   `yaml:"completed_at,omitempty"`
+	StatusHistory []unquotedString `yaml:"status_history,omitempty"`
+	TimeLog       []unquotedString `yaml:"time_log,omitempty"`
 }
 
 // toUnquoted converts a Frontmatter to unquotedFrontmatter for serialization.
 func (fm Frontmatter) toUnquoted() unquotedFrontmatter {
 	return unquotedFrontmatter{
-		Title:     unquotedString(fm.Title),
-		Type:      unquotedString(fm.Type),
-		Status:    unquotedString(fm.Status),
-		Tag:       unquotedString(fm.Tag),
-		CreatedAt: unquotedString(fm.CreatedAt),
+		Title:         unquotedString(fm.Title),
+		Type:          unquotedString(fm.Type),
+		Status:        unquotedString(fm.Status),
+		Tag:           unquotedString(fm.Tag),
+		Priority:      unquotedString(fm.Priority),
+		Due:           unquotedString(fm.Due),
+		Scheduled:     unquotedString(fm.Scheduled),
+		Recurrence:    unquotedString(fm.Recurrence),
+		Estimate:      unquotedString(fm.Estimate),
+		CreatedAt:     unquotedString(fm.CreatedAt),
+		UID:           unquotedString(fm.UID),
+		CompletedAt:   unquotedString(fm.CompletedAt),
+		StatusHistory: unquotedStrings(fm.StatusHistory),
+		TimeLog:       unquotedStrings(fm.TimeLog),
 	}
 }
 
+func unquotedStrings(values []string) []unquotedString {
+	var result []unquotedString
+	for _, v := range values {
+		result = append(result, unquotedString(v))
+	}
+	return result
+}
+
 // Serialize converts a Frontmatter to markdown content with body.
 func (fm Frontmatter) Serialize(body string) ([]byte, error) {
 	return SerializeFrontmatter(fm.toUnquoted(), body)
@@ -57,12 +92,21 @@ func (fm Frontmatter) Serialize(body string) ([]byte, error) {
 
 // ItemInput contains the data to serialize an item to markdown.
 type ItemInput struct {
-	ItemType  items.ItemType
-	Title     string
-	Body      string
-	Status    string
-	Tag       string
-	CreatedAt string // Only populated when serializing for storage/display, not for editor
+	ItemType      items.ItemType
+	Title         string
+	Body          string
+	Status        string
+	Tag           string
+	Priority      string
+	Due           string
+	Scheduled     string
+	Recurrence    string
+	Estimate      string
+	CreatedAt     string   // Only populated when serializing for storage/display, not for editor
+	UID           string   // Only populated when serializing for storage
+	CompletedAt   string   // Only populated when serializing for storage
+	StatusHistory []string // Only populated when serializing for storage
+	TimeLog       []string // Only populated when serializing for storage
 }
 
 // Parse extracts frontmatter and body from markdown content.
@@ -135,11 +179,20 @@ func Serialize(input ItemInput) (string, error) {
 		Type:      string(input.ItemType),
 		Tag:       input.Tag,
 		CreatedAt: input.CreatedAt,
+		UID:       input.UID,
 	}
 
-	// Only include status for tasks
+	// Only include status and planning fields for tasks
 	if input.ItemType == items.ItemTypeTask {
 		fm.Status = input.Status
+		fm.Priority = input.Priority
+		fm.Due = input.Due
+		fm.Scheduled = input.Scheduled
+		fm.Recurrence = input.Recurrence
+		fm.Estimate = input.Estimate
+		fm.CompletedAt = input.CompletedAt
+		fm.StatusHistory = input.StatusHistory
+		fm.TimeLog = input.TimeLog
 	}
 
 	content, err := SerializeFrontmatter(fm.toUnquoted(), input.Body)
@@ -152,10 +205,15 @@ func Serialize(input ItemInput) (string, error) {
 
 // taskEditorFrontmatter is used for task editor templates with all fields visible.
 type taskEditorFrontmatter struct {
-	Title  unquotedString `yaml:"title"`
-	Type   unquotedString `yaml:"type"`
-	Status unquotedString `yaml:"status"`
-	Tag    unquotedString `yaml:"tag"`
+	Title      unquotedString `yaml:"title"`
+	Type       unquotedString `yaml:"type"`
+	Status     unquotedString `yaml:"status"`
+	Tag        unquotedString `yaml:"tag"`
+	Priority   unquotedString `yaml:"priority"`
+	Due        unquotedString `yaml:"due"`
+	Scheduled  unquotedString `yaml:"scheduled"`
+	Recurrence unquotedString `yaml:"recurrence,omitempty"`
+	Estimate   unquotedString `yaml:"estimate"`
 }
 
 // noteEditorFrontmatter is used for note editor templates (no status field).
@@ -177,10 +235,15 @@ func SerializeForEditor(input ItemInput) (string, error) {
 			status = string(items.Todo)
 		}
 		fm := taskEditorFrontmatter{
-			Title:  unquotedString(input.Title),
-			Type:   unquotedString(input.ItemType),
-			Status: unquotedString(status),
-			Tag:    unquotedString(input.Tag),
+			Title:      unquotedString(input.Title),
+			Type:       unquotedString(input.ItemType),
+			Status:     unquotedString(status),
+			Tag:        unquotedString(input.Tag),
+			Priority:   unquotedString(input.Priority),
+			Due:        unquotedString(input.Due),
+			Scheduled:  unquotedString(input.Scheduled),
+			Recurrence: unquotedString(input.Recurrence),
+			Estimate:   unquotedString(input.Estimate),
 		}
 		content, err = SerializeFrontmatter(fm, input.Body)
 	} else {
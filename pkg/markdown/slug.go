@@ -0,0 +1,55 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Slug converts a title to kebab-case, as in the names of the files of Obsidian
+// items and the links to them. It removes diacritics and special characters, and
+// converts spaces to hyphens.
+func Slug(title string) string {
+	// Normalize unicode (NFD) and remove diacritics
+	normalized := norm.NFD.String(title)
+	var builder strings.Builder
+	for _, r := range normalized {
+		if unicode.Is(unicode.Mn, r) {
+			// Skip combining marks (diacritics)
+			continue
+		}
+		builder.WriteRune(r)
+	}
+	result := builder.String()
+
+	// Convert to lowercase
+	result = strings.ToLower(result)
+
+	// Replace spaces and underscores with hyphens
+	result = strings.ReplaceAll(result, " ", "-")
+	result = strings.ReplaceAll(result, "_", "-")
+
+	// Remove invalid filesystem characters: / \ : * ? " < > |
+	invalidChars := regexp.MustCompile(`[/\\:*?"<>|]`)
+	result = invalidChars.ReplaceAllString(result, "")
+
+	// Remove any character that's not alphanumeric or hyphen
+	validChars := regexp.MustCompile(`[^a-z0-9-]`)
+	result = validChars.ReplaceAllString(result, "")
+
+	// Collapse multiple hyphens into one
+	multiHyphen := regexp.MustCompile(`-+`)
+	result = multiHyphen.ReplaceAllString(result, "-")
+
+	// Trim leading/trailing hyphens
+	result = strings.Trim(result, "-")
+
+	// If result is empty, use a default
+	if result == "" {
+		result = "untitled"
+	}
+
+	return result
+}
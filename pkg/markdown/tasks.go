@@ -0,0 +1,288 @@
+package markdown
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/markelca/prioritty/pkg/items"
+)
+
+// TaskLine is the text of a checklist item in the Obsidian Tasks plugin emoji format, e.g.
+// "Pay rent #home ⏫ 🔁 every month 📅 2025-07-01". Fields prioritty doesn't use are kept
+// so the line can be written back without data loss.
+type TaskLine struct {
+	Description  string // task text, including any #tags
+	Priority     items.Priority
+	Recurrence   string
+	Created      time.Time
+	Start        time.Time
+	Scheduled    time.Time
+	Due          time.Time
+	Cancelled    time.Time
+	Done         time.Time
+	ID           string
+	DependsOn    string
+	OnCompletion string
+	BlockLink    string // "^abc123" block reference, always written last
+}
+
+// Emoji used by the Obsidian Tasks plugin.
+const (
+	emojiHighest      = "🔺"
+	emojiHigh         = "⏫"
+	emojiMedium       = "🔼"
+	emojiLow          = "🔽"
+	emojiLowest       = "⏬"
+	emojiRecurrence   = "🔁"
+	emojiCreated      = "➕"
+	emojiStart        = "🛫"
+	emojiScheduled    = "⏳"
+	emojiDue          = "📅"
+	emojiCancelled    = "❌"
+	emojiDone         = "✅"
+	emojiID           = "🆔"
+	emojiDependsOn    = "⛔"
+	emojiOnCompletion = "🏁"
+)
+
+var priorityEmoji = map[items.Priority]string{
+	items.PriorityHighest: emojiHighest,
+	items.PriorityHigh:    emojiHigh,
+	items.PriorityMedium:  emojiMedium,
+	items.PriorityLow:     emojiLow,
+	items.PriorityLowest:  emojiLowest,
+}
+
+const variationSelector = "️?"
+
+var (
+	priorityPattern     = regexp.MustCompile(`\s*(` + emojiHighest + `|` + emojiHigh + `|` + emojiMedium + `|` + emojiLow + `|` + emojiLowest + `)` + variationSelector + `$`)
+	recurrencePattern   = regexp.MustCompile(`\s*` + emojiRecurrence + variationSelector + ` ?([a-zA-Z0-9, !]+)$`)
+	createdPattern      = datePattern(emojiCreated)
+	startPattern        = datePattern(emojiStart)
+	scheduledPattern    = datePattern(emojiScheduled + `|⌛`)
+	duePattern          = datePattern(emojiDue + `|📆|🗓`)
+	cancelledPattern    = datePattern(emojiCancelled)
+	donePattern         = datePattern(emojiDone)
+	idPattern           = regexp.MustCompile(`\s*` + emojiID + variationSelector + ` *([a-zA-Z0-9_-]+)$`)
+	dependsOnPattern    = regexp.MustCompile(`\s*` + emojiDependsOn + variationSelector + ` *([a-zA-Z0-9_-]+( *, *[a-zA-Z0-9_-]+)*)$`)
+	onCompletionPattern = regexp.MustCompile(`\s*` + emojiOnCompletion + variationSelector + ` *([a-zA-Z]+)$`)
+	blockLinkPattern    = regexp.MustCompile(`\s*(\^[a-zA-Z0-9-]+)$`)
+	trailingTagPattern  = regexp.MustCompile(`\s+(#[^\s!@#$%^&*(),.?":{}|<>]+)$`)
+)
+
+func datePattern(emoji string) *regexp.Regexp {
+	return regexp.MustCompile(`\s*(?:` + emoji + `)` + variationSelector + ` *(\d{4}-\d{2}-\d{2})$`)
+}
+
+// maxTaskFields bounds the number of trailing fields parsed, like the plugin does.
+const maxTaskFields = 20
+
+// ParseTaskLine parses the text of a checklist item (everything after "- [ ] ").
+// Like the plugin, fields are read from the end of the line; tags found between
+// them are moved back to the end of the description.
+func ParseTaskLine(text string) TaskLine {
+	var t TaskLine
+	var trailingTags []string
+	rest := strings.TrimRight(text, " \t")
+
+	for range maxTaskFields {
+		matched := false
+
+		if m := blockLinkPattern.FindStringSubmatch(rest); m != nil && t.BlockLink == "" {
+			t.BlockLink = m[1]
+			rest, matched = strings.TrimSuffix(rest, m[0]), true
+		}
+		if m := priorityPattern.FindStringSubmatch(rest); m != nil {
+			for p, emoji := range priorityEmoji {
+				if emoji == m[1] {
+					t.Priority = p
+				}
+			}
+			rest, matched = strings.TrimSuffix(rest, m[0]), true
+		}
+		for _, f := range []struct {
+			pattern *regexp.Regexp
+			dest    *time.Time
+		}{
+			{createdPattern, &t.Created},
+			{startPattern, &t.Start},
+			{scheduledPattern, &t.Scheduled},
+			{duePattern, &t.Due},
+			{cancelledPattern, &t.Cancelled},
+			{donePattern, &t.Done},
+		} {
+			if m := f.pattern.FindStringSubmatch(rest); m != nil {
+				if d, err := items.ParseDate(m[1]); err == nil {
+					*f.dest = d
+					rest, matched = strings.TrimSuffix(rest, m[0]), true
+				}
+			}
+		}
+		for _, f := range []struct {
+			pattern *regexp.Regexp
+			dest    *string
+		}{
+			{recurrencePattern, &t.Recurrence},
+			{idPattern, &t.ID},
+			{dependsOnPattern, &t.DependsOn},
+			{onCompletionPattern, &t.OnCompletion},
+		} {
+			if m := f.pattern.FindStringSubmatch(rest); m != nil {
+				*f.dest = strings.TrimSpace(m[1])
+				rest, matched = strings.TrimSuffix(rest, m[0]), true
+			}
+		}
+		if m := trailingTagPattern.FindStringSubmatch(rest); m != nil {
+			trailingTags = append([]string{m[1]}, trailingTags...)
+			rest, matched = strings.TrimSuffix(rest, m[0]), true
+		}
+
+		if !matched {
+			break
+		}
+	}
+
+	t.Description = strings.TrimSpace(strings.Join(append([]string{rest}, trailingTags...), " "))
+	return t
+}
+
+// String serializes the task line in the plugin's field order.
+func (t TaskLine) String() string {
+	parts := []string{t.Description}
+	if t.ID != "" {
+		parts = append(parts, emojiID+" "+t.ID)
+	}
+	if t.DependsOn != "" {
+		parts = append(parts, emojiDependsOn+" "+t.DependsOn)
+	}
+	if emoji, ok := priorityEmoji[t.Priority]; ok {
+		parts = append(parts, emoji)
+	}
+	if t.Recurrence != "" {
+		parts = append(parts, emojiRecurrence+" "+t.Recurrence)
+	}
+	if t.OnCompletion != "" {
+		parts = append(parts, emojiOnCompletion+" "+t.OnCompletion)
+	}
+	for _, f := range []struct {
+		emoji string
+		date  time.Time
+	}{
+		{emojiCreated, t.Created},
+		{emojiStart, t.Start},
+		{emojiScheduled, t.Scheduled},
+		{emojiDue, t.Due},
+		{emojiCancelled, t.Cancelled},
+		{emojiDone, t.Done},
+	} {
+		if !f.date.IsZero() {
+			parts = append(parts, f.emoji+" "+items.FormatDate(f.date))
+		}
+	}
+	if t.BlockLink != "" {
+		parts = append(parts, t.BlockLink)
+	}
+	return strings.Join(parts, " ")
+}
+
+// NextOccurrence returns the next instance of a recurring task completed on the given day.
+// Supported rules are "every [N] day(s)|week(s)|month(s)|year(s)", optionally followed by
+// "when done" to count from the completion date. It returns false for non-recurring tasks
+// and rules it doesn't understand.
+func (t TaskLine) NextOccurrence(completedOn time.Time) (TaskLine, bool) {
+	fields := strings.Fields(strings.ToLower(t.Recurrence))
+	if len(fields) < 2 || fields[0] != "every" {
+		return TaskLine{}, false
+	}
+	fields = fields[1:]
+
+	whenDone := false
+	if n := len(fields); n >= 2 && fields[n-2] == "when" && fields[n-1] == "done" {
+		whenDone = true
+		fields = fields[:n-2]
+	}
+
+	interval := 1
+	if len(fields) == 2 {
+		n, err := strconv.Atoi(fields[0])
+		if err != nil || n < 1 {
+			return TaskLine{}, false
+		}
+		interval = n
+		fields = fields[1:]
+	}
+	if len(fields) != 1 {
+		return TaskLine{}, false
+	}
+
+	var years, months, days int
+	switch strings.TrimSuffix(fields[0], "s") {
+	case "day":
+		days = interval
+	case "week":
+		days = 7 * interval
+	case "month":
+		months = interval
+	case "year":
+		years = interval
+	default:
+		return TaskLine{}, false
+	}
+
+	// The reference date is the first of due, scheduled and start, like in the plugin
+	reference := t.Due
+	if reference.IsZero() {
+		reference = t.Scheduled
+	}
+	if reference.IsZero() {
+		reference = t.Start
+	}
+	base := reference
+	if whenDone || base.IsZero() {
+		base = time.Date(completedOn.Year(), completedOn.Month(), completedOn.Day(), 0, 0, 0, 0, time.Local)
+	}
+	next := addDate(base, years, months, days)
+
+	shift := func(d time.Time) time.Time {
+		if d.IsZero() {
+			return d
+		}
+		// Keep the distance between the dates of the task
+		return next.AddDate(0, 0, daysBetween(reference, d))
+	}
+
+	n := t
+	n.Done = time.Time{}
+	n.Cancelled = time.Time{}
+	n.Created = time.Time{}
+	n.BlockLink = ""
+	n.ID = ""
+	n.Due = shift(t.Due)
+	n.Scheduled = shift(t.Scheduled)
+	n.Start = shift(t.Start)
+	return n, true
+}
+
+// addDate adds years, months and days to t like time.AddDate, but months and years that
+// would overflow into the next month end on the last day of the target month instead,
+// e.g. a month after January 31st is February 28th (or 29th), not March 3rd.
+func addDate(t time.Time, years, months, days int) time.Time {
+	y, m, d := t.Date()
+	first := time.Date(y+years, m+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	if last := first.AddDate(0, 1, -1).Day(); d > last {
+		d = last
+	}
+	return first.AddDate(0, 0, d-1+days)
+}
+
+// daysBetween returns the number of calendar days from a to b. Elapsed hours would be
+// one short across a change to daylight saving time.
+func daysBetween(a, b time.Time) int {
+	day := func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	return int(day(b).Sub(day(a)).Hours() / 24)
+}
@@ -0,0 +1,203 @@
+package markdown_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/markelca/prioritty/pkg/items"
+	"github.com/markelca/prioritty/pkg/markdown"
+)
+
+func date(t *testing.T, s string) time.Time {
+	t.Helper()
+	d, err := items.ParseDate(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestParseTaskLine(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want func(t *testing.T) markdown.TaskLine
+		line string // as written back, if it differs from text
+	}{
+		{
+			name: "description only",
+			text: "Send the report #work",
+			want: func(t *testing.T) markdown.TaskLine {
+				return markdown.TaskLine{Description: "Send the report #work"}
+			},
+		},
+		{
+			name: "all fields",
+			text: "Pay rent #home 🆔 rent ⛔ abc,def ⏫ 🔁 every month 🏁 delete ➕ 2025-06-01 🛫 2025-06-02 ⏳ 2025-06-03 📅 2025-07-01 ✅ 2025-07-01 ^block-1",
+			want: func(t *testing.T) markdown.TaskLine {
+				return markdown.TaskLine{
+					Description:  "Pay rent #home",
+					ID:           "rent",
+					DependsOn:    "abc,def",
+					Priority:     items.PriorityHigh,
+					Recurrence:   "every month",
+					OnCompletion: "delete",
+					Created:      date(t, "2025-06-01"),
+					Start:        date(t, "2025-06-02"),
+					Scheduled:    date(t, "2025-06-03"),
+					Due:          date(t, "2025-07-01"),
+					Done:         date(t, "2025-07-01"),
+					BlockLink:    "^block-1",
+				}
+			},
+		},
+		{
+			name: "fields in any order, tags between them",
+			text: "Review #work 📅 2025-07-01 #urgent 🔽",
+			want: func(t *testing.T) markdown.TaskLine {
+				return markdown.TaskLine{
+					Description: "Review #work #urgent",
+					Priority:    items.PriorityLow,
+					Due:         date(t, "2025-07-01"),
+				}
+			},
+			line: "Review #work #urgent 🔽 📅 2025-07-01",
+		},
+		{
+			name: "alternative emoji and variation selectors",
+			text: "Call 🗓 2025-07-01 ⌛ 2025-06-30 🔺️",
+			want: func(t *testing.T) markdown.TaskLine {
+				return markdown.TaskLine{
+					Description: "Call",
+					Priority:    items.PriorityHighest,
+					Scheduled:   date(t, "2025-06-30"),
+					Due:         date(t, "2025-07-01"),
+				}
+			},
+			line: "Call 🔺 ⏳ 2025-06-30 📅 2025-07-01",
+		},
+		{
+			name: "cancelled",
+			text: "Order pizza ❌ 2025-06-05",
+			want: func(t *testing.T) markdown.TaskLine {
+				return markdown.TaskLine{Description: "Order pizza", Cancelled: date(t, "2025-06-05")}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := markdown.ParseTaskLine(tt.text)
+			if want := tt.want(t); got != want {
+				t.Fatalf("ParseTaskLine(%q) = %+v, want %+v", tt.text, got, want)
+			}
+			line := tt.line
+			if line == "" {
+				line = tt.text
+			}
+			if got.String() != line {
+				t.Fatalf("String() = %q, want %q", got.String(), line)
+			}
+			if again := markdown.ParseTaskLine(got.String()); again != got {
+				t.Fatalf("round trip = %+v, want %+v", again, got)
+			}
+		})
+	}
+}
+
+func TestNextOccurrence(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		doneOn   string
+		next     string // "" if there's no next occurrence
+		location string // time.Local during the test, if set
+	}{
+		{
+			name:   "every week from the due date",
+			text:   "Water plants 🔁 every week ⏳ 2025-06-01 📅 2025-06-03",
+			doneOn: "2025-06-05",
+			next:   "Water plants 🔁 every week ⏳ 2025-06-08 📅 2025-06-10",
+		},
+		{
+			name:   "every 2 months when done",
+			text:   "Haircut 🔁 every 2 months when done 📅 2025-06-03",
+			doneOn: "2025-06-10",
+			next:   "Haircut 🔁 every 2 months when done 📅 2025-08-10",
+		},
+		{
+			name:   "every year from the scheduled date",
+			text:   "Renew passport 🔁 every year ⏳ 2025-01-15",
+			doneOn: "2025-01-20",
+			next:   "Renew passport 🔁 every year ⏳ 2026-01-15",
+		},
+		{
+			name:   "every month from the 31st",
+			text:   "Send invoice 🔁 every month 📅 2025-01-31",
+			doneOn: "2025-01-31",
+			next:   "Send invoice 🔁 every month 📅 2025-02-28",
+		},
+		{
+			name:   "every month from the 31st in a leap year",
+			text:   "Send invoice 🔁 every month 📅 2024-01-31",
+			doneOn: "2024-01-31",
+			next:   "Send invoice 🔁 every month 📅 2024-02-29",
+		},
+		{
+			name:   "every year from February 29th",
+			text:   "Celebrate 🔁 every year 📅 2024-02-29",
+			doneOn: "2024-02-29",
+			next:   "Celebrate 🔁 every year 📅 2025-02-28",
+		},
+		{
+			name:   "every 4 years from February 29th",
+			text:   "Celebrate 🔁 every 4 years 📅 2024-02-29",
+			doneOn: "2024-02-29",
+			next:   "Celebrate 🔁 every 4 years 📅 2028-02-29",
+		},
+		{
+			name:   "created, done and block link dropped",
+			text:   "Standup 🔁 every day ➕ 2025-06-01 📅 2025-06-02 ✅ 2025-06-02 ^abc",
+			doneOn: "2025-06-02",
+			next:   "Standup 🔁 every day 📅 2025-06-03",
+		},
+		{
+			name:     "offsets in calendar days across daylight saving time",
+			text:     "Pay bills 🔁 every day ⏳ 2025-03-08 📅 2025-03-10",
+			doneOn:   "2025-03-10",
+			next:     "Pay bills 🔁 every day ⏳ 2025-03-09 📅 2025-03-11",
+			location: "America/New_York",
+		},
+		{
+			name:   "not recurring",
+			text:   "Buy milk 📅 2025-06-03",
+			doneOn: "2025-06-03",
+		},
+		{
+			name:   "unknown rule",
+			text:   "Buy milk 🔁 every other tuesday",
+			doneOn: "2025-06-03",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.location != "" {
+				loc, err := time.LoadLocation(tt.location)
+				if err != nil {
+					t.Skipf("time zone %s not available: %v", tt.location, err)
+				}
+				local := time.Local
+				time.Local = loc
+				t.Cleanup(func() { time.Local = local })
+			}
+			next, ok := markdown.ParseTaskLine(tt.text).NextOccurrence(date(t, tt.doneOn))
+			if ok != (tt.next != "") {
+				t.Fatalf("NextOccurrence() ok = %v, want %v", ok, tt.next != "")
+			}
+			if ok && next.String() != tt.next {
+				t.Fatalf("NextOccurrence() = %q, want %q", next.String(), tt.next)
+			}
+		})
+	}
+}
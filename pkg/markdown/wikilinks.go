@@ -0,0 +1,44 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+)
+
+// wikilinkPattern matches [[target]], [[target|alias]] and [[target#heading]] links.
+// Embeds (![[file]]) are matched too, they reference items the same way.
+var wikilinkPattern = regexp.MustCompile(`\[\[([^\[\]|#^]+)(?:[#^][^\[\]|]*)?(?:\|([^\[\]]*))?\]\]`)
+
+// Wikilink is an Obsidian style [[link]] found in a markdown body.
+type Wikilink struct {
+	Target string // title or filename the link points to, without heading or alias
+	Alias  string // display text after the pipe, if any
+}
+
+// Wikilinks returns the wikilinks of a markdown body in order of appearance, without duplicates.
+// Links inside fenced code blocks are ignored.
+func Wikilinks(body string) []Wikilink {
+	var links []Wikilink
+	seen := make(map[string]bool)
+
+	inFence := false
+	for _, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		for _, m := range wikilinkPattern.FindAllStringSubmatch(line, -1) {
+			target := strings.TrimSpace(m[1])
+			if target == "" || seen[strings.ToLower(target)] {
+				continue
+			}
+			seen[strings.ToLower(target)] = true
+			links = append(links, Wikilink{Target: target, Alias: strings.TrimSpace(m[2])})
+		}
+	}
+	return links
+}